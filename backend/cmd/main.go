@@ -3,111 +3,137 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	"badminton-backend/internal/controllers"
+	"badminton-backend/internal/config"
+	"badminton-backend/internal/database"
+	"badminton-backend/internal/invites"
+	"badminton-backend/internal/mailer"
 	"badminton-backend/internal/middleware"
-	"badminton-backend/internal/models"
+	"badminton-backend/internal/migrations"
+	"badminton-backend/internal/oidc"
+	"badminton-backend/internal/oidc/mockissuer"
+	"badminton-backend/internal/routes"
+	"badminton-backend/internal/usertokens"
 )
 
 func main() {
-	// Initialize database
-	db, err := gorm.Open(sqlite.Open("badminton.db"), &gorm.Config{})
+	// Load and validate the configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.IsProduction() && os.Getenv("GIN_MODE") == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Initialize database. Driver errors are translated so unique
+	// constraint violations can be told apart.
+	db, err := database.Open(database.ConfigFrom(cfg.Database), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	migrator := migrations.New(db)
+
+	// "migrate [up | down [steps] | status]" manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrator.Run(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		return
+	}
 
-	// Auto-migrate models
-	db.AutoMigrate(
-		&models.User{},
-		&models.Player{}, // Keep for backward compatibility
-		&models.Team{},
-		&models.TeamPlayer{},
-		&models.Tournament{},
-		&models.TournamentPlayer{},
-		&models.TournamentTeam{},
-		&models.Match{},
-	)
+	// Configure token signing keys
+	tokenConfig, err := middleware.LoadTokenConfig(cfg.JWT)
+	if err != nil {
+		log.Fatal("Invalid JWT configuration:", err)
+	}
+	if err := middleware.ConfigureTokens(tokenConfig); err != nil {
+		log.Fatal("Invalid JWT configuration:", err)
+	}
+
+	// Configure outgoing email
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatal("Invalid mail configuration:", err)
+	}
+
+	// Configure single sign-on
+	var oidcProvider *oidc.Provider
+	oidcConfig, oidcEnabled := oidc.ConfigFrom(cfg.OIDC)
+	if oidcEnabled {
+		oidcProvider = oidc.New(oidcConfig)
+	}
+
+	// Apply pending migrations unless deployments run "migrate up" themselves
+	if !cfg.Server.MigrateOnStart {
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal("Failed to check migrations:", err)
+		}
+		for _, status := range statuses {
+			if status.AppliedAt == nil {
+				log.Fatalf("Migration %d %s is pending, run \"migrate up\" first", status.Version, status.Name)
+			}
+		}
+	} else if _, err := migrator.Up(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	userTokenManager := usertokens.NewManager(db)
+
+	// "invite-legacy-players" emails claim links for converted players and exits
+	if len(os.Args) > 1 && os.Args[1] == "invite-legacy-players" {
+		sent, err := invites.NewSender(db, userTokenManager, mail, cfg.Server.AppURL).SendPending()
+		if err != nil {
+			log.Fatal("Failed to send invites:", err)
+		}
+		log.Printf("Sent %d claim invites", sent)
+		return
+	}
 
 	// Initialize Gin router
 	r := gin.Default()
 
 	// Configure CORS
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:3000"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	r.Use(cors.New(config))
-
-	// Initialize controllers
-	authController := controllers.NewAuthController(db)
-	playerController := controllers.NewPlayerController(db)
-	matchController := controllers.NewMatchController(db)
-	tournamentController := controllers.NewTournamentController(db)
-	tournamentRegController := controllers.NewTournamentRegistrationController(db)
-
-	// API v1 routes
-	v1 := r.Group("/api/v1")
-	{
-		// Auth routes (public)
-		v1.POST("/register", authController.Register)
-		v1.POST("/login", authController.Login)
-
-		// Protected routes
-		authorized := v1.Group("/")
-		authorized.Use(middleware.AuthMiddleware(db))
-		{
-			// Profile routes
-			authorized.GET("/profile", authController.GetProfile)
-			authorized.PUT("/profile", authController.UpdateProfile)
-			authorized.POST("/change-password", authController.ChangePassword)
-
-			// Admin-only user management routes
-			authorized.GET("/users", middleware.RequireAdmin(), authController.GetAllUsers)
-			authorized.PUT("/users/:user_id/role", middleware.RequireAdmin(), authController.UpdateUserRole)
-
-			// Player routes (keep for backward compatibility)
-			authorized.GET("/players", playerController.GetPlayers)
-			authorized.POST("/players", playerController.CreatePlayer)
-			authorized.GET("/players/:id", playerController.GetPlayer)
-			authorized.PUT("/players/:id", playerController.UpdatePlayer)
-			authorized.DELETE("/players/:id", playerController.DeletePlayer)
-
-			// Match routes
-			authorized.GET("/matches", matchController.GetMatches)
-			authorized.POST("/matches", matchController.CreateMatch)
-			authorized.GET("/matches/:id", matchController.GetMatch)
-			authorized.PUT("/matches/:id", matchController.UpdateMatch)
-			authorized.DELETE("/matches/:id", matchController.DeleteMatch)
-
-			// Tournament routes
-			authorized.GET("/tournaments", tournamentController.GetTournaments)
-			authorized.POST("/tournaments", middleware.RequireAdmin(), tournamentController.CreateTournament)
-			authorized.GET("/tournaments/:id", tournamentController.GetTournament)
-			authorized.PUT("/tournaments/:id", middleware.RequireAdmin(), tournamentController.UpdateTournament)
-			authorized.DELETE("/tournaments/:id", middleware.RequireAdmin(), tournamentController.DeleteTournament)
-
-			// Tournament registration routes
-			authorized.POST("/tournament-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.RegisterForTournament)
-			authorized.DELETE("/tournament-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.UnregisterFromTournament)
-			authorized.GET("/my-registrations", middleware.RequirePlayer(), tournamentRegController.GetMyRegistrations)
-
-			// Team routes
-			authorized.POST("/teams", middleware.RequirePlayer(), tournamentRegController.CreateTeam)
-			authorized.POST("/team-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.RegisterTeamForTournament)
-		}
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.Server.CORSOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	// Linking a single sign-on account sets the state cookie from a
+	// cross-origin request
+	corsConfig.AllowCredentials = true
+	r.Use(cors.New(corsConfig))
+
+	// Register the API
+	if _, err := routes.Register(r, db, routes.Dependencies{
+		Mailer: mail,
+		Tokens: userTokenManager,
+		OIDC:   oidcProvider,
+		AppURL: cfg.Server.AppURL,
+	}); err != nil {
+		log.Fatal("Failed to register routes:", err)
 	}
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	// Serve a mock OpenID provider for local single sign-on testing. It is
+	// not part of the API, so it is added after the API is described.
+	if cfg.OIDC.MockIssuer {
+		mock, err := mockissuer.New(oidcConfig.Issuer)
+		if err != nil {
+			log.Fatal("Failed to start mock OIDC issuer:", err)
+		}
+		r.Any("/mock-oidc/*path", gin.WrapH(http.StripPrefix("/mock-oidc", mock)))
+	}
 
 	// Start server
-	log.Println("Server starting on :8080")
-	r.Run(":8080")
+	addr := ":" + strconv.Itoa(cfg.Server.Port)
+	log.Printf("Server starting on %s (%s profile)", addr, cfg.Profile)
+	if err := r.Run(addr); err != nil {
+		log.Fatal("Server stopped:", err)
+	}
 }
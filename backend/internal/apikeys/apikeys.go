@@ -0,0 +1,137 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// KeyPrefix starts every API key so it can be told apart from a JWT
+const KeyPrefix = "bk_"
+
+// lastUsedInterval limits how often last-used tracking writes to the database
+const lastUsedInterval = time.Minute
+
+// ErrInvalidKey is returned for unknown, expired or revoked keys
+var ErrInvalidKey = errors.New("invalid or revoked API key")
+
+// scoreRoutes are the mutating routes a score-entry key may call
+var scoreRoutes = map[string]bool{
+	"POST /api/v1/matches":                   true,
+	"PUT /api/v1/matches/:id":                true,
+	"POST /api/v1/matches/:id/result":        true,
+	"POST /api/v1/match-results/:id/confirm": true,
+	"POST /api/v1/match-results/:id/dispute": true,
+}
+
+// IsAPIKey checks if a bearer credential looks like an API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, KeyPrefix)
+}
+
+// Allows checks if a key with the scope may call the route, given as the
+// HTTP method and the route pattern
+func Allows(scope models.APIKeyScope, method, route string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	switch scope {
+	case models.APIKeyAdmin:
+		return true
+	case models.APIKeyScore:
+		return scoreRoutes[method+" "+route]
+	}
+	return false
+}
+
+// Manager creates and checks API keys
+type Manager struct {
+	db *gorm.DB
+}
+
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// Create issues a key for the user and returns the plain key, which is
+// only available now
+func (m *Manager) Create(user *models.User, createdBy *models.User, name string, scope models.APIKeyScope, expiresAt *time.Time) (string, *models.APIKey, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	key := KeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	apiKey := models.APIKey{
+		UserID:      user.ID,
+		Name:        name,
+		Prefix:      key[:len(KeyPrefix)+6],
+		KeyHash:     hashKey(key),
+		Scope:       scope,
+		CreatedByID: createdBy.ID,
+		ExpiresAt:   expiresAt,
+	}
+	if err := m.db.Create(&apiKey).Error; err != nil {
+		return "", nil, err
+	}
+	return key, &apiKey, nil
+}
+
+// Authenticate returns the active key with its user loaded and records
+// that it was used
+func (m *Manager) Authenticate(key, ip string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := m.db.Preload("User").Where("key_hash = ?", hashKey(key)).First(&apiKey).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, ErrInvalidKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval || apiKey.LastUsedIP != ip {
+		m.db.Model(&apiKey).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+		apiKey.LastUsedAt = &now
+		apiKey.LastUsedIP = ip
+	}
+	return &apiKey, nil
+}
+
+// Revoke stops a key from being accepted
+func (m *Manager) Revoke(apiKey *models.APIKey) error {
+	now := time.Now()
+	if err := m.db.Model(apiKey).Where("revoked_at IS NULL").Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	if apiKey.RevokedAt == nil {
+		apiKey.RevokedAt = &now
+	}
+	return nil
+}
+
+// RevokeAll revokes every active key of a user, for when their password
+// changes or they log out everywhere
+func (m *Manager) RevokeAll(userID uint) error {
+	return m.db.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
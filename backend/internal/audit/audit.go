@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// ignoredFields are bookkeeping fields left out of change sets
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// Change is the before and after value of one field
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Logger writes audit records
+type Logger struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Logger {
+	return &Logger{db: db}
+}
+
+// Record stores an audit entry. Failures are logged rather than returned so
+// auditing never breaks the action being audited.
+func (l *Logger) Record(entry models.AuditLog) {
+	if err := l.db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to write audit record %q: %v", entry.Action, err)
+	}
+}
+
+// RecordRequest stores an audit entry for an API request, filling in the
+// signed-in actor and the request metadata
+func (l *Logger) RecordRequest(c *gin.Context, entry models.AuditLog) {
+	if entry.ActorID == nil {
+		if userID, ok := c.Get("user_id"); ok {
+			id := userID.(uint)
+			entry.ActorID = &id
+		}
+	}
+	if entry.IPAddress == "" {
+		entry.IPAddress = c.ClientIP()
+	}
+	entry.Method = c.Request.Method
+	entry.Path = c.Request.URL.Path
+	entry.UserAgent = c.Request.UserAgent()
+	if sessionID := c.GetUint("session_id"); sessionID != 0 {
+		entry.SessionID = &sessionID
+	}
+	if apiKeyID := c.GetUint("api_key_id"); apiKeyID != 0 {
+		entry.APIKeyID = &apiKeyID
+	}
+
+	l.Record(entry)
+}
+
+// RecordChange stores an audit entry for an API request together with the
+// fields that differ between the before and after state of the resource.
+// Pass nil as before for a creation and as after for a deletion.
+func (l *Logger) RecordChange(c *gin.Context, entry models.AuditLog, before, after interface{}) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		log.Printf("Failed to diff audit record %q: %v", entry.Action, err)
+	}
+	entry.Changes = changes
+	l.RecordRequest(c, entry)
+}
+
+// Diff returns a JSON object of the top-level fields whose JSON values
+// differ between before and after. Nested objects such as loaded
+// relations are left out; they are audited as resources of their own.
+func Diff(before, after interface{}) (string, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return "", err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]Change)
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = Change{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, seen := beforeFields[name]; !seen && value != nil {
+			changes[name] = Change{Before: nil, After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	out, err := json.Marshal(changes)
+	return string(out), err
+}
+
+// toFields flattens a value to its top-level JSON fields
+func toFields(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	for name, fieldValue := range decoded {
+		if ignoredFields[name] {
+			continue
+		}
+		switch fieldValue.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		fields[name] = fieldValue
+	}
+	return fields, nil
+}
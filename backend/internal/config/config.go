@@ -0,0 +1,467 @@
+// Package config loads the server settings from built-in defaults, an
+// optional YAML file and the environment, and validates them at start-up.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in profiles. A configuration file may define others.
+const (
+	ProfileDevelopment = "development"
+	ProfileTest        = "test"
+	ProfileProduction  = "production"
+)
+
+// Config holds every setting of the server
+type Config struct {
+	Profile  string   `yaml:"-"`
+	Release  bool     `yaml:"-"` // GIN_MODE=release
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	JWT      JWT      `yaml:"jwt"`
+	Mail     Mail     `yaml:"mail"`
+	OIDC     OIDC     `yaml:"oidc"`
+}
+
+// Server configures the HTTP server
+type Server struct {
+	Port           int      `yaml:"port"`
+	CORSOrigins    []string `yaml:"cors_origins"`
+	AppURL         string   `yaml:"app_url"` // frontend address used in emailed links
+	MigrateOnStart bool     `yaml:"migrate_on_start"`
+}
+
+// Database selects the database and how connections are pooled
+type Database struct {
+	Driver string `yaml:"driver"`
+	Path   string `yaml:"path"` // SQLite file
+
+	// PostgreSQL connection, either as a URL or as separate fields
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"` // 0 means unlimited
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"` // 0 means connections are reused forever
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// MinHMACKeyBytes is the shortest HS256 secret accepted for signing tokens
+const MinHMACKeyBytes = 32
+
+// KeySpec is a JWT_KEYS entry: the key ID, its algorithm and the file
+// holding its material
+type KeySpec struct {
+	ID        string
+	Algorithm string
+	Path      string
+}
+
+// ParseKeySpec parses a kid=alg:path entry
+func ParseKeySpec(entry string) (KeySpec, error) {
+	kid, rest, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok {
+		return KeySpec{}, fmt.Errorf("invalid JWT key entry %q, expected kid=alg:path", entry)
+	}
+	alg, path, ok := strings.Cut(rest, ":")
+	if !ok {
+		return KeySpec{}, fmt.Errorf("invalid JWT key entry %q, expected kid=alg:path", entry)
+	}
+	return KeySpec{ID: kid, Algorithm: alg, Path: path}, nil
+}
+
+// HMACSecret returns the HS256 secret held in data without surrounding
+// whitespace, refusing one shorter than MinHMACKeyBytes
+func HMACSecret(kid string, data []byte) ([]byte, error) {
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < MinHMACKeyBytes {
+		return nil, fmt.Errorf("JWT key %q: HS256 secrets must be at least %d bytes", kid, MinHMACKeyBytes)
+	}
+	return secret, nil
+}
+
+// JWT configures how access tokens are signed
+type JWT struct {
+	Secret     string        `yaml:"secret"`
+	Keys       []string      `yaml:"keys"` // kid=alg:path entries
+	ActiveKID  string        `yaml:"active_kid"`
+	TTL        time.Duration `yaml:"ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+	Issuer     string        `yaml:"issuer"`
+}
+
+// Mail configures outgoing email
+type Mail struct {
+	Driver       string `yaml:"driver"`
+	From         string `yaml:"from"`
+	LogFile      string `yaml:"log_file"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     string `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
+
+// OIDC configures single sign-on, disabled while Issuer is empty
+type OIDC struct {
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+	MockIssuer   bool     `yaml:"mock_issuer"` // serve a fake provider for local testing
+}
+
+// IsProduction reports whether the production profile is active
+func (c *Config) IsProduction() bool {
+	return c.Profile == ProfileProduction
+}
+
+// AllowsDevShortcuts reports whether the server may start with the local
+// conveniences that are unsafe once exposed: an ephemeral JWT secret and
+// the mock OIDC provider. Only the development and test profiles may, and
+// never under GIN_MODE=release.
+func (c *Config) AllowsDevShortcuts() bool {
+	return (c.Profile == ProfileDevelopment || c.Profile == ProfileTest) && !c.Release
+}
+
+// Load reads the configuration for the profile named by APP_ENV, which
+// defaults to production when GIN_MODE=release and to development
+// otherwise. Settings are layered, later ones winning:
+//
+//  1. built-in defaults
+//  2. the top level of the file named by CONFIG_FILE, or config.yaml if
+//     it exists
+//  3. the file's entry under profiles for the active profile
+//  4. environment variables
+func Load() (*Config, error) {
+	profile := os.Getenv("APP_ENV")
+	if profile == "" {
+		profile = ProfileDevelopment
+		if os.Getenv("GIN_MODE") == "release" {
+			profile = ProfileProduction
+		}
+	}
+
+	cfg := defaults()
+	cfg.Profile = profile
+	cfg.Release = os.Getenv("GIN_MODE") == "release"
+
+	if err := cfg.loadFile(os.Getenv("CONFIG_FILE")); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func defaults() *Config {
+	return &Config{
+		Server: Server{
+			Port:           8080,
+			CORSOrigins:    []string{"http://localhost:3000"},
+			AppURL:         "http://localhost:3000",
+			MigrateOnStart: true,
+		},
+		Database: Database{
+			Driver:          "sqlite",
+			Path:            "badminton.db",
+			Port:            "5432",
+			User:            "postgres",
+			Name:            "badminton",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+			ConnMaxIdleTime: 10 * time.Minute,
+		},
+		JWT: JWT{
+			TTL:        15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Mail: Mail{
+			Driver:   "log",
+			From:     "no-reply@localhost",
+			SMTPPort: "587",
+		},
+		OIDC: OIDC{
+			RedirectURL: "http://localhost:8080/api/v1/auth/oidc/callback",
+			Scopes:      []string{"openid", "email", "profile"},
+		},
+	}
+}
+
+// configFile is the layout of the configuration file
+type configFile struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// loadFile applies the configuration file. An explicitly named file must
+// exist; the default config.yaml is optional.
+func (c *Config) loadFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = "config.yaml"
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return c.checkProfile(nil)
+	}
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	file := configFile{Config: *c}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	*c = file.Config
+
+	if err := c.checkProfile(file.Profiles); err != nil {
+		return err
+	}
+	if node, ok := file.Profiles[c.Profile]; ok {
+		if err := node.Decode(c); err != nil {
+			return fmt.Errorf("parsing profile %q in %s: %w", c.Profile, path, err)
+		}
+	}
+	return nil
+}
+
+// checkProfile rejects a profile that is neither built in nor defined in
+// the file, so a typo cannot silently fall back to development settings
+func (c *Config) checkProfile(defined map[string]yaml.Node) error {
+	switch c.Profile {
+	case ProfileDevelopment, ProfileTest, ProfileProduction:
+		return nil
+	}
+	if _, ok := defined[c.Profile]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown APP_ENV profile %q", c.Profile)
+}
+
+// loadEnv applies the environment variables that are set
+func (c *Config) loadEnv(getenv func(string) string) error {
+	texts := map[string]*string{
+		"APP_URL":            &c.Server.AppURL,
+		"DB_DRIVER":          &c.Database.Driver,
+		"DB_PATH":            &c.Database.Path,
+		"DATABASE_URL":       &c.Database.URL,
+		"DB_HOST":            &c.Database.Host,
+		"DB_PORT":            &c.Database.Port,
+		"DB_USER":            &c.Database.User,
+		"DB_PASSWORD":        &c.Database.Password,
+		"DB_NAME":            &c.Database.Name,
+		"DB_SSLMODE":         &c.Database.SSLMode,
+		"JWT_SECRET":         &c.JWT.Secret,
+		"JWT_ACTIVE_KID":     &c.JWT.ActiveKID,
+		"JWT_ISSUER":         &c.JWT.Issuer,
+		"MAIL_DRIVER":        &c.Mail.Driver,
+		"MAIL_FROM":          &c.Mail.From,
+		"MAIL_LOG_FILE":      &c.Mail.LogFile,
+		"SMTP_HOST":          &c.Mail.SMTPHost,
+		"SMTP_PORT":          &c.Mail.SMTPPort,
+		"SMTP_USERNAME":      &c.Mail.SMTPUsername,
+		"SMTP_PASSWORD":      &c.Mail.SMTPPassword,
+		"OIDC_ISSUER":        &c.OIDC.Issuer,
+		"OIDC_CLIENT_ID":     &c.OIDC.ClientID,
+		"OIDC_CLIENT_SECRET": &c.OIDC.ClientSecret,
+		"OIDC_REDIRECT_URL":  &c.OIDC.RedirectURL,
+	}
+	for name, value := range texts {
+		if raw := getenv(name); raw != "" {
+			*value = raw
+		}
+	}
+
+	lists := []struct {
+		name  string
+		value *[]string
+		split func(string) []string
+	}{
+		{"CORS_ORIGINS", &c.Server.CORSOrigins, splitComma},
+		{"JWT_KEYS", &c.JWT.Keys, splitComma},
+		{"OIDC_SCOPES", &c.OIDC.Scopes, strings.Fields},
+	}
+	for _, setting := range lists {
+		if raw := getenv(setting.name); raw != "" {
+			*setting.value = setting.split(raw)
+		}
+	}
+
+	ints := map[string]*int{
+		"PORT":              &c.Server.Port,
+		"DB_MAX_OPEN_CONNS": &c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS": &c.Database.MaxIdleConns,
+	}
+	for name, value := range ints {
+		if raw := getenv(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s: must be an integer", name)
+			}
+			*value = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"DB_CONN_MAX_LIFETIME":  &c.Database.ConnMaxLifetime,
+		"DB_CONN_MAX_IDLE_TIME": &c.Database.ConnMaxIdleTime,
+		"JWT_TTL":               &c.JWT.TTL,
+		"REFRESH_TTL":           &c.JWT.RefreshTTL,
+	}
+	for name, value := range durations {
+		if raw := getenv(name); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*value = d
+		}
+	}
+
+	bools := map[string]*bool{
+		"MIGRATE_ON_START": &c.Server.MigrateOnStart,
+		"OIDC_MOCK_ISSUER": &c.OIDC.MockIssuer,
+	}
+	for name, value := range bools {
+		if raw := getenv(name); raw != "" {
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s: must be true or false", name)
+			}
+			*value = b
+		}
+	}
+
+	return nil
+}
+
+// splitComma splits a comma-separated list, dropping empty entries
+func splitComma(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Validate checks that required settings are present and consistent,
+// reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("server port %d is out of range", c.Server.Port)
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		fail("at least one CORS origin is required")
+	}
+	if c.Server.AppURL == "" {
+		fail("APP_URL is required")
+	}
+
+	switch c.Database.Driver {
+	case "sqlite":
+		if c.Database.Path == "" {
+			fail("DB_PATH is required when DB_DRIVER=sqlite")
+		}
+	case "postgres":
+		if c.Database.URL == "" && c.Database.Host == "" {
+			fail("DB_HOST or DATABASE_URL must be set when DB_DRIVER=postgres")
+		}
+	default:
+		fail("unsupported DB_DRIVER %q (use sqlite or postgres)", c.Database.Driver)
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		fail("database pool sizes must not be negative")
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		fail("database connection lifetimes must not be negative")
+	}
+
+	if c.JWT.TTL <= 0 || c.JWT.RefreshTTL <= 0 {
+		fail("JWT token lifetimes must be positive")
+	}
+	if !c.AllowsDevShortcuts() && c.JWT.Secret == "" && len(c.JWT.Keys) == 0 {
+		fail("JWT_SECRET or JWT_KEYS must be set outside the development and test profiles")
+	}
+	if c.JWT.Secret != "" {
+		if _, err := HMACSecret("default", []byte(c.JWT.Secret)); err != nil {
+			fail("JWT_SECRET: %v", err)
+		}
+	}
+	for _, entry := range c.JWT.Keys {
+		spec, err := ParseKeySpec(entry)
+		if err != nil {
+			fail("%v", err)
+			continue
+		}
+		if spec.Algorithm != "HS256" {
+			continue
+		}
+		data, err := os.ReadFile(spec.Path)
+		if err != nil {
+			fail("reading JWT key %q: %v", spec.ID, err)
+			continue
+		}
+		if _, err := HMACSecret(spec.ID, data); err != nil {
+			fail("%v", err)
+		}
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			fail("SMTP_HOST must be set when MAIL_DRIVER=smtp")
+		}
+	default:
+		fail("unsupported MAIL_DRIVER %q (use smtp or log)", c.Mail.Driver)
+	}
+
+	if c.OIDC.Issuer != "" && c.OIDC.ClientID == "" {
+		fail("OIDC_CLIENT_ID must be set when OIDC_ISSUER is set")
+	}
+	if c.OIDC.MockIssuer {
+		if !c.AllowsDevShortcuts() {
+			fail("OIDC_MOCK_ISSUER is only allowed in the development and test profiles without GIN_MODE=release")
+		}
+		if c.OIDC.Issuer == "" {
+			fail("OIDC_MOCK_ISSUER needs OIDC_ISSUER set to its URL, e.g. http://localhost:8080/mock-oidc")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid %s configuration: %w", c.Profile, errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/apikeys"
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+// maxAPIKeysPerUser caps the active keys a user can hold
+const maxAPIKeysPerUser = 20
+
+// maxAdminKeyDays caps how long an admin key lasts, and is its default expiry
+const maxAdminKeyDays = 30
+
+// adminKeyMFAWindow is how recently the creating login must have passed
+// two-factor authentication to create an admin key
+const adminKeyMFAWindow = 15 * time.Minute
+
+type APIKeyController struct {
+	db    *gorm.DB
+	keys  *apikeys.Manager
+	audit *audit.Logger
+}
+
+func NewAPIKeyController(db *gorm.DB, keys *apikeys.Manager, auditLog *audit.Logger) *APIKeyController {
+	return &APIKeyController{db: db, keys: keys, audit: auditLog}
+}
+
+// GetMyAPIKeys lists the current user's API keys, including revoked ones
+func (kc *APIKeyController) GetMyAPIKeys(c *gin.Context) {
+	user, _ := c.Get("user")
+	kc.listKeys(c, user.(*models.User))
+}
+
+// CreateAPIKey creates an API key for the current user. The key is only
+// returned in this response.
+func (kc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	user, _ := c.Get("user")
+	kc.createKey(c, user.(*models.User))
+}
+
+// RevokeAPIKey revokes one of the current user's API keys
+func (kc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	user, _ := c.Get("user")
+	kc.revokeKey(c, user.(*models.User))
+}
+
+// GetUserAPIKeys lists a user's API keys (admin only)
+func (kc *APIKeyController) GetUserAPIKeys(c *gin.Context) {
+	owner, ok := kc.loadUser(c)
+	if !ok {
+		return
+	}
+	kc.listKeys(c, owner)
+}
+
+// CreateUserAPIKey creates an API key for another user, such as an account
+// used by a scoring integration (admin only)
+func (kc *APIKeyController) CreateUserAPIKey(c *gin.Context) {
+	owner, ok := kc.loadUser(c)
+	if !ok {
+		return
+	}
+	kc.createKey(c, owner)
+}
+
+// RevokeUserAPIKey revokes any user's API key (admin only)
+func (kc *APIKeyController) RevokeUserAPIKey(c *gin.Context) {
+	owner, ok := kc.loadUser(c)
+	if !ok {
+		return
+	}
+	kc.revokeKey(c, owner)
+}
+
+func (kc *APIKeyController) listKeys(c *gin.Context, owner *models.User) {
+	var keys []models.APIKey
+	if err := kc.db.Where("user_id = ?", owner.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch API keys",
+		})
+		return
+	}
+
+	keyResponses := make([]views.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		keyResponses = append(keyResponses, views.ToAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "API keys retrieved successfully",
+		Data:    keyResponses,
+	})
+}
+
+func (kc *APIKeyController) createKey(c *gin.Context, owner *models.User) {
+	actor := currentActor(c)
+
+	var req views.CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !req.Scope.IsValid() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_scope",
+			Message: "Scope must be one of read, score or admin",
+		})
+		return
+	}
+
+	if req.Scope == models.APIKeyAdmin && (!owner.IsAdmin() || !actor.IsAdmin()) {
+		c.JSON(http.StatusForbidden, views.ErrorResponse{
+			Error:   "admin_required",
+			Message: "Admin keys can only be created by an admin, for an admin, from a login that passed two-factor authentication",
+		})
+		return
+	}
+
+	if req.Scope == models.APIKeyAdmin {
+		if req.ExpiresInDays > maxAdminKeyDays {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_expiry",
+				Message: fmt.Sprintf("Admin keys can last at most %d days", maxAdminKeyDays),
+			})
+			return
+		}
+		if req.ExpiresInDays == 0 {
+			req.ExpiresInDays = maxAdminKeyDays
+		}
+
+		var session models.Session
+		if err := kc.db.First(&session, c.GetUint("session_id")).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to fetch session",
+			})
+			return
+		}
+		if !session.MFA || time.Since(session.CreatedAt) > adminKeyMFAWindow {
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "recent_mfa_required",
+				Message: "Log in again with two-factor authentication to create an admin key",
+			})
+			return
+		}
+	}
+
+	var active int64
+	if err := kc.db.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", owner.ID).Count(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to count API keys",
+		})
+		return
+	}
+	if active >= maxAPIKeysPerUser {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "too_many_keys",
+			Message: fmt.Sprintf("A user can have at most %d active API keys", maxAPIKeysPerUser),
+		})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		expiry := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	key, apiKey, err := kc.keys.Create(owner, actor.User, req.Name, req.Scope, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to create API key",
+		})
+		return
+	}
+
+	kc.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &actor.User.ID,
+		Action:     "apikey.created",
+		TargetType: "user",
+		TargetID:   &owner.ID,
+		Details:    fmt.Sprintf("key %d (%s) with %s scope", apiKey.ID, apiKey.Prefix, apiKey.Scope),
+	})
+
+	c.JSON(http.StatusCreated, views.SuccessResponse{
+		Message: "API key created. Copy it now, it will not be shown again",
+		Data: views.CreatedAPIKeyResponse{
+			Key:    key,
+			APIKey: views.ToAPIKeyResponse(*apiKey),
+		},
+	})
+}
+
+func (kc *APIKeyController) revokeKey(c *gin.Context, owner *models.User) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid API key ID",
+		})
+		return
+	}
+
+	var apiKey models.APIKey
+	if err := kc.db.Where("id = ? AND user_id = ?", uint(id), owner.ID).First(&apiKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "API key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch API key",
+		})
+		return
+	}
+
+	if err := kc.keys.Revoke(&apiKey); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to revoke API key",
+		})
+		return
+	}
+
+	kc.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "apikey.revoked",
+		TargetType: "user",
+		TargetID:   &owner.ID,
+		Details:    fmt.Sprintf("key %d (%s)", apiKey.ID, apiKey.Prefix),
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "API key revoked successfully",
+		Data:    views.ToAPIKeyResponse(apiKey),
+	})
+}
+
+// loadUser fetches the user named by the :user_id param,
+// writing an error response and returning false on failure
+func (kc *APIKeyController) loadUser(c *gin.Context) (*models.User, bool) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return nil, false
+	}
+
+	var user models.User
+	if err := kc.db.First(&user, uint(userID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "user_not_found",
+				Message: "User not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch user",
+		})
+		return nil, false
+	}
+	return &user, true
+}
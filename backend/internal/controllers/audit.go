@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+// defaultAuditLimit and maxAuditLimit bound how many entries one query returns
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+type AuditController struct {
+	db *gorm.DB
+}
+
+func NewAuditController(db *gorm.DB) *AuditController {
+	return &AuditController{db: db}
+}
+
+// GetAuditLogs lists audit entries, newest first, optionally filtered by
+// resource and resource ID, actor and action (Admin only)
+func (ac *AuditController) GetAuditLogs(c *gin.Context) {
+	query := ac.db.Model(&models.AuditLog{})
+	if resource := c.Query("resource"); resource != "" {
+		query = query.Where("target_type = ?", resource)
+	}
+	for param, column := range map[string]string{"id": "target_id", "actor_id": "actor_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_id",
+				Message: "Invalid " + param,
+			})
+			return
+		}
+		query = query.Where(column+" = ?", uint(id))
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	limit := defaultAuditLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_limit",
+				Message: "Limit must be between 1 and " + strconv.Itoa(maxAuditLimit),
+			})
+			return
+		}
+		limit = n
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch audit log",
+		})
+		return
+	}
+
+	entryResponses := make([]views.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		entryResponses[i] = views.ToAuditLogResponse(entry)
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Audit log retrieved successfully",
+		Data:    entryResponses,
+	})
+}
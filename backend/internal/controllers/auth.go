@@ -1,32 +1,70 @@
 package controllers
 
 import (
+	"io"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"badminton-backend/internal/apikeys"
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/mailer"
 	"badminton-backend/internal/middleware"
 	"badminton-backend/internal/models"
+	"badminton-backend/internal/oidc"
+	"badminton-backend/internal/sessions"
+	"badminton-backend/internal/throttle"
+	"badminton-backend/internal/usertokens"
 	"badminton-backend/internal/views"
 )
 
+// AuthServices are the collaborators AuthController uses besides the database
+type AuthServices struct {
+	Sessions *sessions.Manager
+	APIKeys  *apikeys.Manager
+	Tokens   *usertokens.Manager
+	Mailer   mailer.Mailer
+	Guard    *throttle.Guard
+	Audit    *audit.Logger
+	OIDC     *oidc.Provider // nil when single sign-on is not configured
+	AppURL   string         // frontend base URL used in emailed links and redirects
+}
+
 type AuthController struct {
-	db *gorm.DB
+	db       *gorm.DB
+	sessions *sessions.Manager
+	apiKeys  *apikeys.Manager
+	tokens   *usertokens.Manager
+	mailer   mailer.Mailer
+	guard    *throttle.Guard
+	audit    *audit.Logger
+	oidc     *oidc.Provider
+	appURL   string
 }
 
-func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{db: db}
+func NewAuthController(db *gorm.DB, services AuthServices) *AuthController {
+	return &AuthController{
+		db:       db,
+		sessions: services.Sessions,
+		apiKeys:  services.APIKeys,
+		tokens:   services.Tokens,
+		mailer:   services.Mailer,
+		guard:    services.Guard,
+		audit:    services.Audit,
+		oidc:     services.OIDC,
+		appURL:   services.AppURL,
+	}
 }
 
 // Register creates new user account
 func (ac *AuthController) Register(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
-		FullName string `json:"full_name" binding:"required"`
-	}
+	var req views.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
@@ -64,8 +102,20 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(&user)
+	ac.audit.RecordChange(c, models.AuditLog{
+		ActorID:    &user.ID,
+		Action:     "user.registered",
+		TargetType: "user",
+		TargetID:   &user.ID,
+	}, nil, user)
+
+	// The account works without a verified email; only registration needs it
+	if err := ac.sendVerificationEmail(&user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// Start a session
+	tokens, err := ac.sessions.Issue(&user, false, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "token_generation_failed",
@@ -76,19 +126,13 @@ func (ac *AuthController) Register(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, views.SuccessResponse{
 		Message: "User registered successfully",
-		Data: gin.H{
-			"user":  views.ToUserResponse(user),
-			"token": token,
-		},
+		Data:    authData(user, tokens),
 	})
 }
 
 // Login authenticates user
 func (ac *AuthController) Login(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
+	var req views.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
@@ -98,19 +142,49 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	ipKey := throttle.IPKey(c.ClientIP())
+
 	// Find user by username or email
 	var user models.User
 	err := ac.db.Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+	found := err == nil
+
+	// Failures count against the account, so guesses made under its
+	// username and its email share one limit. Unknown names are throttled
+	// on the name as typed so they lock out exactly like real accounts.
+	accountKey := throttle.IdentifierKey(req.Username)
+	if found {
+		accountKey = throttle.AccountKey(user.ID)
+	}
+
+	// Refuse attempts while the account or client is backing off
+	wait, err := ac.guard.RetryAfter(accountKey, ipKey)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
-			Error:   "invalid_credentials",
-			Message: "Invalid username or password",
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
 		})
 		return
 	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return
+	}
 
-	// Check password
-	if !user.CheckPassword(req.Password) {
+	// Check password. Unknown users are checked against a dummy hash so
+	// both failures take the same time.
+	if !found {
+		dummyUser.CheckPassword(req.Password)
+	}
+	if !found || !user.CheckPassword(req.Password) {
+		ac.recordLoginFailure(c, req.Username, accountKey, ipKey, found, user.ID)
 		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
 			Error:   "invalid_credentials",
 			Message: "Invalid username or password",
@@ -127,8 +201,43 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(&user)
+	// 2FA users are only cleared once the second step succeeds
+	if !user.IsTOTPEnabled() {
+		if err := ac.guard.Reset(loginThrottleKeys(&user)...); err != nil {
+			log.Printf("Failed to reset login throttle for user %d: %v", user.ID, err)
+		}
+	}
+
+	ac.completeLogin(c, &user)
+}
+
+// completeLogin finishes a login whose first factor has been checked:
+// users with 2FA on get a challenge for the second step, everyone else
+// gets a session
+func (ac *AuthController) completeLogin(c *gin.Context, user *models.User) {
+	if user.IsTOTPEnabled() {
+		challenge, err := ac.tokens.Issue(user, models.TokenMFAChallenge, usertokens.MFAChallengeTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "token_generation_failed",
+				Message: "Failed to start two-factor authentication",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.SuccessResponse{
+			Message: "Two-factor authentication required",
+			Data: views.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    challenge,
+				ExpiresIn:   int64(usertokens.MFAChallengeTTL.Seconds()),
+			},
+		})
+		return
+	}
+
+	// Start a session
+	tokens, err := ac.sessions.Issue(user, false, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "token_generation_failed",
@@ -137,12 +246,11 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	ac.recordLogin(c, user)
+
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Login successful",
-		Data: gin.H{
-			"user":  views.ToUserResponse(user),
-			"token": token,
-		},
+		Data:    authData(*user, tokens),
 	})
 }
 
@@ -159,11 +267,9 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 	user, _ := c.Get("user")
 	userObj := user.(*models.User)
 
-	var req struct {
-		FullName string `json:"full_name"`
-		Email    string `json:"email"`
-		Ranking  int    `json:"ranking"` // Only for players
-	}
+	before := *userObj
+
+	var req views.UpdateProfileRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
@@ -177,8 +283,11 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 	if req.FullName != "" {
 		userObj.FullName = req.FullName
 	}
-	if req.Email != "" {
+	emailChanged := req.Email != "" && req.Email != userObj.Email
+	if emailChanged {
+		// A new address has to be verified again
 		userObj.Email = req.Email
+		userObj.EmailVerifiedAt = nil
 	}
 	if req.Ranking > 0 && userObj.IsPlayer() {
 		userObj.Ranking = req.Ranking
@@ -192,6 +301,18 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	ac.audit.RecordChange(c, models.AuditLog{
+		Action:     "user.profile_updated",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	}, before, *userObj)
+
+	if emailChanged {
+		if err := ac.sendVerificationEmail(userObj); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", userObj.ID, err)
+		}
+	}
+
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Profile updated successfully",
 		Data:    views.ToUserResponse(*userObj),
@@ -203,10 +324,7 @@ func (ac *AuthController) ChangePassword(c *gin.Context) {
 	user, _ := c.Get("user")
 	userObj := user.(*models.User)
 
-	var req struct {
-		CurrentPassword string `json:"current_password" binding:"required"`
-		NewPassword     string `json:"new_password" binding:"required,min=6"`
-	}
+	var req views.ChangePasswordRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
@@ -243,8 +361,116 @@ func (ac *AuthController) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	ac.audit.RecordRequest(c, models.AuditLog{
+		Action:     "user.password_changed",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	})
+
+	// Log out every device and revoke API keys, then start a fresh session
+	// for this one
+	if err := ac.revokeAccess(userObj.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to revoke sessions and API keys",
+		})
+		return
+	}
+
+	tokens, err := ac.sessions.Issue(userObj, c.GetBool("mfa"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate authentication token",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Password changed successfully",
+		Data:    authData(*userObj, tokens),
+	})
+}
+
+// RefreshToken exchanges a refresh token for a new access and refresh token
+func (ac *AuthController) RefreshToken(c *gin.Context) {
+	var req views.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tokens, user, err := ac.sessions.Refresh(req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err == sessions.ErrInvalidRefreshToken || err == sessions.ErrRefreshTokenReused {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_refresh_token",
+			Message: "Refresh token is invalid, expired or revoked",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to refresh authentication token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Token refreshed successfully",
+		Data:    authData(*user, tokens),
+	})
+}
+
+// Logout ends the current session
+func (ac *AuthController) Logout(c *gin.Context) {
+	sessionID := c.GetUint("session_id")
+
+	if err := ac.sessions.Revoke(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to log out",
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	ac.audit.RecordRequest(c, models.AuditLog{
+		Action:     "logout",
+		TargetType: "user",
+		TargetID:   &userID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// LogoutAll ends every session of the current user
+func (ac *AuthController) LogoutAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	if err := ac.revokeAccess(userObj.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to log out",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		Action:     "logout.all",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Logged out of all devices successfully",
 	})
 }
 
@@ -252,9 +478,7 @@ func (ac *AuthController) ChangePassword(c *gin.Context) {
 func (ac *AuthController) UpdateUserRole(c *gin.Context) {
 	userID := c.Param("user_id")
 
-	var req struct {
-		Role string `json:"role" binding:"required"`
-	}
+	var req views.UpdateUserRoleRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
@@ -284,6 +508,7 @@ func (ac *AuthController) UpdateUserRole(c *gin.Context) {
 	}
 
 	// Update role
+	before := targetUser
 	targetUser.Role = models.UserRole(req.Role)
 	if err := ac.db.Save(&targetUser).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
@@ -293,16 +518,160 @@ func (ac *AuthController) UpdateUserRole(c *gin.Context) {
 		return
 	}
 
+	ac.audit.RecordChange(c, models.AuditLog{
+		Action:     "user.role_changed",
+		TargetType: "user",
+		TargetID:   &targetUser.ID,
+	}, before, targetUser)
+
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "User role updated successfully",
 		Data:    views.ToUserResponse(targetUser),
 	})
 }
 
-// GetAllUsers returns all users (Admin only)
+// UpdateUserStatus activates or deactivates a user account (Admin only).
+// Deactivating a user revokes all of their sessions.
+func (ac *AuthController) UpdateUserStatus(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	var req views.UpdateUserStatusRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Find target user
+	var targetUser models.User
+	if err := ac.db.First(&targetUser, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "user_not_found",
+			Message: "User not found",
+		})
+		return
+	}
+
+	before := targetUser
+	targetUser.IsActive = *req.IsActive
+	if err := ac.db.Model(&targetUser).Update("is_active", targetUser.IsActive).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update user status",
+		})
+		return
+	}
+
+	ac.audit.RecordChange(c, models.AuditLog{
+		Action:     "user.status_changed",
+		TargetType: "user",
+		TargetID:   &targetUser.ID,
+	}, before, targetUser)
+
+	if !targetUser.IsActive {
+		if err := ac.revokeAccess(targetUser.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to revoke user sessions and API keys",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "User status updated successfully",
+		Data:    views.ToUserResponse(targetUser),
+	})
+}
+
+// UnlockUser clears a user's failed-login lockout, and optionally that of
+// a client address (Admin only)
+func (ac *AuthController) UnlockUser(c *gin.Context) {
+	admin, _ := c.Get("user")
+	adminObj := admin.(*models.User)
+
+	var req views.UnlockUserRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var targetUser models.User
+	if err := ac.db.First(&targetUser, c.Param("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "user_not_found",
+			Message: "User not found",
+		})
+		return
+	}
+
+	keys := loginThrottleKeys(&targetUser)
+	if req.IPAddress != "" {
+		keys = append(keys, throttle.IPKey(req.IPAddress))
+	}
+
+	if err := ac.guard.Reset(keys...); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to unlock user",
+		})
+		return
+	}
+
+	details := "account unlocked"
+	if req.IPAddress != "" {
+		details += ", ip " + req.IPAddress + " unlocked"
+	}
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &adminObj.ID,
+		Action:     "login.unlocked",
+		TargetType: "user",
+		TargetID:   &targetUser.ID,
+		Details:    details,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "User unlocked successfully",
+	})
+}
+
+// UserListing is what the user list accepts
+var UserListing = listing.Spec{
+	Filters: map[string]listing.Filter{
+		"role":           listing.OneOf("role", string(models.RolePlayer), string(models.RoleAdmin)),
+		"is_active":      listing.Bool("is_active"),
+		"email_verified": listing.IsSet("email_verified_at"),
+		"claim_pending":  listing.Bool("claim_pending"),
+		"search":         listing.Search("username", "email", "full_name"),
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"email":      "email",
+		"role":       "role",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id",
+}
+
+// GetAllUsers lists users a page at a time, filtered by role, status and
+// name (Admin only)
 func (ac *AuthController) GetAllUsers(c *gin.Context) {
+	q, ok := parseListing(c, UserListing)
+	if !ok {
+		return
+	}
+
 	var users []models.User
-	if err := ac.db.Find(&users).Error; err != nil {
+	page, err := q.Find(ac.db, &users)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "database_error",
 			Message: "Failed to fetch users",
@@ -318,5 +687,100 @@ func (ac *AuthController) GetAllUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Users retrieved successfully",
 		Data:    userResponses,
+		Meta:    views.ToPaginationMeta(page),
+	})
+}
+
+// GetJWKS publishes the public keys used to sign access tokens
+func (ac *AuthController) GetJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.PublicJWKS())
+}
+
+// authData is the response payload for a successful authentication
+func authData(user models.User, tokens sessions.Tokens) views.AuthResponse {
+	return views.AuthResponse{
+		User:         views.ToUserResponse(user),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+// dummyUser is checked against when no account matches the login name, so
+// unknown usernames take as long to reject as wrong passwords
+var dummyUser = func() models.User {
+	user := models.User{Password: "not-a-real-password"}
+	user.HashPassword()
+	return user
+}()
+
+// recordLogin audits a login that started a session
+func (ac *AuthController) recordLogin(c *gin.Context, user *models.User) {
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &user.ID,
+		Action:     "login.succeeded",
+		TargetType: "user",
+		TargetID:   &user.ID,
+	})
+}
+
+// revokeAccess logs a user out on every device and revokes their API keys,
+// so a changed password or a lost device leaves no working credential
+func (ac *AuthController) revokeAccess(userID uint) error {
+	if err := ac.sessions.RevokeAll(userID); err != nil {
+		return err
+	}
+	return ac.apiKeys.RevokeAll(userID)
+}
+
+// loginThrottleKeys are the keys a user's failed logins may have counted
+// against: the account, and both of its names from before they matched it
+func loginThrottleKeys(user *models.User) []string {
+	return []string{
+		throttle.AccountKey(user.ID),
+		throttle.IdentifierKey(user.Username),
+		throttle.IdentifierKey(user.Email),
+	}
+}
+
+// recordLoginFailure counts a failed login against the account and the
+// client address and audits any lockout it causes
+func (ac *AuthController) recordLoginFailure(c *gin.Context, identifier, accountKey, ipKey string, found bool, userID uint) {
+	lockedOut, err := ac.guard.Fail(accountKey, throttle.AccountPolicy)
+	if err != nil {
+		log.Printf("Failed to record login failure for %s: %v", accountKey, err)
+	}
+	if lockedOut {
+		entry := models.AuditLog{
+			Action:     "login.locked_out",
+			TargetType: "user",
+			Details:    "account locked after repeated failed logins as " + strconv.Quote(identifier),
+		}
+		if found {
+			entry.TargetID = &userID
+		}
+		ac.audit.RecordRequest(c, entry)
+	}
+
+	lockedOut, err = ac.guard.Fail(ipKey, throttle.IPPolicy)
+	if err != nil {
+		log.Printf("Failed to record login failure for %s: %v", ipKey, err)
+	}
+	if lockedOut {
+		ac.audit.RecordRequest(c, models.AuditLog{
+			Action:     "login.ip_locked_out",
+			TargetType: "ip",
+			Details:    "client locked after repeated failed logins",
+		})
+	}
+}
+
+// respondTooManyAttempts rejects a login attempt made while backing off
+func respondTooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	c.JSON(http.StatusTooManyRequests, views.ErrorResponse{
+		Error:   "too_many_attempts",
+		Message: "Too many failed login attempts, try again in " + strconv.FormatInt(seconds, 10) + " seconds",
 	})
 }
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+var errUsernameTaken = errors.New("username is taken")
+
+// ClaimAccount lets a converted legacy player take over their account with
+// an emailed invite, choosing a password and optionally a new username
+func (ac *AuthController) ClaimAccount(c *gin.Context) {
+	var req views.ClaimAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user, before models.User
+	err := ac.tokens.Redeem(req.Token, models.TokenAccountClaim, func(tx *gorm.DB, userToken *models.UserToken) error {
+		user = userToken.User
+		before = user
+		// The invite only works while the account is unclaimed and still
+		// has the address it was sent to
+		if !user.ClaimPending || !user.IsActive || userToken.Email != user.Email {
+			return usertokens.ErrInvalidToken
+		}
+
+		if req.Username != "" && req.Username != user.Username {
+			// Usernames differing only in case would be confused at login
+			var count int64
+			if err := tx.Unscoped().Model(&models.User{}).Where("LOWER(username) = LOWER(?) AND id <> ?", req.Username, user.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errUsernameTaken
+			}
+			user.Username = req.Username
+		}
+
+		user.Password = req.Password
+		if err := user.HashPassword(); err != nil {
+			return err
+		}
+		user.ClaimPending = false
+		updates := map[string]interface{}{
+			"username":      user.Username,
+			"password":      user.Password,
+			"claim_pending": false,
+		}
+		// Receiving the invite proves the address is theirs
+		if !user.IsEmailVerified() {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			updates["email_verified_at"] = now
+		}
+		return tx.Model(&user).Updates(updates).Error
+	})
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_token",
+			Message: "Invite link is invalid or has expired",
+		})
+		return
+	}
+	if err == errUsernameTaken {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "username_taken",
+			Message: "This username is already taken",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to claim account",
+		})
+		return
+	}
+
+	ac.audit.RecordChange(c, models.AuditLog{
+		ActorID:    &user.ID,
+		Action:     "user.claimed",
+		TargetType: "user",
+		TargetID:   &user.ID,
+	}, before, user)
+
+	tokens, err := ac.sessions.Issue(&user, false, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate authentication token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Account claimed successfully",
+		Data:    authData(user, tokens),
+	})
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/mailer"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+// VerifyEmail confirms the user's email address with an emailed token
+func (ac *AuthController) VerifyEmail(c *gin.Context) {
+	var req views.TokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	err := ac.tokens.Redeem(req.Token, models.TokenEmailVerification, func(tx *gorm.DB, userToken *models.UserToken) error {
+		user = userToken.User
+		// The address may have changed since the link was sent
+		if userToken.Email != user.Email {
+			return usertokens.ErrInvalidToken
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_token",
+			Message: "Verification link is invalid or has expired",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to verify email",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &user.ID,
+		Action:     "user.email_verified",
+		TargetType: "user",
+		TargetID:   &user.ID,
+		Details:    "verified " + user.Email,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Email verified successfully",
+		Data:    views.ToUserResponse(user),
+	})
+}
+
+// ResendVerification emails a new verification link to the current user
+func (ac *AuthController) ResendVerification(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	if userObj.IsEmailVerified() {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "email_already_verified",
+			Message: "Your email address is already verified",
+		})
+		return
+	}
+
+	if err := ac.sendVerificationEmail(userObj); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", userObj.ID, err)
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "email_failed",
+			Message: "Failed to send verification email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// ForgotPassword emails a password reset link. The response is the same
+// whether or not the address belongs to an account, and the link is sent
+// in the background so the response time does not tell either.
+func (ac *AuthController) ForgotPassword(c *gin.Context) {
+	var req views.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := ac.db.Where("email = ? AND is_active = ?", req.Email, true).First(&user).Error; err == nil {
+		request := c.Copy()
+		go func() {
+			ac.audit.RecordRequest(request, models.AuditLog{
+				Action:     "user.password_reset_requested",
+				TargetType: "user",
+				TargetID:   &user.ID,
+			})
+			if err := ac.sendPasswordResetEmail(&user); err != nil {
+				log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+			}
+		}()
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with an emailed token and logs the
+// user out everywhere
+func (ac *AuthController) ResetPassword(c *gin.Context) {
+	var req views.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var userID uint
+	var throttleKeys []string
+	err := ac.tokens.Redeem(req.Token, models.TokenPasswordReset, func(tx *gorm.DB, userToken *models.UserToken) error {
+		user := userToken.User
+		userID = user.ID
+		throttleKeys = loginThrottleKeys(&user)
+		user.Password = req.NewPassword
+		if err := user.HashPassword(); err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"password": user.Password}
+		// Receiving the link proves the address is theirs
+		if !user.IsEmailVerified() && userToken.Email == user.Email {
+			updates["email_verified_at"] = time.Now()
+		}
+		// Choosing a password also claims a converted legacy account
+		if user.ClaimPending && userToken.Email == user.Email {
+			updates["claim_pending"] = false
+		}
+		return tx.Model(&user).Updates(updates).Error
+	})
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_token",
+			Message: "Password reset link is invalid or has expired",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to reset password",
+		})
+		return
+	}
+
+	if err := ac.revokeAccess(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to revoke sessions and API keys",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userID,
+		Action:     "user.password_reset",
+		TargetType: "user",
+		TargetID:   &userID,
+	})
+
+	// Proving control of the mailbox lifts any failed-login lockout
+	if err := ac.guard.Reset(throttleKeys...); err != nil {
+		log.Printf("Failed to reset login throttle for user %d: %v", userID, err)
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Password reset successfully, please log in with your new password",
+	})
+}
+
+// sendVerificationEmail issues a verification token and emails its link
+func (ac *AuthController) sendVerificationEmail(user *models.User) error {
+	token, err := ac.tokens.Issue(user, models.TokenEmailVerification, usertokens.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return ac.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Hi " + user.FullName + ",\n\n" +
+			"Please confirm your email address to register for tournaments:\n\n" +
+			ac.appURL + "/auth/verify-email?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link expires in 48 hours.",
+	})
+}
+
+// sendPasswordResetEmail issues a password reset token and emails its link
+func (ac *AuthController) sendPasswordResetEmail(user *models.User) error {
+	token, err := ac.tokens.Issue(user, models.TokenPasswordReset, usertokens.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return ac.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Hi " + user.FullName + ",\n\n" +
+			"Someone asked to reset the password for your account. If it was you, open:\n\n" +
+			ac.appURL + "/auth/reset-password?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link expires in 1 hour and can only be used once. If you did not ask for this, ignore this email.",
+	})
+}
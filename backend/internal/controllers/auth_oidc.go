@@ -0,0 +1,542 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/oidc"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+// oidcRequestTTL is how long the user has to finish signing in at the provider
+const oidcRequestTTL = 10 * time.Minute
+
+// oidcStateCookie holds the hash of the state of the browser's request in
+// flight, so only the browser that started a login can complete it
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/v1/auth/oidc"
+)
+
+// errIdentityInUse is returned when linking an identity that belongs to another user
+var errIdentityInUse = errors.New("identity is linked to another user")
+
+// usernameDisallowed matches characters not kept in provisioned usernames
+var usernameDisallowed = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// OIDCLogin starts single sign-on by redirecting the browser to the provider
+func (ac *AuthController) OIDCLogin(c *gin.Context) {
+	if !ac.requireOIDC(c) {
+		return
+	}
+
+	authURL, err := ac.startOIDCRequest(c, nil)
+	if err != nil {
+		log.Printf("Failed to start OIDC login: %v", err)
+		ac.redirectToApp(c, "/auth/login", "sso_unavailable")
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// LinkOIDCIdentity starts linking a provider account to the current user.
+// The frontend sends the browser to the returned authorization URL.
+func (ac *AuthController) LinkOIDCIdentity(c *gin.Context) {
+	if !ac.requireOIDC(c) {
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	authURL, err := ac.startOIDCRequest(c, &userObj.ID)
+	if err != nil {
+		log.Printf("Failed to start OIDC link for user %d: %v", userObj.ID, err)
+		c.JSON(http.StatusBadGateway, views.ErrorResponse{
+			Error:   "sso_unavailable",
+			Message: "Single sign-on provider is unavailable",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Continue at the single sign-on provider",
+		Data:    views.AuthorizationURLResponse{AuthorizationURL: authURL},
+	})
+}
+
+// OIDCCallback handles the provider's redirect. A login is handed to the
+// frontend as a one-time code for ExchangeOIDCLogin; a link is saved and
+// the browser returns to the security page.
+func (ac *AuthController) OIDCCallback(c *gin.Context) {
+	if !ac.requireOIDC(c) {
+		return
+	}
+
+	// The state must be the one this browser was sent off with
+	stateCookie, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	stateHash := hashOIDCState(c.Query("state"))
+	if stateCookie == "" || subtle.ConstantTimeCompare([]byte(stateCookie), []byte(stateHash)) != 1 {
+		ac.redirectToApp(c, "/auth/login", "sso_expired")
+		return
+	}
+
+	if c.Query("error") != "" || c.Query("code") == "" {
+		ac.redirectToApp(c, "/auth/login", "sso_denied")
+		return
+	}
+
+	request, err := ac.consumeOIDCRequest(c.Query("state"))
+	if err != nil {
+		ac.redirectToApp(c, "/auth/login", "sso_expired")
+		return
+	}
+
+	claims, err := ac.oidc.Exchange(c.Request.Context(), c.Query("code"), request.CodeVerifier, request.Nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		ac.redirectToApp(c, "/auth/login", "sso_failed")
+		return
+	}
+
+	if request.LinkUserID != nil {
+		err := ac.linkIdentity(*request.LinkUserID, claims, c.ClientIP(), *request.LinkUserID)
+		if err == errIdentityInUse {
+			ac.redirectToApp(c, "/security", "identity_in_use")
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to link OIDC identity to user %d: %v", *request.LinkUserID, err)
+			ac.redirectToApp(c, "/security", "sso_failed")
+			return
+		}
+		c.Redirect(http.StatusFound, ac.appURL+"/security?linked=1")
+		return
+	}
+
+	user, errorCode := ac.resolveOIDCUser(claims, c.ClientIP())
+	if user == nil {
+		ac.redirectToApp(c, "/auth/login", errorCode)
+		return
+	}
+
+	if !user.IsActive {
+		ac.redirectToApp(c, "/auth/login", "account_deactivated")
+		return
+	}
+
+	code, err := ac.tokens.Issue(user, models.TokenOIDCLogin, usertokens.OIDCLoginTTL)
+	if err != nil {
+		log.Printf("Failed to issue OIDC login code for user %d: %v", user.ID, err)
+		ac.redirectToApp(c, "/auth/login", "sso_failed")
+		return
+	}
+
+	c.Redirect(http.StatusFound, ac.appURL+"/auth/oidc/callback?code="+url.QueryEscape(code))
+}
+
+// ExchangeOIDCLogin swaps the one-time code from OIDCCallback for tokens,
+// or a two-factor challenge when the user has 2FA on
+func (ac *AuthController) ExchangeOIDCLogin(c *gin.Context) {
+	if !ac.requireOIDC(c) {
+		return
+	}
+
+	var req views.OIDCExchangeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	err := ac.tokens.Redeem(req.Code, models.TokenOIDCLogin, func(tx *gorm.DB, userToken *models.UserToken) error {
+		user = userToken.User
+		return nil
+	})
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_code",
+			Message: "Sign-in code is invalid or has expired, please sign in again",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "account_deactivated",
+			Message: "Your account has been deactivated",
+		})
+		return
+	}
+
+	ac.completeLogin(c, &user)
+}
+
+// GetOIDCIdentities lists the provider accounts linked to the current user
+func (ac *AuthController) GetOIDCIdentities(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var identities []models.UserIdentity
+	if err := ac.db.Where("user_id = ?", userObj.ID).Find(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch linked accounts",
+		})
+		return
+	}
+
+	identityResponses := make([]views.IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		identityResponses = append(identityResponses, views.ToIdentityResponse(identity))
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Linked accounts retrieved successfully",
+		Data:    identityResponses,
+	})
+}
+
+// UnlinkOIDCIdentity removes a linked provider account from the current user
+func (ac *AuthController) UnlinkOIDCIdentity(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid identity ID",
+		})
+		return
+	}
+
+	result := ac.db.Unscoped().Where("id = ? AND user_id = ?", uint(id), userObj.ID).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to unlink account",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "not_found",
+			Message: "Linked account not found",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "oidc.unlinked",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Account unlinked successfully",
+	})
+}
+
+// requireOIDC writes a 404 and returns false when single sign-on is not configured
+func (ac *AuthController) requireOIDC(c *gin.Context) bool {
+	if ac.oidc == nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "oidc_disabled",
+			Message: "Single sign-on is not configured",
+		})
+		return false
+	}
+	return true
+}
+
+// startOIDCRequest records a new authorization request, binds it to the
+// browser with a cookie and returns the provider URL for it. linkUserID is
+// set when linking to a signed-in user.
+func (ac *AuthController) startOIDCRequest(c *gin.Context, linkUserID *uint) (string, error) {
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomString()
+		if err != nil {
+			return "", err
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := ac.oidc.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	request := models.OIDCAuthRequest{
+		StateHash:    hashOIDCState(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oidcRequestTTL),
+	}
+	if err := ac.db.Create(&request).Error; err != nil {
+		return "", err
+	}
+
+	setOIDCStateCookie(c, request.StateHash, int(oidcRequestTTL.Seconds()))
+	return authURL, nil
+}
+
+// setOIDCStateCookie sets the state cookie, or clears it when maxAge is
+// negative. Lax lets it ride along on the provider's top-level redirect
+// back to the callback.
+func setOIDCStateCookie(c *gin.Context, stateHash string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateHash,
+		Path:     oidcStateCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// consumeOIDCRequest uses up the authorization request named by state
+func (ac *AuthController) consumeOIDCRequest(state string) (*models.OIDCAuthRequest, error) {
+	now := time.Now()
+
+	var request models.OIDCAuthRequest
+	if err := ac.db.Where("state_hash = ?", hashOIDCState(state)).First(&request).Error; err != nil {
+		return nil, err
+	}
+
+	result := ac.db.Model(&request).
+		Where("used_at IS NULL AND expires_at > ?", now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, usertokens.ErrInvalidToken
+	}
+
+	return &request, nil
+}
+
+// resolveOIDCUser finds the user for a provider login: by linked identity,
+// then by a verified email matching an existing account, and otherwise by
+// provisioning a new player. On failure it returns an error code for the
+// frontend.
+func (ac *AuthController) resolveOIDCUser(claims *oidc.Claims, ip string) (*models.User, string) {
+	var identity models.UserIdentity
+	err := ac.db.Preload("User").
+		Where("issuer = ? AND subject = ?", ac.oidc.Issuer(), claims.Subject).
+		First(&identity).Error
+	if err == nil {
+		return &identity.User, ""
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Printf("Failed to look up OIDC identity: %v", err)
+		return nil, "sso_failed"
+	}
+
+	if claims.Email == "" {
+		return nil, "email_required"
+	}
+
+	var user models.User
+	err = ac.db.Where("email = ?", claims.Email).First(&user).Error
+	if err == nil {
+		// Only trust the address for linking if the provider has verified it
+		if !claims.IsEmailVerified() {
+			return nil, "email_in_use"
+		}
+		if err := ac.linkIdentity(user.ID, claims, ip, 0); err != nil {
+			log.Printf("Failed to link OIDC identity to user %d: %v", user.ID, err)
+			return nil, "sso_failed"
+		}
+		return &user, ""
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Printf("Failed to look up user by email: %v", err)
+		return nil, "sso_failed"
+	}
+
+	provisioned, err := ac.provisionOIDCUser(claims, ip)
+	if err != nil {
+		log.Printf("Failed to provision OIDC user: %v", err)
+		return nil, "sso_failed"
+	}
+	return provisioned, ""
+}
+
+// linkIdentity links the provider account to the user. actorID is the
+// user who asked for the link, or 0 when it was linked by verified email.
+func (ac *AuthController) linkIdentity(userID uint, claims *oidc.Claims, ip string, actorID uint) error {
+	var existing models.UserIdentity
+	err := ac.db.Where("issuer = ? AND subject = ?", ac.oidc.Issuer(), claims.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			return errIdentityInUse
+		}
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	if err := ac.db.Create(&models.UserIdentity{
+		UserID:  userID,
+		Issuer:  ac.oidc.Issuer(),
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	}).Error; err != nil {
+		return err
+	}
+
+	entry := models.AuditLog{
+		Action:     "oidc.linked",
+		TargetType: "user",
+		TargetID:   &userID,
+		IPAddress:  ip,
+		Details:    "linked " + ac.oidc.Issuer() + " account " + claims.Subject,
+	}
+	if actorID != 0 {
+		entry.ActorID = &actorID
+	} else {
+		entry.Details += " by verified email"
+	}
+	ac.audit.Record(entry)
+	return nil
+}
+
+// provisionOIDCUser creates a player account for a first-time provider login.
+// The random password means the account can only sign in through the
+// provider until the user sets one with the password reset flow.
+func (ac *AuthController) provisionOIDCUser(claims *oidc.Claims, ip string) (*models.User, error) {
+	password, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := ac.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := claims.Name
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := models.User{
+		Username: username,
+		Email:    claims.Email,
+		Password: password,
+		FullName: fullName,
+		Role:     models.RolePlayer,
+		IsActive: true,
+	}
+	if claims.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := user.HashPassword(); err != nil {
+		return nil, err
+	}
+
+	err = ac.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserIdentity{
+			UserID:  user.ID,
+			Issuer:  ac.oidc.Issuer(),
+			Subject: claims.Subject,
+			Email:   claims.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ac.audit.Record(models.AuditLog{
+		Action:     "oidc.user_provisioned",
+		TargetType: "user",
+		TargetID:   &user.ID,
+		IPAddress:  ip,
+		Details:    "provisioned from " + ac.oidc.Issuer() + " account " + claims.Subject,
+	})
+	return &user, nil
+}
+
+// availableUsername derives an unused username from the provider's
+// preferred username or the email address
+func (ac *AuthController) availableUsername(claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.Split(claims.Email, "@")[0]
+	}
+	base = usernameDisallowed.ReplaceAllString(strings.ToLower(base), "")
+	if base == "" {
+		base = "player"
+	}
+
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = base + strconv.Itoa(i)
+		}
+
+		var count int64
+		if err := ac.db.Unscoped().Model(&models.User{}).Where("LOWER(username) = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	suffix, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	return base + "-" + strings.ToLower(suffix[:6]), nil
+}
+
+// redirectToApp sends the browser to a frontend page with an error code
+func (ac *AuthController) redirectToApp(c *gin.Context, path, errorCode string) {
+	c.Redirect(http.StatusFound, ac.appURL+path+"?error="+url.QueryEscape(errorCode))
+}
+
+// hashOIDCState returns the hash under which a state value is stored
+func hashOIDCState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/views"
+)
+
+// currentActor returns the signed-in user as a policy actor
+func currentActor(c *gin.Context) policy.Actor {
+	user, _ := c.Get("user")
+	return policy.Actor{User: user.(*models.User), MFA: c.GetBool("mfa")}
+}
+
+// authorize turns a policy decision into a response: a 403 for a denial or
+// an error status when the policy could not decide. It returns true when
+// the action is allowed.
+func authorize(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var denied *policy.Denied
+	switch {
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, views.ErrorResponse{
+			Error:   denied.Code,
+			Message: denied.Reason,
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "tournament_not_found",
+			Message: "Tournament not found",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check permissions",
+		})
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/services"
+	"badminton-backend/internal/views"
+)
+
+type CheckInController struct {
+	checkIns services.CheckInService
+	audit    *audit.Logger
+}
+
+func NewCheckInController(checkIns services.CheckInService, auditLog *audit.Logger) *CheckInController {
+	return &CheckInController{checkIns: checkIns, audit: auditLog}
+}
+
+// GetCheckIns lists a tournament's entrants with their check-in state (desk view)
+func (cc *CheckInController) GetCheckIns(c *gin.Context) {
+	tournament, ok := cc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	entrants := []views.EntrantResponse{}
+	if tournament.IsTeamTournament() {
+		registrations, err := cc.checkIns.ListTeams(tournament.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to fetch entrants",
+			})
+			return
+		}
+		for _, r := range registrations {
+			entrants = append(entrants, views.ToTeamEntrantResponse(r))
+		}
+	} else {
+		registrations, err := cc.checkIns.ListPlayers(tournament.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to fetch entrants",
+			})
+			return
+		}
+		for _, r := range registrations {
+			entrants = append(entrants, views.ToPlayerEntrantResponse(r))
+		}
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Entrants retrieved successfully",
+		Data: views.CheckInListResponse{
+			CheckInOpensAt:  tournament.CheckInOpensAt(),
+			CheckInClosedAt: tournament.CheckInClosedAt,
+			Entrants:        entrants,
+		},
+	})
+}
+
+// SelfCheckIn checks in the current player (or their team) during the check-in window
+func (cc *CheckInController) SelfCheckIn(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	tournament, ok := cc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	if !cc.checkCheckInOpen(c, tournament) {
+		return
+	}
+
+	if !tournament.IsSelfCheckInOpen(time.Now()) {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "check_in_not_open",
+			Message: "Self check-in opens " + strconv.Itoa(tournament.CheckInWindowMinutes) + " minutes before the tournament starts",
+		})
+		return
+	}
+
+	if tournament.IsTeamTournament() {
+		registration, err := cc.checkIns.FindTeamByMember(tournament.ID, userObj.ID)
+		if err != nil {
+			respondError(c, err, "Failed to find registration")
+			return
+		}
+		cc.checkInTeam(c, registration)
+		return
+	}
+
+	registration, err := cc.checkIns.FindPlayer(tournament.ID, userObj.ID)
+	if err != nil {
+		respondError(c, err, "Failed to find registration")
+		return
+	}
+	cc.checkInPlayer(c, registration)
+}
+
+// DeskCheckInPlayer checks in a registered player on their behalf (Admin only)
+func (cc *CheckInController) DeskCheckInPlayer(c *gin.Context) {
+	tournament, ok := cc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	if tournament.IsTeamTournament() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "doubles_tournament",
+			Message: "This is a doubles tournament, check in the team instead",
+		})
+		return
+	}
+
+	playerID, err := strconv.ParseUint(c.Param("player_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid player ID",
+		})
+		return
+	}
+
+	if !cc.checkCheckInOpen(c, tournament) {
+		return
+	}
+
+	registration, err := cc.checkIns.FindPlayer(tournament.ID, uint(playerID))
+	if err != nil {
+		respondError(c, err, "Failed to find registration")
+		return
+	}
+	cc.checkInPlayer(c, registration)
+}
+
+// DeskCheckInTeam checks in a registered team on their behalf (Admin only)
+func (cc *CheckInController) DeskCheckInTeam(c *gin.Context) {
+	tournament, ok := cc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	if !tournament.IsTeamTournament() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "singles_tournament",
+			Message: "This is a singles tournament, check in the player instead",
+		})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid team ID",
+		})
+		return
+	}
+
+	if !cc.checkCheckInOpen(c, tournament) {
+		return
+	}
+
+	registration, err := cc.checkIns.FindTeam(tournament.ID, uint(teamID))
+	if err != nil {
+		respondError(c, err, "Failed to find registration")
+		return
+	}
+	cc.checkInTeam(c, registration)
+}
+
+// CloseCheckIn closes check-in, marks absent entrants as no-shows and
+// optionally fills the freed places from checked-in waitlisted entrants (Admin only)
+func (cc *CheckInController) CloseCheckIn(c *gin.Context) {
+	tournament, ok := cc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	var req views.CloseCheckInRequest
+
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	closure, err := cc.checkIns.Close(tournament, req.PromoteWaitlist)
+	if err != nil {
+		respondError(c, err, "Failed to close check-in")
+		return
+	}
+
+	cc.audit.RecordRequest(c, models.AuditLog{
+		Action:     "tournament.check_in_closed",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+		Details:    fmt.Sprintf("%d no-shows, %d promoted from the waitlist", closure.NoShows, closure.Promoted),
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Check-in closed successfully",
+		Data: views.CheckInClosedResponse{
+			NoShows:  closure.NoShows,
+			Promoted: closure.Promoted,
+		},
+	})
+}
+
+// checkInPlayer records a player's arrival. Waitlisted players keep their
+// status until check-in closes and they are promoted.
+func (cc *CheckInController) checkInPlayer(c *gin.Context, registration *models.TournamentPlayer) {
+	before := *registration
+	if err := cc.checkIns.CheckInPlayer(registration); err != nil {
+		respondError(c, err, "Failed to check in")
+		return
+	}
+
+	cc.audit.RecordChange(c, playerRegistrationAudit("registration.checked_in", registration), before, *registration)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Checked in successfully",
+		Data:    views.RegistrationStatusResponse{RegistrationID: registration.ID, Status: string(registration.Status)},
+	})
+}
+
+// checkInTeam records a team's arrival, see checkInPlayer
+func (cc *CheckInController) checkInTeam(c *gin.Context, registration *models.TournamentTeam) {
+	before := *registration
+	if err := cc.checkIns.CheckInTeam(registration); err != nil {
+		respondError(c, err, "Failed to check in")
+		return
+	}
+
+	cc.audit.RecordChange(c, teamRegistrationAudit("registration.checked_in", registration), before, *registration)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Checked in successfully",
+		Data:    views.RegistrationStatusResponse{RegistrationID: registration.ID, Status: string(registration.Status)},
+	})
+}
+
+// loadTournament fetches the tournament named by the :id param,
+// writing an error response and returning false on failure
+func (cc *CheckInController) loadTournament(c *gin.Context) (*models.Tournament, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid tournament ID",
+		})
+		return nil, false
+	}
+
+	tournament, err := cc.checkIns.Tournament(uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to fetch tournament")
+		return nil, false
+	}
+
+	return tournament, true
+}
+
+// checkCheckInOpen rejects check-in changes outside the registration phase or once check-in is closed
+func (cc *CheckInController) checkCheckInOpen(c *gin.Context, tournament *models.Tournament) bool {
+	if !tournament.IsCheckInAllowed() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "check_in_closed",
+			Message: "Check-in is closed for this tournament",
+		})
+		return false
+	}
+	return true
+}
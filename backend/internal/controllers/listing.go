@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/views"
+)
+
+// parseListing reads the page, sort and filter parameters a list endpoint
+// accepts, writing an error response and returning false when one is invalid
+func parseListing(c *gin.Context, spec listing.Spec) (*listing.Query, bool) {
+	q, err := listing.Parse(c.Request.URL.Query(), spec)
+	if err != nil {
+		var listErr *listing.Error
+		if errors.As(err, &listErr) {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   listErr.Code,
+				Message: listErr.Message,
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_query",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return q, true
+}
@@ -3,26 +3,36 @@ package controllers
 import (
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
+	"badminton-backend/internal/audit"
 	"badminton-backend/internal/models"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/services"
 	"badminton-backend/internal/views"
 )
 
 type MatchController struct {
-	db *gorm.DB
+	matches services.MatchService
+	policy  *policy.Policy
+	audit   *audit.Logger
 }
 
-func NewMatchController(db *gorm.DB) *MatchController {
-	return &MatchController{db: db}
+func NewMatchController(matches services.MatchService, pol *policy.Policy, auditLog *audit.Logger) *MatchController {
+	return &MatchController{matches: matches, policy: pol, audit: auditLog}
 }
 
+// GetMatches lists matches a page at a time, filtered by tournament, status,
+// type, round, player and date range
 func (mc *MatchController) GetMatches(c *gin.Context) {
-	var matches []models.Match
-	if err := mc.db.Preload("Player1").Preload("Player2").Preload("Player3").Preload("Player4").Preload("Tournament").Find(&matches).Error; err != nil {
+	q, ok := parseListing(c, services.MatchListing)
+	if !ok {
+		return
+	}
+
+	matches, page, err := mc.matches.List(q)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "database_error",
 			Message: "Failed to fetch matches",
@@ -30,7 +40,7 @@ func (mc *MatchController) GetMatches(c *gin.Context) {
 		return
 	}
 
-	var matchResponses []views.MatchResponse
+	matchResponses := make([]views.MatchResponse, 0, len(matches))
 	for _, match := range matches {
 		matchResponses = append(matchResponses, views.ToMatchResponse(match))
 	}
@@ -38,12 +48,15 @@ func (mc *MatchController) GetMatches(c *gin.Context) {
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Matches retrieved successfully",
 		Data:    matchResponses,
+		Meta:    views.ToPaginationMeta(page),
 	})
 }
 
 func (mc *MatchController) CreateMatch(c *gin.Context) {
-	var match models.Match
-	if err := c.ShouldBindJSON(&match); err != nil {
+	actor := currentActor(c)
+
+	var req views.MatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_input",
 			Message: err.Error(),
@@ -51,21 +64,32 @@ func (mc *MatchController) CreateMatch(c *gin.Context) {
 		return
 	}
 
-	// Set default match date if not provided
-	if match.MatchDate.IsZero() {
-		match.MatchDate = time.Now()
+	var match models.Match
+	applyMatchRequest(&match, req)
+	match.CreatedByID = &actor.User.ID
+	if !authorize(c, mc.policy.Match(actor, &match, policy.ActionCreate)) {
+		return
 	}
 
-	if err := mc.db.Create(&match).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to create match",
-		})
+	// Players report friendly results after the match for their opponent
+	// to confirm, so a new match starts without one
+	scoreErr := mc.policy.Match(actor, &match, policy.ActionScore)
+	if policy.IsDenied(scoreErr) {
+		match = withResultOf(match, models.Match{Status: models.MatchPending})
+	} else if !authorize(c, scoreErr) {
 		return
 	}
 
-	// Load related data
-	mc.db.Preload("Player1").Preload("Player2").Preload("Player3").Preload("Player4").Preload("Tournament").First(&match, match.ID)
+	if err := mc.matches.Create(&match); err != nil {
+		respondError(c, err, "Failed to create match")
+		return
+	}
+
+	mc.audit.RecordChange(c, models.AuditLog{
+		Action:     "match.created",
+		TargetType: "match",
+		TargetID:   &match.ID,
+	}, nil, match)
 
 	c.JSON(http.StatusCreated, views.SuccessResponse{
 		Message: "Match created successfully",
@@ -74,81 +98,85 @@ func (mc *MatchController) CreateMatch(c *gin.Context) {
 }
 
 func (mc *MatchController) GetMatch(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, views.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid match ID",
-		})
+	id, ok := matchID(c)
+	if !ok {
 		return
 	}
 
-	var match models.Match
-	if err := mc.db.Preload("Player1").Preload("Player2").Preload("Player3").Preload("Player4").Preload("Tournament").First(&match, uint(id)).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, views.ErrorResponse{
-				Error:   "not_found",
-				Message: "Match not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to fetch match",
-		})
+	match, err := mc.matches.GetWithRelations(id)
+	if err != nil {
+		respondError(c, err, "Failed to fetch match")
 		return
 	}
 
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Match retrieved successfully",
-		Data:    views.ToMatchResponse(match),
+		Data:    views.ToMatchResponse(*match),
 	})
 }
 
 func (mc *MatchController) UpdateMatch(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
+	actor := currentActor(c)
+
+	stored, ok := mc.loadMatch(c)
+	if !ok {
+		return
+	}
+
+	match := stored.Clone()
+	original := stored.Clone()
+
+	// Fields left out of the request keep their stored values
+	req := matchRequest(match)
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid match ID",
+			Error:   "invalid_input",
+			Message: err.Error(),
 		})
 		return
 	}
+	applyMatchRequest(&match, req)
 
-	var match models.Match
-	if err := mc.db.First(&match, uint(id)).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, views.ErrorResponse{
-				Error:   "not_found",
-				Message: "Match not found",
-			})
+	// Tournament matches stay in their tournament
+	if original.TournamentID != nil {
+		match.TournamentID = original.TournamentID
+	}
+
+	// Those who may only record results get the result fields applied, and
+	// those who may not record results keep the stored ones. Anyone changing
+	// more must be allowed to create the match as it now stands.
+	updateErr := mc.policy.Match(actor, &original, policy.ActionUpdate)
+	scoreErr := mc.policy.Match(actor, &original, policy.ActionScore)
+	for _, err := range []error{updateErr, scoreErr} {
+		if err != nil && !policy.IsDenied(err) {
+			authorize(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to fetch match",
-		})
-		return
 	}
 
-	if err := c.ShouldBindJSON(&match); err != nil {
-		c.JSON(http.StatusBadRequest, views.ErrorResponse{
-			Error:   "invalid_input",
-			Message: err.Error(),
-		})
+	switch {
+	case updateErr != nil && scoreErr != nil:
+		authorize(c, scoreErr)
+		return
+	case updateErr != nil:
+		match = withResultOf(original, match)
+	case scoreErr != nil:
+		match = withResultOf(match, original)
+	}
+	if updateErr == nil && !authorize(c, mc.policy.Match(actor, &match, policy.ActionCreate)) {
 		return
 	}
 
-	if err := mc.db.Save(&match).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to update match",
-		})
+	if err := mc.matches.Update(&match); err != nil {
+		respondError(c, err, "Failed to update match")
 		return
 	}
 
-	// Load related data
-	mc.db.Preload("Player1").Preload("Player2").Preload("Player3").Preload("Player4").Preload("Tournament").First(&match, match.ID)
+	mc.audit.RecordChange(c, models.AuditLog{
+		Action:     "match.updated",
+		TargetType: "match",
+		TargetID:   &match.ID,
+	}, original, match)
 
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Match updated successfully",
@@ -157,24 +185,111 @@ func (mc *MatchController) UpdateMatch(c *gin.Context) {
 }
 
 func (mc *MatchController) DeleteMatch(c *gin.Context) {
+	match, ok := mc.loadMatch(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, mc.policy.Match(currentActor(c), match, policy.ActionDelete)) {
+		return
+	}
+
+	if err := mc.matches.Delete(match); err != nil {
+		respondError(c, err, "Failed to delete match")
+		return
+	}
+
+	mc.audit.RecordChange(c, models.AuditLog{
+		Action:     "match.deleted",
+		TargetType: "match",
+		TargetID:   &match.ID,
+	}, *match, nil)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Match deleted successfully",
+	})
+}
+
+// loadMatch fetches the match named by the :id param without its relations,
+// writing an error response and returning false on failure
+func (mc *MatchController) loadMatch(c *gin.Context) (*models.Match, bool) {
+	id, ok := matchID(c)
+	if !ok {
+		return nil, false
+	}
+
+	match, err := mc.matches.Get(id)
+	if err != nil {
+		respondError(c, err, "Failed to fetch match")
+		return nil, false
+	}
+
+	return match, true
+}
+
+// matchID parses the :id param, writing an error response and returning
+// false when it is not a valid ID
+func matchID(c *gin.Context) (uint, bool) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid match ID",
 		})
-		return
+		return 0, false
 	}
+	return uint(id), true
+}
 
-	if err := mc.db.Delete(&models.Match{}, uint(id)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to delete match",
-		})
-		return
+// withResultOf returns the match with the result fields taken from another
+// version of it
+func withResultOf(match, from models.Match) models.Match {
+	match.Status = from.Status
+	match.Player1Score = from.Player1Score
+	match.Player2Score = from.Player2Score
+	match.Team1Score = from.Team1Score
+	match.Team2Score = from.Team2Score
+	match.WinnerPlayerID = from.WinnerPlayerID
+	match.WinnerTeamID = from.WinnerTeamID
+	return match
+}
+
+// matchRequest is the request that leaves the match as it is
+func matchRequest(match models.Match) views.MatchRequest {
+	return views.MatchRequest{
+		TournamentID:   match.TournamentID,
+		Type:           match.Type,
+		Status:         match.Status,
+		MatchDate:      match.MatchDate,
+		Round:          match.Round,
+		Player1ID:      match.Player1ID,
+		Player2ID:      match.Player2ID,
+		Player1Score:   match.Player1Score,
+		Player2Score:   match.Player2Score,
+		Team1ID:        match.Team1ID,
+		Team2ID:        match.Team2ID,
+		Team1Score:     match.Team1Score,
+		Team2Score:     match.Team2Score,
+		WinnerPlayerID: match.WinnerPlayerID,
+		WinnerTeamID:   match.WinnerTeamID,
 	}
+}
 
-	c.JSON(http.StatusOK, views.SuccessResponse{
-		Message: "Match deleted successfully",
-	})
+// applyMatchRequest copies the request's fields onto the match
+func applyMatchRequest(match *models.Match, req views.MatchRequest) {
+	match.TournamentID = req.TournamentID
+	match.Type = req.Type
+	match.Status = req.Status
+	match.MatchDate = req.MatchDate
+	match.Round = req.Round
+	match.Player1ID = req.Player1ID
+	match.Player2ID = req.Player2ID
+	match.Player1Score = req.Player1Score
+	match.Player2Score = req.Player2Score
+	match.Team1ID = req.Team1ID
+	match.Team2ID = req.Team2ID
+	match.Team1Score = req.Team1Score
+	match.Team2Score = req.Team2Score
+	match.WinnerPlayerID = req.WinnerPlayerID
+	match.WinnerTeamID = req.WinnerTeamID
 }
@@ -0,0 +1,690 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/notify"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/views"
+)
+
+var (
+	errResultChanged = errors.New("result is no longer open")
+	errMatchClosed   = errors.New("match is already finished")
+)
+
+type MatchResultController struct {
+	db       *gorm.DB
+	policy   *policy.Policy
+	notifier *notify.Notifier
+	audit    *audit.Logger
+}
+
+func NewMatchResultController(db *gorm.DB, pol *policy.Policy, notifier *notify.Notifier, auditLog *audit.Logger) *MatchResultController {
+	return &MatchResultController{db: db, policy: pol, notifier: notifier, audit: auditLog}
+}
+
+// ReportResult lets a participant of a friendly match submit its result
+// for the other side to confirm
+func (rc *MatchResultController) ReportResult(c *gin.Context) {
+	actor := currentActor(c)
+
+	match, ok := rc.loadMatch(c)
+	if !ok {
+		return
+	}
+
+	var req views.ReportResultRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !validResultScores(c, *req.Side1Score, *req.Side2Score) {
+		return
+	}
+
+	if !authorize(c, rc.policy.Match(actor, match, policy.ActionReport)) {
+		return
+	}
+
+	side, err := rc.policy.ParticipantSide(match, actor.User.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check match participants",
+		})
+		return
+	}
+
+	opponents, err := rc.sideUserIDs(match, 3-side)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check match participants",
+		})
+		return
+	}
+	if len(opponents) == 0 {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "opponent_missing",
+			Message: "The match has no opponent to confirm the result",
+		})
+		return
+	}
+
+	var count int64
+	if err := rc.db.Model(&models.MatchResult{}).
+		Where("match_id = ? AND status IN ?", match.ID, []models.MatchResultStatus{models.ResultPending, models.ResultDisputed}).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check match results",
+		})
+		return
+	}
+	if count > 0 {
+		respondResultPending(c)
+		return
+	}
+
+	result := models.MatchResult{
+		MatchID:      match.ID,
+		ReportedByID: actor.User.ID,
+		ReporterSide: side,
+		Side1Score:   *req.Side1Score,
+		Side2Score:   *req.Side2Score,
+		Status:       models.ResultPending,
+	}
+	if err := rc.db.Create(&result).Error; err != nil {
+		// A concurrent report got in first
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondResultPending(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to report result",
+		})
+		return
+	}
+
+	rc.notifyUsers(opponents, match, models.Notification{
+		Type:    "result_reported",
+		Title:   "Confirm match result",
+		Message: fmt.Sprintf("%s reported a result of %d-%d. Confirm or dispute it.", actor.User.FullName, result.Side1Score, result.Side2Score),
+	})
+
+	c.JSON(http.StatusCreated, views.SuccessResponse{
+		Message: "Result reported. It counts once your opponent confirms it",
+		Data:    views.ToMatchResultResponse(result),
+	})
+}
+
+// GetMatchResults lists the results reported for a match, newest first
+func (rc *MatchResultController) GetMatchResults(c *gin.Context) {
+	match, ok := rc.loadMatch(c)
+	if !ok {
+		return
+	}
+
+	var results []models.MatchResult
+	if err := rc.db.Where("match_id = ?", match.ID).Order("created_at DESC").Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch match results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Match results retrieved successfully",
+		Data:    toMatchResultResponses(results),
+	})
+}
+
+// GetPendingResults lists results waiting for the current user to confirm
+// or dispute
+func (rc *MatchResultController) GetPendingResults(c *gin.Context) {
+	actor := currentActor(c)
+
+	// Only the side that did not report may respond, as in
+	// policy.RespondToResult
+	userTeams := rc.db.Model(&models.TeamPlayer{}).Select("team_id").Where("player_id = ?", actor.User.ID)
+	var results []models.MatchResult
+	if err := rc.db.Joins("JOIN matches ON matches.id = match_results.match_id AND matches.deleted_at IS NULL").
+		Where("match_results.status = ?", models.ResultPending).
+		Where(rc.db.
+			Where("match_results.reporter_side = 1 AND (matches.player2_id = ? OR matches.team2_id IN (?))", actor.User.ID, userTeams).
+			Or("match_results.reporter_side = 2 AND (matches.player1_id = ? OR matches.team1_id IN (?))", actor.User.ID, userTeams)).
+		Order("match_results.created_at").
+		Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch pending results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Pending results retrieved successfully",
+		Data:    toMatchResultResponses(results),
+	})
+}
+
+// ConfirmResult lets the opponent accept a reported result, which is then
+// recorded on the match
+func (rc *MatchResultController) ConfirmResult(c *gin.Context) {
+	actor := currentActor(c)
+
+	result, match, ok := rc.loadResult(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, rc.policy.RespondToResult(actor, match, result)) {
+		return
+	}
+
+	now := time.Now()
+	err := rc.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeResult(tx, result, models.ResultPending, map[string]interface{}{
+			"status":          models.ResultConfirmed,
+			"responded_by_id": actor.User.ID,
+			"responded_at":    now,
+		}); err != nil {
+			return err
+		}
+		return applyResult(tx, match, result.Side1Score, result.Side2Score)
+	})
+	if !rc.checkResultSaved(c, err) {
+		return
+	}
+
+	rc.notifyUsers([]uint{result.ReportedByID}, match, models.Notification{
+		Type:    "result_confirmed",
+		Title:   "Match result confirmed",
+		Message: fmt.Sprintf("Your reported result of %d-%d was confirmed.", result.Side1Score, result.Side2Score),
+	})
+
+	result.Status = models.ResultConfirmed
+	result.RespondedByID = &actor.User.ID
+	result.RespondedAt = &now
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Result confirmed",
+		Data:    views.ToMatchResultResponse(*result),
+	})
+}
+
+// DisputeResult lets the opponent reject a reported result and send it to
+// the admin queue
+func (rc *MatchResultController) DisputeResult(c *gin.Context) {
+	actor := currentActor(c)
+
+	result, match, ok := rc.loadResult(c)
+	if !ok {
+		return
+	}
+
+	var req views.DisputeResultRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !authorize(c, rc.policy.RespondToResult(actor, match, result)) {
+		return
+	}
+
+	now := time.Now()
+	err := closeResult(rc.db, result, models.ResultPending, map[string]interface{}{
+		"status":          models.ResultDisputed,
+		"responded_by_id": actor.User.ID,
+		"responded_at":    now,
+		"dispute_reason":  req.Reason,
+	})
+	if !rc.checkResultSaved(c, err) {
+		return
+	}
+
+	rc.notifyUsers([]uint{result.ReportedByID}, match, models.Notification{
+		Type:    "result_disputed",
+		Title:   "Match result disputed",
+		Message: fmt.Sprintf("Your reported result of %d-%d was disputed and will be reviewed by an admin.", result.Side1Score, result.Side2Score),
+	})
+
+	result.Status = models.ResultDisputed
+	result.RespondedByID = &actor.User.ID
+	result.RespondedAt = &now
+	result.DisputeReason = req.Reason
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Result disputed. An admin will review it",
+		Data:    views.ToMatchResultResponse(*result),
+	})
+}
+
+// GetDisputedResults lists disputed results for admins to settle, oldest first
+func (rc *MatchResultController) GetDisputedResults(c *gin.Context) {
+	var results []models.MatchResult
+	if err := rc.db.Where("status = ?", models.ResultDisputed).Order("responded_at").Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch disputed results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Disputed results retrieved successfully",
+		Data:    toMatchResultResponses(results),
+	})
+}
+
+// ResolveResult settles a disputed result. An accepted result, optionally
+// with corrected scores, is recorded on the match; a rejected one is
+// discarded so the players can report again.
+func (rc *MatchResultController) ResolveResult(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	result, match, ok := rc.loadResult(c)
+	if !ok {
+		return
+	}
+
+	var req views.ResolveResultRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if result.Status != models.ResultDisputed {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "result_not_disputed",
+			Message: "Only disputed results can be resolved",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"resolved_by_id":  userObj.ID,
+		"resolved_at":     time.Now(),
+		"resolution_note": req.Note,
+	}
+
+	var err error
+	if *req.Accept {
+		if req.Side1Score != nil {
+			result.Side1Score = *req.Side1Score
+		}
+		if req.Side2Score != nil {
+			result.Side2Score = *req.Side2Score
+		}
+		if !validResultScores(c, result.Side1Score, result.Side2Score) {
+			return
+		}
+
+		updates["status"] = models.ResultConfirmed
+		updates["side1_score"] = result.Side1Score
+		updates["side2_score"] = result.Side2Score
+		err = rc.db.Transaction(func(tx *gorm.DB) error {
+			if err := closeResult(tx, result, models.ResultDisputed, updates); err != nil {
+				return err
+			}
+			return applyResult(tx, match, result.Side1Score, result.Side2Score)
+		})
+	} else {
+		updates["status"] = models.ResultRejected
+		err = closeResult(rc.db, result, models.ResultDisputed, updates)
+	}
+	if !rc.checkResultSaved(c, err) {
+		return
+	}
+
+	status := "accepted"
+	if !*req.Accept {
+		status = "rejected"
+	}
+
+	rc.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "match_result." + status,
+		TargetType: "match",
+		TargetID:   &match.ID,
+		Details:    fmt.Sprintf("result %d: %d-%d", result.ID, result.Side1Score, result.Side2Score),
+	})
+
+	for _, side := range []int{1, 2} {
+		rc.notifySide(match, side, models.Notification{
+			Type:    "result_resolved",
+			Title:   "Disputed result " + status,
+			Message: fmt.Sprintf("An admin %s the disputed result of %d-%d.", status, result.Side1Score, result.Side2Score),
+		})
+	}
+
+	rc.db.First(result, result.ID)
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Disputed result " + status,
+		Data:    views.ToMatchResultResponse(*result),
+	})
+}
+
+// GetStandings ranks players (or doubles teams with type=doubles) by the
+// completed matches they won. Friendly matches only complete once their
+// result is confirmed, so unconfirmed results never count.
+func (rc *MatchResultController) GetStandings(c *gin.Context) {
+	matchType := models.MatchSingles
+	if c.Query("type") == string(models.MatchDoubles) {
+		matchType = models.MatchDoubles
+	}
+
+	query := rc.db.Where("status = ? AND type = ?", models.MatchCompleted, matchType)
+	if tournamentID := c.Query("tournament_id"); tournamentID != "" {
+		query = query.Where("tournament_id = ?", tournamentID)
+	}
+
+	var matches []models.Match
+	if err := query.Find(&matches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch matches",
+		})
+		return
+	}
+
+	standings := make(map[uint]*views.StandingResponse)
+	record := func(id *uint, pointsFor, pointsAgainst int, won bool) {
+		if id == nil {
+			return
+		}
+		standing, ok := standings[*id]
+		if !ok {
+			standing = &views.StandingResponse{ID: *id}
+			standings[*id] = standing
+		}
+		standing.Played++
+		standing.PointsFor += pointsFor
+		standing.PointsAgainst += pointsAgainst
+		if won {
+			standing.Won++
+		} else {
+			standing.Lost++
+		}
+	}
+
+	for _, match := range matches {
+		// A bye is not a game played, and a match completed without a
+		// winner has no result to count
+		side := match.WinnerSide()
+		if side == 0 || match.IsWalkover() {
+			continue
+		}
+		if matchType == models.MatchDoubles {
+			record(match.Team1ID, match.Team1Score, match.Team2Score, side == 1)
+			record(match.Team2ID, match.Team2Score, match.Team1Score, side == 2)
+		} else {
+			record(match.Player1ID, match.Player1Score, match.Player2Score, side == 1)
+			record(match.Player2ID, match.Player2Score, match.Player1Score, side == 2)
+		}
+	}
+
+	ids := make([]uint, 0, len(standings))
+	for id := range standings {
+		ids = append(ids, id)
+	}
+	if matchType == models.MatchDoubles {
+		var teams []models.Team
+		if err := rc.db.Where("id IN ?", ids).Find(&teams).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to fetch teams",
+			})
+			return
+		}
+		for _, team := range teams {
+			standings[team.ID].Name = team.Name
+		}
+	} else {
+		var users []models.User
+		if err := rc.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+				Error:   "database_error",
+				Message: "Failed to fetch players",
+			})
+			return
+		}
+		for _, user := range users {
+			standings[user.ID].Name = user.FullName
+		}
+	}
+
+	table := make([]views.StandingResponse, 0, len(standings))
+	for _, standing := range standings {
+		table = append(table, *standing)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Won != b.Won {
+			return a.Won > b.Won
+		}
+		if diffA, diffB := a.PointsFor-a.PointsAgainst, b.PointsFor-b.PointsAgainst; diffA != diffB {
+			return diffA > diffB
+		}
+		return a.ID < b.ID
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Standings retrieved successfully",
+		Data:    table,
+	})
+}
+
+// respondResultPending rejects a report while another result for the match
+// is still open
+func respondResultPending(c *gin.Context) {
+	c.JSON(http.StatusConflict, views.ErrorResponse{
+		Error:   "result_pending",
+		Message: "A result for this match is already waiting for confirmation",
+	})
+}
+
+// closeResult moves an open result out of the expected status, failing
+// with errResultChanged if another request got there first
+func closeResult(tx *gorm.DB, result *models.MatchResult, from models.MatchResultStatus, updates map[string]interface{}) error {
+	res := tx.Model(&models.MatchResult{}).Where("id = ? AND status = ?", result.ID, from).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errResultChanged
+	}
+	return nil
+}
+
+// applyResult records a confirmed result on the match
+func applyResult(tx *gorm.DB, match *models.Match, side1Score, side2Score int) error {
+	if err := tx.First(match, match.ID).Error; err != nil {
+		return err
+	}
+	if match.Status == models.MatchCompleted || match.Status == models.MatchCancelled {
+		return errMatchClosed
+	}
+
+	match.ApplyResult(side1Score, side2Score)
+	return tx.Omit(clause.Associations).Save(match).Error
+}
+
+// validResultScores rejects negative and tied scores, writing an error
+// response and returning false
+func validResultScores(c *gin.Context, side1Score, side2Score int) bool {
+	if side1Score < 0 || side2Score < 0 || side1Score == side2Score {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_score",
+			Message: "Scores must be non-negative and a match cannot end in a tie",
+		})
+		return false
+	}
+	return true
+}
+
+// checkResultSaved writes the error response for a failed result update,
+// returning true if there was no error
+func (rc *MatchResultController) checkResultSaved(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errResultChanged):
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "result_changed",
+			Message: "This result was already handled",
+		})
+	case errors.Is(err, errMatchClosed):
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "match_closed",
+			Message: "The match already has a result",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update result",
+		})
+	}
+	return false
+}
+
+// notifySide notifies everyone playing on one side of the match
+func (rc *MatchResultController) notifySide(match *models.Match, side int, notification models.Notification) {
+	userIDs, err := rc.sideUserIDs(match, side)
+	if err != nil {
+		log.Printf("Failed to find players of match %d: %v", match.ID, err)
+		return
+	}
+	rc.notifyUsers(userIDs, match, notification)
+}
+
+func (rc *MatchResultController) notifyUsers(userIDs []uint, match *models.Match, notification models.Notification) {
+	notification.MatchID = &match.ID
+	if err := rc.notifier.Notify(userIDs, notification); err != nil {
+		log.Printf("Failed to notify players of match %d: %v", match.ID, err)
+	}
+}
+
+// sideUserIDs returns the users playing on side 1 or 2 of the match
+func (rc *MatchResultController) sideUserIDs(match *models.Match, side int) ([]uint, error) {
+	if match.IsTeamMatch() {
+		teamID := match.Team1ID
+		if side == 2 {
+			teamID = match.Team2ID
+		}
+		if teamID == nil {
+			return nil, nil
+		}
+		var userIDs []uint
+		err := rc.db.Model(&models.TeamPlayer{}).Where("team_id = ?", *teamID).Pluck("player_id", &userIDs).Error
+		return userIDs, err
+	}
+
+	playerID := match.Player1ID
+	if side == 2 {
+		playerID = match.Player2ID
+	}
+	if playerID == nil {
+		return nil, nil
+	}
+	return []uint{*playerID}, nil
+}
+
+// loadMatch fetches the match named by the :id param,
+// writing an error response and returning false on failure
+func (rc *MatchResultController) loadMatch(c *gin.Context) (*models.Match, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid match ID",
+		})
+		return nil, false
+	}
+
+	var match models.Match
+	if err := rc.db.First(&match, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Match not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch match",
+		})
+		return nil, false
+	}
+
+	return &match, true
+}
+
+// loadResult fetches the result named by the :id param together with its
+// match, writing an error response and returning false on failure
+func (rc *MatchResultController) loadResult(c *gin.Context) (*models.MatchResult, *models.Match, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid result ID",
+		})
+		return nil, nil, false
+	}
+
+	var result models.MatchResult
+	if err := rc.db.Preload("Match").First(&result, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Result not found",
+			})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch result",
+		})
+		return nil, nil, false
+	}
+
+	match := result.Match
+	return &result, &match, true
+}
+
+func toMatchResultResponses(results []models.MatchResult) []views.MatchResultResponse {
+	responses := make([]views.MatchResultResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, views.ToMatchResultResponse(result))
+	}
+	return responses
+}
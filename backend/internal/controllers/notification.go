@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+type NotificationController struct {
+	db *gorm.DB
+}
+
+func NewNotificationController(db *gorm.DB) *NotificationController {
+	return &NotificationController{db: db}
+}
+
+// GetNotifications returns the current user's notifications, newest first
+func (nc *NotificationController) GetNotifications(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	query := nc.db.Where("user_id = ?", userObj.ID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch notifications",
+		})
+		return
+	}
+
+	notificationResponses := make([]views.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		notificationResponses = append(notificationResponses, views.ToNotificationResponse(notification))
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Notifications retrieved successfully",
+		Data:    notificationResponses,
+	})
+}
+
+// MarkNotificationRead marks one of the current user's notifications as read
+func (nc *NotificationController) MarkNotificationRead(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid notification ID",
+		})
+		return
+	}
+
+	result := nc.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", uint(id), userObj.ID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update notification",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Notification marked as read",
+	})
+}
+
+// MarkAllNotificationsRead marks all of the current user's notifications as read
+func (nc *NotificationController) MarkAllNotificationsRead(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	if err := nc.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userObj.ID).
+		Update("read_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update notifications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "All notifications marked as read",
+	})
+}
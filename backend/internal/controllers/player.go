@@ -7,16 +7,35 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"badminton-backend/internal/listing"
 	"badminton-backend/internal/models"
+	"badminton-backend/internal/policy"
 	"badminton-backend/internal/views"
 )
 
 type PlayerController struct {
-	db *gorm.DB
+	db     *gorm.DB
+	policy *policy.Policy
 }
 
-func NewPlayerController(db *gorm.DB) *PlayerController {
-	return &PlayerController{db: db}
+func NewPlayerController(db *gorm.DB, pol *policy.Policy) *PlayerController {
+	return &PlayerController{db: db, policy: pol}
+}
+
+// PlayerListing is what the player list accepts
+var PlayerListing = listing.Spec{
+	Filters: map[string]listing.Filter{
+		"search":      listing.Search("full_name", "username"),
+		"min_ranking": listing.AtLeast("ranking"),
+		"max_ranking": listing.AtMost("ranking"),
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"name":       "full_name",
+		"ranking":    "ranking",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id",
 }
 
 // GetPlayers now returns User objects with role="player"
@@ -25,9 +44,15 @@ func NewPlayerController(db *gorm.DB) *PlayerController {
 // but uses the new User system under the hood
 
 func (pc *PlayerController) GetPlayers(c *gin.Context) {
+	q, ok := parseListing(c, PlayerListing)
+	if !ok {
+		return
+	}
+
 	var users []models.User
 	// Only get users with player role
-	if err := pc.db.Where("role = ?", "player").Find(&users).Error; err != nil {
+	page, err := q.Find(pc.db.Where("role = ?", "player"), &users)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "database_error",
 			Message: "Failed to fetch players",
@@ -35,7 +60,7 @@ func (pc *PlayerController) GetPlayers(c *gin.Context) {
 		return
 	}
 
-	var playerResponses []views.PlayerResponse
+	playerResponses := make([]views.PlayerResponse, 0, len(users))
 	for _, user := range users {
 		// Convert User to PlayerResponse for backward compatibility
 		playerResponse := views.PlayerResponse{
@@ -50,19 +75,18 @@ func (pc *PlayerController) GetPlayers(c *gin.Context) {
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Players retrieved successfully",
 		Data:    playerResponses,
+		Meta:    views.ToPaginationMeta(page),
 	})
 }
 
 // CreatePlayer creates a new User with player role (legacy endpoint)
 func (pc *PlayerController) CreatePlayer(c *gin.Context) {
-	var req struct {
-		Name     string `json:"name" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required,min=6"`
-		Ranking  int    `json:"ranking"`
+	if !authorize(c, pc.policy.Player(currentActor(c), nil, policy.ActionCreate)) {
+		return
 	}
 
+	var req views.CreatePlayerRequest
+
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_input",
@@ -179,12 +203,13 @@ func (pc *PlayerController) UpdatePlayer(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Name    string `json:"name"`
-		Email   string `json:"email"`
-		Ranking int    `json:"ranking"`
+	actor := currentActor(c)
+	if !authorize(c, pc.policy.Player(actor, &user, policy.ActionUpdate)) {
+		return
 	}
 
+	var req views.UpdatePlayerRequest
+
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_input",
@@ -193,6 +218,12 @@ func (pc *PlayerController) UpdatePlayer(c *gin.Context) {
 		return
 	}
 
+	emailChanged := req.Email != "" && req.Email != user.Email
+	rankingChanged := req.Ranking != nil && *req.Ranking != user.Ranking
+	if (emailChanged || rankingChanged) && !authorize(c, pc.policy.PlayerAccountFields(actor)) {
+		return
+	}
+
 	// Update user fields
 	if req.Name != "" {
 		user.FullName = req.Name
@@ -200,7 +231,9 @@ func (pc *PlayerController) UpdatePlayer(c *gin.Context) {
 	if req.Email != "" {
 		user.Email = req.Email
 	}
-	user.Ranking = req.Ranking
+	if req.Ranking != nil {
+		user.Ranking = *req.Ranking
+	}
 
 	if err := pc.db.Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
@@ -234,6 +267,10 @@ func (pc *PlayerController) DeletePlayer(c *gin.Context) {
 		return
 	}
 
+	if !authorize(c, pc.policy.Player(currentActor(c), nil, policy.ActionDelete)) {
+		return
+	}
+
 	// Only allow deletion of users with player role
 	result := pc.db.Where("role = ? AND id = ?", "player", uint(id)).Delete(&models.User{})
 	if result.Error != nil {
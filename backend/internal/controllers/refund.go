@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+type RefundController struct {
+	db    *gorm.DB
+	audit *audit.Logger
+}
+
+func NewRefundController(db *gorm.DB, auditLog *audit.Logger) *RefundController {
+	return &RefundController{db: db, audit: auditLog}
+}
+
+// GetRefunds lists refunds, optionally filtered by status and tournament (Admin only)
+func (rc *RefundController) GetRefunds(c *gin.Context) {
+	query := rc.db.Preload("User").Preload("Tournament")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if tournamentID := c.Query("tournament_id"); tournamentID != "" {
+		query = query.Where("tournament_id = ?", tournamentID)
+	}
+
+	var refunds []models.Refund
+	if err := query.Order("created_at").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch refunds",
+		})
+		return
+	}
+
+	refundResponses := make([]views.RefundResponse, len(refunds))
+	for i, refund := range refunds {
+		refundResponses[i] = views.ToRefundResponse(refund)
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Refunds retrieved successfully",
+		Data:    refundResponses,
+	})
+}
+
+// GetMyRefunds lists the current user's refunds
+func (rc *RefundController) GetMyRefunds(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var refunds []models.Refund
+	if err := rc.db.Preload("User").Preload("Tournament").Where("user_id = ?", userObj.ID).Order("created_at").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch refunds",
+		})
+		return
+	}
+
+	refundResponses := make([]views.RefundResponse, len(refunds))
+	for i, refund := range refunds {
+		refundResponses[i] = views.ToRefundResponse(refund)
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Refunds retrieved successfully",
+		Data:    refundResponses,
+	})
+}
+
+// ProcessRefund records that a pending refund has been paid out (Admin only)
+func (rc *RefundController) ProcessRefund(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid refund ID",
+		})
+		return
+	}
+
+	var refund models.Refund
+	if err := rc.db.Preload("User").Preload("Tournament").First(&refund, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Refund not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch refund",
+		})
+		return
+	}
+
+	// Only a pending refund is processed, so two admins processing it at
+	// once cannot both succeed
+	before := refund
+	now := time.Now()
+	result := rc.db.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, models.RefundPending).
+		Updates(map[string]interface{}{
+			"status":          models.RefundProcessed,
+			"processed_at":    now,
+			"processed_by_id": userObj.ID,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update refund",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "refund_processed",
+			Message: "Refund has already been processed",
+		})
+		return
+	}
+	refund.Status = models.RefundProcessed
+	refund.ProcessedAt = &now
+	refund.ProcessedByID = &userObj.ID
+
+	rc.audit.RecordChange(c, models.AuditLog{
+		Action:     "refund.processed",
+		TargetType: "refund",
+		TargetID:   &refund.ID,
+		Details:    fmt.Sprintf("%.0f to user %d for tournament %d", refund.Amount, refund.UserID, refund.TournamentID),
+	}, before, refund)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Refund processed successfully",
+		Data:    views.ToRefundResponse(refund),
+	})
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/search"
+	"badminton-backend/internal/views"
+)
+
+type SearchController struct {
+	index search.Index
+}
+
+func NewSearchController(index search.Index) *SearchController {
+	return &SearchController{index: index}
+}
+
+// Search finds players, teams and tournaments by name, best matches first
+func (sc *SearchController) Search(c *gin.Context) {
+	query := c.Query("q")
+	if len(search.Terms(query)) == 0 {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "query_required",
+			Message: "Search query must contain at least one letter or digit",
+		})
+		return
+	}
+
+	limit := listing.DefaultLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > listing.MaxLimit {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_limit",
+				Message: "Limit must be between 1 and " + strconv.Itoa(listing.MaxLimit),
+			})
+			return
+		}
+		limit = n
+	}
+
+	results, err := sc.index.Search(query, limit)
+	if err != nil {
+		if errors.Is(err, search.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, views.ErrorResponse{
+				Error:   "search_unavailable",
+				Message: "Search is not available on this server",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to search",
+		})
+		return
+	}
+
+	response := make([]views.SearchResultResponse, 0, len(results))
+	for _, result := range results {
+		response = append(response, views.ToSearchResultResponse(result))
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Search completed successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/services"
+	"badminton-backend/internal/views"
+)
+
+// serviceStatus maps a rule violation kind to its HTTP status
+var serviceStatus = map[services.Kind]int{
+	services.KindInvalid:   http.StatusBadRequest,
+	services.KindForbidden: http.StatusForbidden,
+	services.KindNotFound:  http.StatusNotFound,
+	services.KindConflict:  http.StatusConflict,
+}
+
+// respondError writes the response for an error returned by a service:
+// the violated rule for a services.Error, otherwise a database error with
+// the given message
+func respondError(c *gin.Context, err error, message string) {
+	var ruleErr *services.Error
+	if errors.As(err, &ruleErr) {
+		c.JSON(serviceStatus[ruleErr.Kind], views.ErrorResponse{
+			Error:   ruleErr.Code,
+			Message: ruleErr.Message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+		Error:   "database_error",
+		Message: message,
+	})
+}
@@ -0,0 +1,593 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+var (
+	errAlreadyMember = errors.New("player is already on the team")
+	errTeamFull      = errors.New("team is full")
+)
+
+type TeamController struct {
+	db *gorm.DB
+}
+
+func NewTeamController(db *gorm.DB) *TeamController {
+	return &TeamController{db: db}
+}
+
+// CreateTeam creates a new team for doubles tournaments
+func (tc *TeamController) CreateTeam(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var req views.CreateTeamRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.PartnerID == userObj.ID {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_partner",
+			Message: "You cannot be your own partner",
+		})
+		return
+	}
+
+	// Check if partner exists and is a player
+	var partner models.User
+	if err := tc.db.First(&partner, req.PartnerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "partner_not_found",
+			Message: "Partner not found",
+		})
+		return
+	}
+
+	if !partner.IsPlayer() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "partner_not_player",
+			Message: "Partner must be a player",
+		})
+		return
+	}
+
+	team := models.Team{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	// The pair check and the inserts run in one transaction holding both
+	// players' rows, so the same pair cannot create two teams at once and
+	// a failure leaves no team without members
+	var existingTeamID uint
+	err := tc.db.Transaction(func(tx *gorm.DB) error {
+		var players []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{userObj.ID, partner.ID}).
+			Order("id").Find(&players).Error; err != nil {
+			return err
+		}
+
+		// Prevent the same pair from creating several teams
+		var err error
+		existingTeamID, err = findTeamWithPair(tx, userObj.ID, partner.ID)
+		if err != nil || existingTeamID != 0 {
+			return err
+		}
+
+		if err := tx.Create(&team).Error; err != nil {
+			return err
+		}
+
+		teamPlayers := []models.TeamPlayer{
+			{
+				TeamID:   team.ID,
+				PlayerID: userObj.ID,
+				Role:     models.TeamRoleCaptain,
+			},
+			{
+				TeamID:   team.ID,
+				PlayerID: partner.ID,
+				Role:     models.TeamRolePlayer,
+			},
+		}
+		return tx.Create(&teamPlayers).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to create team",
+		})
+		return
+	}
+	if existingTeamID != 0 {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "duplicate_pair",
+			Message: "You already have a team with this partner (team " + strconv.FormatUint(uint64(existingTeamID), 10) + ")",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, views.SuccessResponse{
+		Message: "Team created successfully",
+		Data:    views.CreatedTeamResponse{TeamID: team.ID, TeamName: team.Name},
+	})
+}
+
+// GetMyTeams returns the teams the current user plays in
+func (tc *TeamController) GetMyTeams(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var teams []models.Team
+	err := tc.db.Preload("Players.Player").
+		Where("id IN (?)", tc.db.Model(&models.TeamPlayer{}).Select("team_id").Where("player_id = ?", userObj.ID)).
+		Find(&teams).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch teams",
+		})
+		return
+	}
+
+	teamResponses := make([]views.TeamResponse, len(teams))
+	for i, team := range teams {
+		teamResponses[i] = views.ToTeamResponse(team)
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Teams retrieved successfully",
+		Data:    teamResponses,
+	})
+}
+
+// GetTeam returns a team with its roster
+func (tc *TeamController) GetTeam(c *gin.Context) {
+	team, ok := tc.loadTeam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Team retrieved successfully",
+		Data:    views.ToTeamResponse(*team),
+	})
+}
+
+// UpdateTeam renames a team or changes its description (captain only)
+func (tc *TeamController) UpdateTeam(c *gin.Context) {
+	team, ok := tc.loadTeamAsCaptain(c)
+	if !ok {
+		return
+	}
+
+	var req views.UpdateTeamRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.Name != "" {
+		team.Name = req.Name
+	}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+
+	if err := tc.db.Model(team).Updates(map[string]interface{}{
+		"name":        team.Name,
+		"description": team.Description,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Team updated successfully",
+		Data:    views.ToTeamResponse(*team),
+	})
+}
+
+// AddTeamMember adds a player to a team that has a free slot (captain only)
+func (tc *TeamController) AddTeamMember(c *gin.Context) {
+	team, ok := tc.loadTeamAsCaptain(c)
+	if !ok {
+		return
+	}
+
+	var req views.TeamPlayerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !tc.checkRosterUnlocked(c, team.ID) {
+		return
+	}
+
+	var player models.User
+	if err := tc.db.First(&player, req.PlayerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "player_not_found",
+			Message: "Player not found",
+		})
+		return
+	}
+
+	if !player.IsPlayer() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "not_player",
+			Message: "Team members must be players",
+		})
+		return
+	}
+
+	// The roster checks and the insert run in one transaction holding the
+	// rows of the members and the new player, like CreateTeam, so
+	// concurrent adds cannot overfill the team or form the same pair twice
+	var existingTeamID uint
+	err := tc.db.Transaction(func(tx *gorm.DB) error {
+		playerIDs := []uint{player.ID}
+		for _, tp := range team.Players {
+			playerIDs = append(playerIDs, tp.PlayerID)
+		}
+		var players []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", playerIDs).
+			Order("id").Find(&players).Error; err != nil {
+			return err
+		}
+
+		// The roster as it is now that no other add can change it
+		var members []models.TeamPlayer
+		if err := tx.Where("team_id = ?", team.ID).Find(&members).Error; err != nil {
+			return err
+		}
+		for _, tp := range members {
+			if tp.PlayerID == player.ID {
+				return errAlreadyMember
+			}
+		}
+		if len(members) >= models.MaxTeamSize {
+			return errTeamFull
+		}
+
+		// The new roster must not duplicate another team's pair
+		for _, tp := range members {
+			var err error
+			existingTeamID, err = findTeamWithPair(tx, tp.PlayerID, player.ID)
+			if err != nil || existingTeamID != 0 {
+				return err
+			}
+		}
+
+		return tx.Create(&models.TeamPlayer{
+			TeamID:   team.ID,
+			PlayerID: player.ID,
+			Role:     models.TeamRolePlayer,
+		}).Error
+	})
+	switch {
+	case err == errAlreadyMember:
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "already_member",
+			Message: "Player is already on this team",
+		})
+		return
+	case err == errTeamFull:
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "team_full",
+			Message: "Team already has the maximum number of players",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to add team member",
+		})
+		return
+	case existingTeamID != 0:
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "duplicate_pair",
+			Message: "These players already form team " + strconv.FormatUint(uint64(existingTeamID), 10),
+		})
+		return
+	}
+
+	tc.respondWithTeam(c, team.ID, "Team member added successfully")
+}
+
+// RemoveTeamMember removes a player from a team. The captain can remove
+// anyone but themselves; other members can only remove themselves.
+func (tc *TeamController) RemoveTeamMember(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	team, ok := tc.loadTeam(c)
+	if !ok {
+		return
+	}
+
+	playerID, err := strconv.ParseUint(c.Param("player_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid player ID",
+		})
+		return
+	}
+
+	isCaptain := team.CaptainID() == userObj.ID
+	if !isCaptain && uint(playerID) != userObj.ID {
+		c.JSON(http.StatusForbidden, views.ErrorResponse{
+			Error:   "captain_required",
+			Message: "Only the team captain can remove other members",
+		})
+		return
+	}
+
+	if !team.HasMember(uint(playerID)) {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "member_not_found",
+			Message: "Player is not on this team",
+		})
+		return
+	}
+
+	if uint(playerID) == team.CaptainID() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "captain_cannot_leave",
+			Message: "Transfer the captaincy or dissolve the team instead",
+		})
+		return
+	}
+
+	if !tc.checkRosterUnlocked(c, team.ID) {
+		return
+	}
+
+	if err := tc.db.Where("team_id = ? AND player_id = ?", team.ID, playerID).Delete(&models.TeamPlayer{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to remove team member",
+		})
+		return
+	}
+
+	tc.respondWithTeam(c, team.ID, "Team member removed successfully")
+}
+
+// TransferCaptain hands the captaincy to another team member (captain only)
+func (tc *TeamController) TransferCaptain(c *gin.Context) {
+	team, ok := tc.loadTeamAsCaptain(c)
+	if !ok {
+		return
+	}
+
+	var req views.TeamPlayerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !team.HasMember(req.PlayerID) {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "member_not_found",
+			Message: "New captain must be a member of the team",
+		})
+		return
+	}
+
+	if req.PlayerID == team.CaptainID() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "already_captain",
+			Message: "Player is already the captain",
+		})
+		return
+	}
+
+	err := tc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TeamPlayer{}).
+			Where("team_id = ? AND player_id = ?", team.ID, team.CaptainID()).
+			Update("role", models.TeamRolePlayer).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TeamPlayer{}).
+			Where("team_id = ? AND player_id = ?", team.ID, req.PlayerID).
+			Update("role", models.TeamRoleCaptain).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to transfer captaincy",
+		})
+		return
+	}
+
+	tc.respondWithTeam(c, team.ID, "Captaincy transferred successfully")
+}
+
+// DeleteTeam dissolves a team and withdraws it from upcoming tournaments (captain only)
+func (tc *TeamController) DeleteTeam(c *gin.Context) {
+	team, ok := tc.loadTeamAsCaptain(c)
+	if !ok {
+		return
+	}
+
+	if !tc.checkRosterUnlocked(c, team.ID) {
+		return
+	}
+
+	err := tc.db.Transaction(func(tx *gorm.DB) error {
+		// Finished tournaments keep the team's entry as it was
+		unfinished := tx.Model(&models.Tournament{}).Select("id").
+			Where("status NOT IN ?", []models.TournamentStatus{models.TournamentCompleted, models.TournamentCancelled})
+		if err := tx.Model(&models.TournamentTeam{}).
+			Where("team_id = ? AND status NOT IN ? AND tournament_id IN (?)", team.ID,
+				[]models.RegistrationStatus{models.RegistrationWithdrawn, models.RegistrationCancelled}, unfinished).
+			Update("status", models.RegistrationWithdrawn).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamPlayer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(team).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to dissolve team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Team dissolved successfully",
+	})
+}
+
+// loadTeam fetches the team named by the :id param with its roster,
+// writing an error response and returning false on failure
+func (tc *TeamController) loadTeam(c *gin.Context) (*models.Team, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid team ID",
+		})
+		return nil, false
+	}
+
+	var team models.Team
+	if err := tc.db.Preload("Players.Player").First(&team, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Team not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch team",
+		})
+		return nil, false
+	}
+
+	return &team, true
+}
+
+// loadTeamAsCaptain is loadTeam restricted to the team captain
+func (tc *TeamController) loadTeamAsCaptain(c *gin.Context) (*models.Team, bool) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	team, ok := tc.loadTeam(c)
+	if !ok {
+		return nil, false
+	}
+
+	if team.CaptainID() != userObj.ID {
+		c.JSON(http.StatusForbidden, views.ErrorResponse{
+			Error:   "captain_required",
+			Message: "Only the team captain can manage this team",
+		})
+		return nil, false
+	}
+
+	return team, true
+}
+
+// checkRosterUnlocked rejects roster changes once the team is committed to
+// a tournament: checked in, placed in the draw or playing
+func (tc *TeamController) checkRosterUnlocked(c *gin.Context, teamID uint) bool {
+	var count int64
+	err := tc.db.Model(&models.TournamentTeam{}).
+		Joins("JOIN tournaments ON tournaments.id = tournament_teams.tournament_id AND tournaments.deleted_at IS NULL").
+		Where("tournament_teams.team_id = ? AND tournament_teams.status NOT IN ?", teamID,
+			[]models.RegistrationStatus{models.RegistrationWithdrawn, models.RegistrationCancelled, models.RegistrationNoShow}).
+		Where(tc.db.
+			Where("tournaments.status IN ?", []models.TournamentStatus{models.TournamentDrawn, models.TournamentOngoing}).
+			Or("tournaments.status = ? AND tournament_teams.status = ?", models.TournamentRegistrationClosed, models.RegistrationCheckedIn)).
+		Count(&count).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check team tournaments",
+		})
+		return false
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "roster_locked",
+			Message: "Team roster cannot change once the team has checked in to or is playing in a tournament",
+		})
+		return false
+	}
+
+	return true
+}
+
+// findTeamWithPair returns the ID of an existing team containing both players, or 0
+func findTeamWithPair(db *gorm.DB, playerA, playerB uint) (uint, error) {
+	var teamIDs []uint
+	err := db.Model(&models.TeamPlayer{}).
+		Where("player_id IN ?", []uint{playerA, playerB}).
+		Group("team_id").
+		Having("COUNT(DISTINCT player_id) = ?", 2).
+		Pluck("team_id", &teamIDs).Error
+	if err != nil || len(teamIDs) == 0 {
+		return 0, err
+	}
+	return teamIDs[0], nil
+}
+
+// respondWithTeam reloads the team and writes it as a success response
+func (tc *TeamController) respondWithTeam(c *gin.Context, teamID uint, message string) {
+	var team models.Team
+	if err := tc.db.Preload("Players.Player").First(&team, teamID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: message,
+		Data:    views.ToTeamResponse(team),
+	})
+}
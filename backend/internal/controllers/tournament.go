@@ -5,23 +5,32 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
+	"badminton-backend/internal/audit"
 	"badminton-backend/internal/models"
+	"badminton-backend/internal/services"
 	"badminton-backend/internal/views"
 )
 
 type TournamentController struct {
-	db *gorm.DB
+	tournaments services.TournamentService
+	audit       *audit.Logger
 }
 
-func NewTournamentController(db *gorm.DB) *TournamentController {
-	return &TournamentController{db: db}
+func NewTournamentController(tournaments services.TournamentService, auditLog *audit.Logger) *TournamentController {
+	return &TournamentController{tournaments: tournaments, audit: auditLog}
 }
 
+// GetTournaments lists tournaments a page at a time, filtered by status,
+// type, organiser, name and start date
 func (tc *TournamentController) GetTournaments(c *gin.Context) {
-	var tournaments []models.Tournament
-	if err := tc.db.Preload("Matches").Find(&tournaments).Error; err != nil {
+	q, ok := parseListing(c, services.TournamentListing)
+	if !ok {
+		return
+	}
+
+	tournaments, page, err := tc.tournaments.List(q)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
 			Error:   "database_error",
 			Message: "Failed to fetch tournaments",
@@ -29,7 +38,7 @@ func (tc *TournamentController) GetTournaments(c *gin.Context) {
 		return
 	}
 
-	var tournamentResponses []views.TournamentResponse
+	tournamentResponses := make([]views.TournamentResponse, 0, len(tournaments))
 	for _, tournament := range tournaments {
 		tournamentResponses = append(tournamentResponses, views.ToTournamentResponse(tournament))
 	}
@@ -37,12 +46,16 @@ func (tc *TournamentController) GetTournaments(c *gin.Context) {
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Tournaments retrieved successfully",
 		Data:    tournamentResponses,
+		Meta:    views.ToPaginationMeta(page),
 	})
 }
 
 func (tc *TournamentController) CreateTournament(c *gin.Context) {
-	var tournament models.Tournament
-	if err := c.ShouldBindJSON(&tournament); err != nil {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var req views.CreateTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_input",
 			Message: err.Error(),
@@ -50,14 +63,30 @@ func (tc *TournamentController) CreateTournament(c *gin.Context) {
 		return
 	}
 
-	if err := tc.db.Create(&tournament).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to create tournament",
-		})
+	tournament := models.Tournament{
+		Name:                 req.Name,
+		Description:          req.Description,
+		Type:                 req.Type,
+		StartDate:            req.StartDate,
+		EndDate:              req.EndDate,
+		MaxPlayers:           req.MaxPlayers,
+		MaxTeams:             req.MaxTeams,
+		EntryFee:             req.EntryFee,
+		PrizePool:            req.PrizePool,
+		CheckInWindowMinutes: req.CheckInWindowMinutes,
+	}
+
+	if err := tc.tournaments.Create(userObj.ID, &tournament); err != nil {
+		respondError(c, err, "Failed to create tournament")
 		return
 	}
 
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.created",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+	}, nil, tournament)
+
 	c.JSON(http.StatusCreated, views.SuccessResponse{
 		Message: "Tournament created successfully",
 		Data:    views.ToTournamentResponse(tournament),
@@ -65,64 +94,29 @@ func (tc *TournamentController) CreateTournament(c *gin.Context) {
 }
 
 func (tc *TournamentController) GetTournament(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, views.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid tournament ID",
-		})
-		return
-	}
-
-	var tournament models.Tournament
-	if err := tc.db.Preload("Matches").First(&tournament, uint(id)).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, views.ErrorResponse{
-				Error:   "not_found",
-				Message: "Tournament not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to fetch tournament",
-		})
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Tournament retrieved successfully",
-		Data:    views.ToTournamentResponse(tournament),
+		Data:    views.ToTournamentResponse(*tournament),
 	})
 }
 
+// UpdateTournament edits tournament details. Status changes go through the
+// lifecycle transition endpoints instead.
 func (tc *TournamentController) UpdateTournament(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, views.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid tournament ID",
-		})
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
 		return
 	}
+	before := *tournament
 
-	var tournament models.Tournament
-	if err := tc.db.First(&tournament, uint(id)).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, views.ErrorResponse{
-				Error:   "not_found",
-				Message: "Tournament not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to fetch tournament",
-		})
-		return
-	}
+	var req views.UpdateTournamentRequest
 
-	if err := c.ShouldBindJSON(&tournament); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_input",
 			Message: err.Error(),
@@ -130,42 +124,72 @@ func (tc *TournamentController) UpdateTournament(c *gin.Context) {
 		return
 	}
 
-	if err := tc.db.Save(&tournament).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to update tournament",
+	if req.Status != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "status_read_only",
+			Message: "Use the tournament transition endpoints to change status",
 		})
 		return
 	}
 
-	// Load matches data
-	tc.db.Preload("Matches").First(&tournament, tournament.ID)
+	if err := tc.tournaments.Update(tournament, req.TournamentChanges); err != nil {
+		respondError(c, err, "Failed to update tournament")
+		return
+	}
+
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.updated",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+	}, before, *tournament)
 
 	c.JSON(http.StatusOK, views.SuccessResponse{
 		Message: "Tournament updated successfully",
-		Data:    views.ToTournamentResponse(tournament),
+		Data:    views.ToTournamentResponse(*tournament),
 	})
 }
 
+// DeleteTournament removes a draft tournament. Tournaments that have opened
+// registration keep their history and must be cancelled instead.
 func (tc *TournamentController) DeleteTournament(c *gin.Context) {
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.tournaments.Delete(tournament); err != nil {
+		respondError(c, err, "Failed to delete tournament")
+		return
+	}
+
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.deleted",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+	}, *tournament, nil)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Tournament deleted successfully",
+	})
+}
+
+// loadTournament fetches the tournament named by the :id param,
+// writing an error response and returning false on failure
+func (tc *TournamentController) loadTournament(c *gin.Context) (*models.Tournament, bool) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, views.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid tournament ID",
 		})
-		return
+		return nil, false
 	}
 
-	if err := tc.db.Delete(&models.Tournament{}, uint(id)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
-			Error:   "database_error",
-			Message: "Failed to delete tournament",
-		})
-		return
+	tournament, err := tc.tournaments.Get(uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to fetch tournament")
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, views.SuccessResponse{
-		Message: "Tournament deleted successfully",
-	})
+	return tournament, true
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+// CancelTournament cancels a tournament that has not finished while keeping
+// its history: unfinished matches and open registrations are marked
+// cancelled, entry fees are queued for refund and entrants are notified (Admin only)
+func (tc *TournamentController) CancelTournament(c *gin.Context) {
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	var req views.CancelTournamentRequest
+
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, views.ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	before := *tournament
+	cancellation, err := tc.tournaments.Cancel(tournament, req.Reason)
+	if err != nil {
+		respondError(c, err, "Failed to cancel tournament")
+		return
+	}
+
+	details := fmt.Sprintf("%d matches and %d registrations cancelled, %d refunds created",
+		cancellation.CancelledMatches, cancellation.CancelledRegistrations, len(cancellation.Refunds))
+	if req.Reason != "" {
+		details += ", reason: " + req.Reason
+	}
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.cancelled",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+		Details:    details,
+	}, before, *tournament)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Tournament cancelled successfully",
+		Data: views.CancellationResponse{
+			Tournament:             views.ToTournamentResponse(*tournament),
+			CancelledMatches:       cancellation.CancelledMatches,
+			CancelledRegistrations: cancellation.CancelledRegistrations,
+			RefundsCreated:         len(cancellation.Refunds),
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+// GenerateDraw creates the first-round matches of a single-elimination
+// draw from the checked-in entrants and moves the tournament to drawn
+// (Admin only). Registration and check-in must both be closed.
+func (tc *TournamentController) GenerateDraw(c *gin.Context) {
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	before := *tournament
+	draw, err := tc.tournaments.GenerateDraw(tournament)
+	if err != nil {
+		respondError(c, err, "Failed to create draw")
+		return
+	}
+
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.drawn",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+		Details:    fmt.Sprintf("%d first-round matches, %d byes", len(draw.Matches), len(draw.Byes)),
+	}, before, *tournament)
+
+	byes := make([]views.ByeResponse, len(draw.Byes))
+	for i, entrant := range draw.Byes {
+		byes[i] = views.ByeResponse{PlayerID: entrant.PlayerID, TeamID: entrant.TeamID}
+	}
+
+	c.JSON(http.StatusCreated, views.SuccessResponse{
+		Message: "Draw generated successfully",
+		Data: views.DrawResponse{
+			MatchCount: len(draw.Matches),
+			Byes:       byes,
+		},
+	})
+}
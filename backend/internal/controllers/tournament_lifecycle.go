@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
+)
+
+// OpenRegistration opens (or reopens) registration for a tournament (Admin only)
+func (tc *TournamentController) OpenRegistration(c *gin.Context) {
+	tc.transition(c, tc.tournaments.OpenRegistration, "Registration opened successfully")
+}
+
+// CloseRegistration stops new registrations and tells entrants when check-in opens (Admin only)
+func (tc *TournamentController) CloseRegistration(c *gin.Context) {
+	tc.transition(c, tc.tournaments.CloseRegistration, "Registration closed successfully")
+}
+
+// StartTournament moves a drawn tournament into play (Admin only)
+func (tc *TournamentController) StartTournament(c *gin.Context) {
+	tc.transition(c, tc.tournaments.Start, "Tournament started successfully")
+}
+
+// CompleteTournament finishes an ongoing tournament once every match is decided (Admin only)
+func (tc *TournamentController) CompleteTournament(c *gin.Context) {
+	tc.transition(c, tc.tournaments.Complete, "Tournament completed successfully")
+}
+
+// transition loads the tournament, applies the lifecycle step, records the
+// status change and writes the response
+func (tc *TournamentController) transition(c *gin.Context, step func(*models.Tournament) error, message string) {
+	tournament, ok := tc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	before := *tournament
+	if err := step(tournament); err != nil {
+		respondError(c, err, "Failed to update tournament status")
+		return
+	}
+
+	tc.audit.RecordChange(c, models.AuditLog{
+		Action:     "tournament.status_changed",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+	}, before, *tournament)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: message,
+		Data:    views.ToTournamentResponse(*tournament),
+	})
+}
@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/views"
+)
+
+type TournamentStaffController struct {
+	db     *gorm.DB
+	policy *policy.Policy
+	audit  *audit.Logger
+}
+
+func NewTournamentStaffController(db *gorm.DB, pol *policy.Policy, auditLog *audit.Logger) *TournamentStaffController {
+	return &TournamentStaffController{db: db, policy: pol, audit: auditLog}
+}
+
+// GetStaff lists the staff assigned to a tournament. The tournament's
+// admin is its organizer and is not listed.
+func (sc *TournamentStaffController) GetStaff(c *gin.Context) {
+	tournament, ok := sc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	var staff []models.TournamentStaff
+	if err := sc.db.Preload("User").Where("tournament_id = ?", tournament.ID).Order("role, id").Find(&staff).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch tournament staff",
+		})
+		return
+	}
+
+	staffResponses := make([]views.TournamentStaffResponse, 0, len(staff))
+	for _, member := range staff {
+		staffResponses = append(staffResponses, views.ToTournamentStaffResponse(member))
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Tournament staff retrieved successfully",
+		Data: views.TournamentStaffListResponse{
+			AdminID: tournament.AdminID,
+			Staff:   staffResponses,
+		},
+	})
+}
+
+// AddStaff assigns a user a role in the tournament
+func (sc *TournamentStaffController) AddStaff(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	tournament, ok := sc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	var req views.AddStaffRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !req.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_role",
+			Message: "Role must be one of organizer, referee, scorer or desk",
+		})
+		return
+	}
+
+	var member models.User
+	if err := sc.db.First(&member, req.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "user_not_found",
+				Message: "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch user",
+		})
+		return
+	}
+
+	if !member.IsActive {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "user_inactive",
+			Message: "Deactivated users cannot be assigned to a tournament",
+		})
+		return
+	}
+
+	var count int64
+	if err := sc.db.Model(&models.TournamentStaff{}).
+		Where("tournament_id = ? AND user_id = ? AND role = ?", tournament.ID, member.ID, req.Role).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to check tournament staff",
+		})
+		return
+	}
+	if count > 0 {
+		respondAlreadyAssigned(c)
+		return
+	}
+
+	staff := models.TournamentStaff{
+		TournamentID: tournament.ID,
+		UserID:       member.ID,
+		Role:         req.Role,
+		AssignedByID: userObj.ID,
+	}
+	if err := sc.db.Create(&staff).Error; err != nil {
+		// A concurrent request assigned the same role first
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondAlreadyAssigned(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to assign tournament staff",
+		})
+		return
+	}
+	staff.User = member
+
+	sc.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "tournament.staff_added",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+		Details:    fmt.Sprintf("user %d as %s", member.ID, req.Role),
+	})
+
+	c.JSON(http.StatusCreated, views.SuccessResponse{
+		Message: "Tournament staff assigned successfully",
+		Data:    views.ToTournamentStaffResponse(staff),
+	})
+}
+
+// respondAlreadyAssigned rejects assigning a role the user already has
+func respondAlreadyAssigned(c *gin.Context) {
+	c.JSON(http.StatusConflict, views.ErrorResponse{
+		Error:   "already_assigned",
+		Message: "User already has this role in the tournament",
+	})
+}
+
+// RemoveStaff removes a role assignment from the tournament
+func (sc *TournamentStaffController) RemoveStaff(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	tournament, ok := sc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	staffID, err := strconv.ParseUint(c.Param("staff_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid staff ID",
+		})
+		return
+	}
+
+	var staff models.TournamentStaff
+	if err := sc.db.Where("id = ? AND tournament_id = ?", uint(staffID), tournament.ID).First(&staff).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Staff assignment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch staff assignment",
+		})
+		return
+	}
+
+	// Hard delete so the user can be assigned the same role again later
+	if err := sc.db.Unscoped().Delete(&staff).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to remove tournament staff",
+		})
+		return
+	}
+
+	sc.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "tournament.staff_removed",
+		TargetType: "tournament",
+		TargetID:   &tournament.ID,
+		Details:    fmt.Sprintf("user %d as %s", staff.UserID, staff.Role),
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Tournament staff removed successfully",
+	})
+}
+
+// GetMyTournamentRoles returns the current user's roles and permissions in
+// the tournament so clients can decide which actions to offer
+func (sc *TournamentStaffController) GetMyTournamentRoles(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	tournament, ok := sc.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	roles, err := sc.policy.TournamentRoles(userObj, tournament.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch tournament roles",
+		})
+		return
+	}
+
+	seen := make(map[models.TournamentPermission]bool)
+	permissions := []models.TournamentPermission{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions() {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	if roles == nil {
+		roles = []models.StaffRole{}
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Tournament roles retrieved successfully",
+		Data: views.TournamentRolesResponse{
+			Roles:       roles,
+			Permissions: permissions,
+		},
+	})
+}
+
+// loadTournament fetches the tournament named by the :id param,
+// writing an error response and returning false on failure
+func (sc *TournamentStaffController) loadTournament(c *gin.Context) (*models.Tournament, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid tournament ID",
+		})
+		return nil, false
+	}
+
+	var tournament models.Tournament
+	if err := sc.db.First(&tournament, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, views.ErrorResponse{
+				Error:   "not_found",
+				Message: "Tournament not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch tournament",
+		})
+		return nil, false
+	}
+
+	return &tournament, true
+}
@@ -0,0 +1,534 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/throttle"
+	"badminton-backend/internal/totp"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps
+	totpIssuer = "Badminton"
+	// recoveryCodeCount is how many recovery codes are issued at a time
+	recoveryCodeCount = 10
+)
+
+// LoginMFA completes a login challenged for two-factor authentication,
+// accepting either a TOTP code or a recovery code
+func (ac *AuthController) LoginMFA(c *gin.Context) {
+	var req views.LoginMFARequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	challenge, err := ac.tokens.Lookup(req.MFAToken, models.TokenMFAChallenge)
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_mfa_token",
+			Message: "Two-factor challenge is invalid or has expired, please log in again",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+	user := challenge.User
+
+	// Wrong codes count towards the same backoff as wrong passwords
+	accountKey, ipKey := throttle.AccountKey(user.ID), throttle.IPKey(c.ClientIP())
+	wait, err := ac.guard.RetryAfter(accountKey, ipKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
+	ok, err := ac.checkSecondFactor(&user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+	if !ok {
+		ac.recordLoginFailure(c, user.Username, accountKey, ipKey, true, user.ID)
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_mfa_code",
+			Message: "Invalid two-factor authentication code",
+		})
+		return
+	}
+
+	// The challenge can only complete one login
+	err = ac.tokens.Redeem(req.MFAToken, models.TokenMFAChallenge, func(tx *gorm.DB, userToken *models.UserToken) error {
+		return nil
+	})
+	if err == usertokens.ErrInvalidToken {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_mfa_token",
+			Message: "Two-factor challenge is invalid or has expired, please log in again",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to authenticate",
+		})
+		return
+	}
+
+	if err := ac.guard.Reset(loginThrottleKeys(&user)...); err != nil {
+		log.Printf("Failed to reset login throttle for user %d: %v", user.ID, err)
+	}
+
+	tokens, err := ac.sessions.Issue(&user, true, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate authentication token",
+		})
+		return
+	}
+
+	ac.recordLogin(c, &user)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Login successful",
+		Data:    authData(user, tokens),
+	})
+}
+
+// GetTwoFactorStatus returns whether 2FA is on and how many recovery codes remain
+func (ac *AuthController) GetTwoFactorStatus(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var remaining int64
+	ac.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userObj.ID).Count(&remaining)
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Two-factor status retrieved successfully",
+		Data: views.TwoFactorStatusResponse{
+			Enabled:                userObj.IsTOTPEnabled(),
+			Required:               userObj.IsAdmin(),
+			RecoveryCodesRemaining: remaining,
+		},
+	})
+}
+
+// SetupTwoFactor generates a new TOTP secret for the current user. 2FA is
+// not enforced until the secret is confirmed with EnableTwoFactor.
+func (ac *AuthController) SetupTwoFactor(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	if userObj.IsTOTPEnabled() {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "two_factor_enabled",
+			Message: "Two-factor authentication is already enabled",
+		})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "secret_generation_failed",
+			Message: "Failed to generate two-factor secret",
+		})
+		return
+	}
+
+	if err := ac.db.Model(userObj).Update("totp_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to save two-factor secret",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Scan the QR code with your authenticator app, then confirm with a code",
+		Data: views.TwoFactorSetupResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(secret, totpIssuer, userObj.Email),
+		},
+	})
+}
+
+// EnableTwoFactor confirms the secret from SetupTwoFactor with a code,
+// turns 2FA on and returns the recovery codes, which are only shown once
+func (ac *AuthController) EnableTwoFactor(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var req views.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if userObj.IsTOTPEnabled() {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "two_factor_enabled",
+			Message: "Two-factor authentication is already enabled",
+		})
+		return
+	}
+
+	if userObj.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "two_factor_not_set_up",
+			Message: "Set up two-factor authentication before enabling it",
+		})
+		return
+	}
+
+	step, ok := totp.Validate(userObj.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_mfa_code",
+			Message: "Invalid two-factor authentication code",
+		})
+		return
+	}
+
+	var codes []string
+	err := ac.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(userObj).Updates(map[string]interface{}{
+			"totp_enabled_at": now,
+			"totp_last_step":  step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userObj.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to enable two-factor authentication",
+		})
+		return
+	}
+
+	// The code just entered proves the second factor for this session
+	if err := ac.sessions.MarkMFA(c.GetUint("session_id")); err != nil {
+		log.Printf("Failed to mark session %d as MFA: %v", c.GetUint("session_id"), err)
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "2fa.enabled",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Two-factor authentication enabled. Store your recovery codes somewhere safe",
+		Data:    views.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// DisableTwoFactor turns 2FA off after checking the password and a current
+// code. Admins lose admin access until they enable it again.
+func (ac *AuthController) DisableTwoFactor(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var req views.DisableTwoFactorRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !userObj.IsTOTPEnabled() {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "two_factor_disabled",
+			Message: "Two-factor authentication is not enabled",
+		})
+		return
+	}
+
+	if !userObj.CheckPassword(req.Password) {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_current_password",
+			Message: "Current password is incorrect",
+		})
+		return
+	}
+
+	if !ac.verifySecondFactor(c, userObj, req.Code) {
+		return
+	}
+
+	if err := ac.clearTwoFactor(userObj); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to disable two-factor authentication",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &userObj.ID,
+		Action:     "2fa.disabled",
+		TargetType: "user",
+		TargetID:   &userObj.ID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// ResetUserTwoFactor turns off 2FA for a user who has lost both their
+// authenticator and recovery codes, and logs them out everywhere (Admin only)
+func (ac *AuthController) ResetUserTwoFactor(c *gin.Context) {
+	admin, _ := c.Get("user")
+	adminObj := admin.(*models.User)
+
+	var targetUser models.User
+	if err := ac.db.First(&targetUser, c.Param("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, views.ErrorResponse{
+			Error:   "user_not_found",
+			Message: "User not found",
+		})
+		return
+	}
+
+	if err := ac.clearTwoFactor(&targetUser); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to reset two-factor authentication",
+		})
+		return
+	}
+
+	if err := ac.revokeAccess(targetUser.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to revoke user sessions and API keys",
+		})
+		return
+	}
+
+	ac.audit.RecordRequest(c, models.AuditLog{
+		ActorID:    &adminObj.ID,
+		Action:     "2fa.reset",
+		TargetType: "user",
+		TargetID:   &targetUser.ID,
+	})
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "Two-factor authentication reset successfully",
+		Data:    views.ToUserResponse(targetUser),
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a
+// current code
+func (ac *AuthController) RegenerateRecoveryCodes(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*models.User)
+
+	var req views.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !userObj.IsTOTPEnabled() {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "two_factor_disabled",
+			Message: "Two-factor authentication is not enabled",
+		})
+		return
+	}
+
+	if !ac.verifySecondFactor(c, userObj, req.Code) {
+		return
+	}
+
+	var codes []string
+	err := ac.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userObj.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to generate recovery codes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views.SuccessResponse{
+		Message: "New recovery codes generated. Your old codes no longer work",
+		Data:    views.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// verifySecondFactor checks a code for a signed-in user, writing an error
+// response and returning false if it is wrong. Wrong codes back off like
+// wrong passwords, so a stolen session cannot guess its way to changing
+// the second factor.
+func (ac *AuthController) verifySecondFactor(c *gin.Context, user *models.User, code string) bool {
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusConflict, views.ErrorResponse{
+			Error:   "two_factor_not_set_up",
+			Message: "Two-factor authentication has no secret, set it up again",
+		})
+		return false
+	}
+
+	accountKey, ipKey := throttle.AccountKey(user.ID), throttle.IPKey(c.ClientIP())
+	wait, err := ac.guard.RetryAfter(accountKey, ipKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to verify two-factor code",
+		})
+		return false
+	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return false
+	}
+
+	ok, err := ac.checkSecondFactor(user, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to verify two-factor code",
+		})
+		return false
+	}
+	if !ok {
+		ac.recordLoginFailure(c, user.Username, accountKey, ipKey, true, user.ID)
+		c.JSON(http.StatusBadRequest, views.ErrorResponse{
+			Error:   "invalid_mfa_code",
+			Message: "Invalid two-factor authentication code",
+		})
+		return false
+	}
+
+	if err := ac.guard.Reset(accountKey); err != nil {
+		log.Printf("Failed to reset login throttle for user %d: %v", user.ID, err)
+	}
+	return true
+}
+
+// checkSecondFactor accepts a TOTP code that has not been used before, or
+// an unused recovery code, which is then spent. Without a secret only
+// recovery codes are accepted.
+func (ac *AuthController) checkSecondFactor(user *models.User, code string) (bool, error) {
+	if user.TOTPSecret != "" {
+		if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+			// Only a later step than the last accepted one may be used
+			result := ac.db.Model(&models.User{}).
+				Where("id = ? AND totp_last_step < ?", user.ID, step).
+				Update("totp_last_step", step)
+			return result.RowsAffected == 1, result.Error
+		}
+	}
+
+	result := ac.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// clearTwoFactor turns 2FA off and deletes the recovery codes
+func (ac *AuthController) clearTwoFactor(user *models.User) error {
+	return ac.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores new
+// ones, returning them formatted for display
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(raw)}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode returns the hash under which a recovery code is stored,
+// ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
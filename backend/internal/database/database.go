@@ -0,0 +1,139 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/config"
+)
+
+// Supported drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Config selects the database and how connections are pooled
+type Config struct {
+	Driver string
+	DSN    string
+
+	MaxOpenConns    int // 0 means unlimited
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration // 0 means connections are reused forever
+	ConnMaxIdleTime time.Duration
+}
+
+// ConfigFrom resolves the loaded settings into a connection string and
+// pool limits
+func ConfigFrom(settings config.Database) Config {
+	cfg := Config{
+		Driver:          settings.Driver,
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: settings.ConnMaxLifetime,
+		ConnMaxIdleTime: settings.ConnMaxIdleTime,
+	}
+
+	switch settings.Driver {
+	case DriverSQLite:
+		cfg.DSN = sqliteDSN(settings.Path)
+	case DriverPostgres:
+		cfg.DSN = settings.URL
+		if cfg.DSN == "" {
+			cfg.DSN = postgresDSN(settings)
+		}
+	}
+	return cfg
+}
+
+// Open connects to the configured database and applies the pool settings
+func Open(cfg Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case DriverSQLite:
+		dialector = sqlite.Open(cfg.DSN)
+	case DriverPostgres:
+		dialector = postgres.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteDSN opens the file with foreign keys enforced, as PostgreSQL does,
+// and waits for locks held by other connections instead of failing at once.
+// Transactions begin immediate, taking the write lock up front: SQLite has
+// no row locks, so this is what keeps a transaction's reads valid until it
+// writes, where PostgreSQL uses SELECT ... FOR UPDATE.
+func sqliteDSN(path string) string {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_txlock", "immediate")
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + params.Encode()
+}
+
+// postgresDSN builds a keyword/value connection string from the separate
+// fields. Sessions use UTC so timestamps read back the same as on SQLite.
+func postgresDSN(settings config.Database) string {
+	fields := []struct{ key, value string }{
+		{"host", settings.Host},
+		{"port", settings.Port},
+		{"user", settings.User},
+		{"password", settings.Password},
+		{"dbname", settings.Name},
+		{"sslmode", settings.SSLMode},
+	}
+
+	parts := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		parts = append(parts, field.key+"="+quoteDSNValue(field.value))
+	}
+	parts = append(parts, "TimeZone=UTC")
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes a keyword/value connection string value when it
+// contains spaces, quotes or backslashes
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
@@ -0,0 +1,63 @@
+package invites
+
+import (
+	"log"
+	"net/url"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/mailer"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/usertokens"
+)
+
+// Sender emails invites to claim the accounts converted from legacy players
+type Sender struct {
+	db     *gorm.DB
+	tokens *usertokens.Manager
+	mailer mailer.Mailer
+	appURL string
+}
+
+func NewSender(db *gorm.DB, tokens *usertokens.Manager, mail mailer.Mailer, appURL string) *Sender {
+	return &Sender{db: db, tokens: tokens, mailer: mail, appURL: appURL}
+}
+
+// SendPending invites every active account that is still unclaimed and
+// returns how many invites were sent. Each invite replaces the previous
+// one, so running it again resends fresh links. An account whose email
+// fails is logged and skipped.
+func (s *Sender) SendPending() (int, error) {
+	var users []models.User
+	if err := s.db.Where("claim_pending = ? AND is_active = ?", true, true).Order("id").Find(&users).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range users {
+		if err := s.send(&users[i]); err != nil {
+			log.Printf("Failed to send claim invite to user %d: %v", users[i].ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// send issues a claim token and emails its link
+func (s *Sender) send(user *models.User) error {
+	token, err := s.tokens.Issue(user, models.TokenAccountClaim, usertokens.AccountClaimTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Claim your player account",
+		Body: "Hi " + user.FullName + ",\n\n" +
+			"Your player profile has moved to a full account with the username " + user.Username + ". " +
+			"Choose a password to start using it:\n\n" +
+			s.appURL + "/auth/claim-account?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link expires in 30 days and can only be used once.",
+	})
+}
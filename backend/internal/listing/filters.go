@@ -0,0 +1,159 @@
+package listing
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dateLayout is accepted alongside RFC 3339 for date filters
+const dateLayout = "2006-01-02"
+
+// Equals matches rows whose column has exactly the value
+func Equals(column string) Filter {
+	return func(value string) (Scope, error) {
+		return where(column+" = ?", value), nil
+	}
+}
+
+// OneOf matches rows whose column has the value, which must be one of the
+// allowed values
+func OneOf(column string, allowed ...string) Filter {
+	return func(value string) (Scope, error) {
+		for _, candidate := range allowed {
+			if value == candidate {
+				return where(column+" = ?", value), nil
+			}
+		}
+		return nil, errors.New("must be one of " + strings.Join(allowed, ", "))
+	}
+}
+
+// ID matches rows whose column holds the given ID
+func ID(column string) Filter {
+	return IDFunc(func(id uint) Scope {
+		return where(column+" = ?", id)
+	})
+}
+
+// IDFunc parses an ID and narrows the query with the scope it builds, for
+// filters that match more than one column
+func IDFunc(scope func(id uint) Scope) Filter {
+	return func(value string) (Scope, error) {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			return nil, errors.New("must be a positive number")
+		}
+		return scope(uint(id)), nil
+	}
+}
+
+// Bool matches rows whose column is true or false
+func Bool(column string) Filter {
+	return func(value string) (Scope, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return where(column+" = ?", b), nil
+	}
+}
+
+// IsSet matches rows whose nullable column is set (true) or null (false)
+func IsSet(column string) Filter {
+	return func(value string) (Scope, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		if b {
+			return where(column + " IS NOT NULL"), nil
+		}
+		return where(column + " IS NULL"), nil
+	}
+}
+
+// AtLeast and AtMost bound a numeric column
+func AtLeast(column string) Filter {
+	return number(column + " >= ?")
+}
+
+func AtMost(column string) Filter {
+	return number(column + " <= ?")
+}
+
+func number(condition string) Filter {
+	return func(value string) (Scope, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("must be a whole number")
+		}
+		return where(condition, n), nil
+	}
+}
+
+// Since matches rows whose time column is at or after the value
+func Since(column string) Filter {
+	return func(value string) (Scope, error) {
+		t, _, err := parseTime(value)
+		if err != nil {
+			return nil, err
+		}
+		return where(column+" >= ?", t), nil
+	}
+}
+
+// Until matches rows whose time column is at or before the value. A bare
+// date includes the whole day.
+func Until(column string) Filter {
+	return func(value string) (Scope, error) {
+		t, dateOnly, err := parseTime(value)
+		if err != nil {
+			return nil, err
+		}
+		if dateOnly {
+			return where(column+" < ?", t.AddDate(0, 0, 1)), nil
+		}
+		return where(column+" <= ?", t), nil
+	}
+}
+
+// Search matches rows where any of the columns contains the value, ignoring
+// letter case
+func Search(columns ...string) Filter {
+	return func(value string) (Scope, error) {
+		pattern := "%" + escapeLike(strings.ToLower(value)) + "%"
+		conditions := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			conditions[i] = "LOWER(" + column + `) LIKE ? ESCAPE '\'`
+			args[i] = pattern
+		}
+		return where("("+strings.Join(conditions, " OR ")+")", args...), nil
+	}
+}
+
+func where(condition string, args ...interface{}) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(condition, args...)
+	}
+}
+
+// parseTime reads an RFC 3339 time or a date, reporting which it was
+func parseTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, errors.New("must be a date (YYYY-MM-DD) or RFC 3339 time")
+}
+
+// escapeLike stops the value's own % and _ acting as wildcards
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,97 @@
+package listing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type row struct {
+	ID uint
+}
+
+// toSQL renders the WHERE clause a scope adds, without running it
+func toSQL(t *testing.T, scope Scope) (string, []interface{}) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := db.Scopes(scope).Find(&[]row{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestFilters(t *testing.T) {
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		value    string
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{"equals", Equals("round"), "final", "SELECT * FROM `rows` WHERE round = ?", []interface{}{"final"}},
+		{"one of", OneOf("status", "open", "closed"), "closed", "SELECT * FROM `rows` WHERE status = ?", []interface{}{"closed"}},
+		{"ID", ID("owner_id"), "42", "SELECT * FROM `rows` WHERE owner_id = ?", []interface{}{uint(42)}},
+		{"bool", Bool("is_active"), "false", "SELECT * FROM `rows` WHERE is_active = ?", []interface{}{false}},
+		{"is set", IsSet("verified_at"), "true", "SELECT * FROM `rows` WHERE verified_at IS NOT NULL", []interface{}{}},
+		{"is not set", IsSet("verified_at"), "0", "SELECT * FROM `rows` WHERE verified_at IS NULL", []interface{}{}},
+		{"at least", AtLeast("ranking"), "10", "SELECT * FROM `rows` WHERE ranking >= ?", []interface{}{10}},
+		{"at most", AtMost("ranking"), "-3", "SELECT * FROM `rows` WHERE ranking <= ?", []interface{}{-3}},
+		{"since a date", Since("start_date"), "2025-03-14", "SELECT * FROM `rows` WHERE start_date >= ?", []interface{}{day}},
+		{"since a time", Since("start_date"), "2025-03-14T09:30:00Z", "SELECT * FROM `rows` WHERE start_date >= ?", []interface{}{day.Add(9*time.Hour + 30*time.Minute)}},
+		{"until a date includes the day", Until("start_date"), "2025-03-14", "SELECT * FROM `rows` WHERE start_date < ?", []interface{}{day.AddDate(0, 0, 1)}},
+		{"until a time", Until("start_date"), "2025-03-14T09:30:00Z", "SELECT * FROM `rows` WHERE start_date <= ?", []interface{}{day.Add(9*time.Hour + 30*time.Minute)}},
+		{"search", Search("name", "email"), "Ann", "SELECT * FROM `rows` WHERE (LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\')", []interface{}{"%ann%", "%ann%"}},
+		{"search escapes wildcards", Search("name"), `50%_a\b`, "SELECT * FROM `rows` WHERE (LOWER(name) LIKE ? ESCAPE '\\')", []interface{}{`%50\%\_a\\b%`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := tt.filter(tt.value)
+			if err != nil {
+				t.Fatalf("filter rejected %q: %v", tt.value, err)
+			}
+			sql, vars := toSQL(t, scope)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL %s, want %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(vars, tt.wantVars) {
+				t.Errorf("vars %#v, want %#v", vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestFiltersRejectInvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		value  string
+	}{
+		{"value not allowed", OneOf("status", "open", "closed"), "Open"},
+		{"ID not a number", ID("owner_id"), "seven"},
+		{"ID zero", ID("owner_id"), "0"},
+		{"ID negative", ID("owner_id"), "-1"},
+		{"ID too large", ID("owner_id"), "4294967296"},
+		{"bool", Bool("is_active"), "yes"},
+		{"is set", IsSet("verified_at"), "maybe"},
+		{"number", AtLeast("ranking"), "1.5"},
+		{"date", Since("start_date"), "14/03/2025"},
+		{"time without zone", Until("start_date"), "2025-03-14T09:30:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.filter(tt.value); err == nil {
+				t.Errorf("filter accepted %q", tt.value)
+			}
+		})
+	}
+}
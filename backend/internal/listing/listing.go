@@ -0,0 +1,169 @@
+// Package listing parses the page, sort and filter parameters of list
+// endpoints and applies them to GORM queries. Each endpoint declares which
+// filters and sort keys it accepts in a Spec; anything else is rejected so
+// clients cannot reach arbitrary columns.
+package listing
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultLimit and MaxLimit bound how many rows one page returns
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Scope narrows a query
+type Scope = func(*gorm.DB) *gorm.DB
+
+// Filter turns the value of a query parameter into a scope, or an error
+// message when the value is not valid for it
+type Filter func(value string) (Scope, error)
+
+// Spec is what a list endpoint accepts
+type Spec struct {
+	// Filters by query parameter name
+	Filters map[string]Filter
+	// Sorts maps sort keys to the column they order by
+	Sorts map[string]string
+	// DefaultSort is used when the request names none, in the same
+	// "key" or "-key" form as the sort parameter
+	DefaultSort string
+}
+
+// Error is a parameter the endpoint cannot accept, with a stable code for
+// API clients
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Query is a parsed list request
+type Query struct {
+	Page  int
+	Limit int
+
+	filters []Scope
+	order   []string
+}
+
+// Page describes the slice of results a query returned
+type Page struct {
+	Number int
+	Limit  int
+	Total  int64
+}
+
+// Pages is how many pages the results span
+func (p Page) Pages() int {
+	if p.Limit == 0 {
+		return 0
+	}
+	return int((p.Total + int64(p.Limit) - 1) / int64(p.Limit))
+}
+
+// Parse reads page, limit, sort and the spec's filters from the query
+// string. Sorting is by one or more comma separated keys, each prefixed
+// with "-" for descending order.
+func Parse(values url.Values, spec Spec) (*Query, error) {
+	q := &Query{Page: 1, Limit: DefaultLimit}
+
+	if value := values.Get("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, &Error{Code: "invalid_page", Message: "Page must be a positive number"}
+		}
+		q.Page = n
+	}
+
+	if value := values.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, &Error{Code: "invalid_limit", Message: "Limit must be between 1 and " + strconv.Itoa(MaxLimit)}
+		}
+		q.Limit = n
+	}
+
+	sortParam := values.Get("sort")
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	sortedByID := false
+	for _, key := range strings.Split(sortParam, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(key, "-") {
+			key, direction = key[1:], "DESC"
+		}
+		column, ok := spec.Sorts[key]
+		if !ok {
+			return nil, &Error{Code: "invalid_sort", Message: "Cannot sort by " + key + ", use one of: " + strings.Join(sortedKeys(spec.Sorts), ", ")}
+		}
+		q.order = append(q.order, column+" "+direction)
+		sortedByID = sortedByID || column == "id"
+	}
+	// Rows with equal sort values keep a fixed order so pages do not
+	// overlap or skip rows
+	if !sortedByID {
+		q.order = append(q.order, "id ASC")
+	}
+
+	names := make([]string, 0, len(spec.Filters))
+	for name := range spec.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		scope, err := spec.Filters[name](value)
+		if err != nil {
+			return nil, &Error{Code: "invalid_filter", Message: "Invalid " + name + ": " + err.Error()}
+		}
+		q.filters = append(q.filters, scope)
+	}
+
+	return q, nil
+}
+
+// Find loads the requested page of rows matching the filters into dest,
+// a pointer to a slice, and counts every matching row. db may carry
+// preloads and conditions of its own.
+func (q *Query) Find(db *gorm.DB, dest interface{}) (Page, error) {
+	page := Page{Number: q.Page, Limit: q.Limit}
+
+	db = db.Scopes(q.filters...)
+	if err := db.Session(&gorm.Session{}).Model(dest).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	for _, order := range q.order {
+		db = db.Order(order)
+	}
+	err := db.Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(dest).Error
+	return page, err
+}
+
+func sortedKeys(sorts map[string]string) []string {
+	keys := make([]string, 0, len(sorts))
+	for key := range sorts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package listing
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+var testSpec = Spec{
+	Filters: map[string]Filter{
+		"status": OneOf("status", "open", "closed"),
+		"owner":  ID("owner_id"),
+	},
+	Sorts: map[string]string{
+		"id":   "id",
+		"name": "name",
+		"date": "start_date",
+	},
+	DefaultSort: "-date",
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantPage  int
+		wantLimit int
+		wantOrder []string
+		wantErr   string
+	}{
+		{name: "defaults", query: "", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"start_date DESC", "id ASC"}},
+		{name: "page and limit", query: "page=3&limit=50", wantPage: 3, wantLimit: 50, wantOrder: []string{"start_date DESC", "id ASC"}},
+		{name: "largest limit", query: "limit=100", wantPage: 1, wantLimit: MaxLimit, wantOrder: []string{"start_date DESC", "id ASC"}},
+		{name: "several sort keys", query: "sort=name,-date", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"name ASC", "start_date DESC", "id ASC"}},
+		{name: "sort by id needs no tie breaker", query: "sort=-id", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"id DESC"}},
+		{name: "blank sort keys are skipped", query: "sort=name,,%20", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"name ASC", "id ASC"}},
+		{name: "valid filters", query: "status=open&owner=7", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"start_date DESC", "id ASC"}},
+		{name: "unknown parameters are ignored", query: "colour=red", wantPage: 1, wantLimit: DefaultLimit, wantOrder: []string{"start_date DESC", "id ASC"}},
+
+		{name: "page zero", query: "page=0", wantErr: "invalid_page"},
+		{name: "negative page", query: "page=-1", wantErr: "invalid_page"},
+		{name: "page not a number", query: "page=two", wantErr: "invalid_page"},
+		{name: "limit zero", query: "limit=0", wantErr: "invalid_limit"},
+		{name: "limit over the maximum", query: "limit=101", wantErr: "invalid_limit"},
+		{name: "limit not a number", query: "limit=all", wantErr: "invalid_limit"},
+		{name: "sort by a column not on the allow-list", query: "sort=password", wantErr: "invalid_sort"},
+		{name: "sort by a raw column name", query: "sort=start_date", wantErr: "invalid_sort"},
+		{name: "sort injection", query: "sort=name%3BDROP%20TABLE%20users", wantErr: "invalid_sort"},
+		{name: "filter value not allowed", query: "status=archived", wantErr: "invalid_filter"},
+		{name: "filter ID not a number", query: "owner=abc", wantErr: "invalid_filter"},
+		{name: "filter ID zero", query: "owner=0", wantErr: "invalid_filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			q, err := Parse(values, testSpec)
+			if tt.wantErr != "" {
+				var listingErr *Error
+				if !errors.As(err, &listingErr) || listingErr.Code != tt.wantErr {
+					t.Fatalf("Parse(%q) returned %v, want %s", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned %v", tt.query, err)
+			}
+
+			if q.Page != tt.wantPage || q.Limit != tt.wantLimit {
+				t.Errorf("page %d limit %d, want page %d limit %d", q.Page, q.Limit, tt.wantPage, tt.wantLimit)
+			}
+			if !reflect.DeepEqual(q.order, tt.wantOrder) {
+				t.Errorf("order %q, want %q", q.order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestPages(t *testing.T) {
+	tests := []struct {
+		page Page
+		want int
+	}{
+		{Page{Limit: 20, Total: 0}, 0},
+		{Page{Limit: 20, Total: 1}, 1},
+		{Page{Limit: 20, Total: 20}, 1},
+		{Page{Limit: 20, Total: 21}, 2},
+		{Page{Limit: 0, Total: 5}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.page.Pages(); got != tt.want {
+			t.Errorf("%+v.Pages() = %d, want %d", tt.page, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"badminton-backend/internal/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer sends email through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message, authenticating when a username is configured
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body := "From: " + m.From + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + msg.Subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + strings.ReplaceAll(msg.Body, "\n", "\r\n")
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{msg.To}, []byte(body))
+}
+
+// LogMailer writes emails to a file, or to the server log when no path is
+// set, so links can be followed during development without an SMTP server
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send records the message
+func (m *LogMailer) Send(msg Message) error {
+	entry := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+
+	if m.Path == "" {
+		log.Printf("Email (not sent):\n%s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "----- %s\n%s\n", time.Now().Format(time.RFC3339), entry)
+	return err
+}
+
+// New builds the mailer selected by the settings: "smtp", or "log" to
+// write emails to LogFile, or the server log when it is empty
+func New(settings config.Mail) (Mailer, error) {
+	switch settings.Driver {
+	case "log":
+		return &LogMailer{Path: settings.LogFile}, nil
+	case "smtp":
+		return &SMTPMailer{
+			Host:     settings.SMTPHost,
+			Port:     settings.SMTPPort,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword,
+			From:     settings.From,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", settings.Driver)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -9,40 +10,79 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 
+	"badminton-backend/internal/apikeys"
 	"badminton-backend/internal/models"
+	"badminton-backend/internal/views"
 )
 
-var JWTSecret = []byte("your-secret-key-here") // In production, use env variable
-
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates JWT token for user
-func GenerateToken(user *models.User) (string, error) {
+// GenerateToken generates a short-lived access token for a user's session,
+// signed with the active key
+func GenerateToken(user *models.User, sessionID uint) (string, error) {
+	key, _ := findKey(tokenConfig.Keys, tokenConfig.ActiveKID)
+	if key.SignKey == nil {
+		return "", errors.New("token signing is not configured")
+	}
+
+	now := time.Now()
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     string(user.Role),
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      string(user.Role),
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    tokenConfig.Issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenConfig.TTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JWTSecret)
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SignKey)
+}
+
+// ParseToken verifies a token against the configured keys and returns its claims
+func ParseToken(tokenString string) (*Claims, error) {
+	methods := make([]string, 0, len(tokenConfig.Keys))
+	for _, key := range tokenConfig.Keys {
+		methods = append(methods, key.Method.Alg())
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods)}
+	if tokenConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(tokenConfig.Issuer))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey, options...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
 }
 
-// AuthMiddleware validates JWT token
+// AuthMiddleware validates JWT token, or an API key sent in its place
 func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+	keys := apikeys.NewManager(db)
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "authorization_required",
+				Message: "Authorization header required",
+			})
 			c.Abort()
 			return
 		}
@@ -50,19 +90,37 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		// Extract token from "Bearer <token>"
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "bearer_token_required",
+				Message: "Bearer token required",
+			})
 			c.Abort()
 			return
 		}
 
+		if apikeys.IsAPIKey(tokenString) {
+			authenticateAPIKey(c, keys, tokenString)
+			return
+		}
+
 		// Parse and validate token
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return JWTSecret, nil
-		})
+		claims, err := ParseToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "invalid_token",
+				Message: "Invalid token",
+			})
+			c.Abort()
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		// Reject tokens whose session has been logged out or revoked
+		var session models.Session
+		if err := db.Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID).First(&session).Error; err != nil || !session.IsActive(time.Now()) {
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "session_revoked",
+				Message: "Session expired or revoked",
+			})
 			c.Abort()
 			return
 		}
@@ -70,13 +128,19 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		// Get user from database
 		var user models.User
 		if err := db.First(&user, claims.UserID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "user_not_found",
+				Message: "User not found",
+			})
 			c.Abort()
 			return
 		}
 
 		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User account deactivated"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "account_deactivated",
+				Message: "User account deactivated",
+			})
 			c.Abort()
 			return
 		}
@@ -85,23 +149,109 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 		c.Set("user", &user)
 		c.Set("user_id", user.ID)
 		c.Set("user_role", string(user.Role))
+		c.Set("session_id", session.ID)
+		c.Set("mfa", session.MFA)
+		c.Next()
+	}
+}
+
+// authenticateAPIKey authenticates a request made with an API key and
+// checks the key's scope allows the route
+func authenticateAPIKey(c *gin.Context, keys *apikeys.Manager, key string) {
+	apiKey, err := keys.Authenticate(key, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "invalid_api_key",
+			Message: "Invalid or revoked API key",
+		})
+		c.Abort()
+		return
+	}
+
+	user := apiKey.User
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+			Error:   "account_deactivated",
+			Message: "User account deactivated",
+		})
+		c.Abort()
+		return
+	}
+
+	if !apikeys.Allows(apiKey.Scope, c.Request.Method, c.FullPath()) {
+		c.JSON(http.StatusForbidden, views.ErrorResponse{
+			Error:   "api_key_scope",
+			Message: "API key scope does not allow this request",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set("user", &user)
+	c.Set("user_id", user.ID)
+	c.Set("user_role", string(user.Role))
+	c.Set("api_key_id", apiKey.ID)
+	// Admin keys can only be created from a login that just passed
+	// two-factor authentication and always expire, so they carry it for
+	// RequireAdmin
+	c.Set("mfa", apiKey.Scope == models.APIKeyAdmin)
+	c.Next()
+}
+
+// RequireSession middleware rejects requests made with an API key, for
+// account security routes that need the user to be logged in
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, usingKey := c.Get("api_key_id"); usingKey {
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "session_required",
+				Message: "This request cannot be made with an API key",
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
 
-// RequireAdmin middleware requires admin role
+// RequireAdmin middleware requires admin role and a login that passed
+// two-factor authentication
 func RequireAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "authentication_required",
+				Message: "Authentication required",
+			})
 			c.Abort()
 			return
 		}
 
 		userObj := user.(*models.User)
 		if !userObj.IsAdmin() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "admin_required",
+				Message: "Admin access required",
+			})
+			c.Abort()
+			return
+		}
+
+		if !userObj.IsTOTPEnabled() {
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "mfa_setup_required",
+				Message: "Two-factor authentication must be enabled for admin access",
+			})
+			c.Abort()
+			return
+		}
+
+		if !c.GetBool("mfa") {
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "mfa_required",
+				Message: "Log in again with two-factor authentication for admin access",
+			})
 			c.Abort()
 			return
 		}
@@ -115,14 +265,20 @@ func RequirePlayer() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "authentication_required",
+				Message: "Authentication required",
+			})
 			c.Abort()
 			return
 		}
 
 		userObj := user.(*models.User)
 		if !userObj.IsPlayer() && !userObj.IsAdmin() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Player access required"})
+			c.JSON(http.StatusForbidden, views.ErrorResponse{
+				Error:   "player_required",
+				Message: "Player access required",
+			})
 			c.Abort()
 			return
 		}
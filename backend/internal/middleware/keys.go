@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"badminton-backend/internal/config"
+)
+
+// SigningKey is a JWT key identified by its kid. Retired keys keep only a
+// VerifyKey so tokens they signed stay valid until they expire.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{} // []byte, *rsa.PrivateKey or ed25519.PrivateKey; nil for verify-only keys
+	VerifyKey interface{} // []byte, *rsa.PublicKey or ed25519.PublicKey
+}
+
+// TokenConfig configures how access tokens are signed and verified
+type TokenConfig struct {
+	Keys       []SigningKey
+	ActiveKID  string // key used to sign new tokens
+	TTL        time.Duration
+	RefreshTTL time.Duration
+	Issuer     string
+}
+
+// tokenConfig is the configuration used by GenerateToken and AuthMiddleware
+var tokenConfig TokenConfig
+
+// ConfigureTokens validates and installs the token configuration
+func ConfigureTokens(cfg TokenConfig) error {
+	if len(cfg.Keys) == 0 {
+		return errors.New("at least one JWT key is required")
+	}
+	if cfg.ActiveKID == "" {
+		cfg.ActiveKID = cfg.Keys[0].ID
+	}
+	if cfg.TTL <= 0 || cfg.RefreshTTL <= 0 {
+		return errors.New("JWT token lifetimes must be positive")
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range cfg.Keys {
+		if key.ID == "" {
+			return errors.New("JWT keys must have a kid")
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("duplicate JWT kid %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	active, ok := findKey(cfg.Keys, cfg.ActiveKID)
+	if !ok {
+		return fmt.Errorf("active JWT kid %q is not configured", cfg.ActiveKID)
+	}
+	if active.SignKey == nil {
+		return fmt.Errorf("active JWT kid %q has no private key", cfg.ActiveKID)
+	}
+
+	tokenConfig = cfg
+	return nil
+}
+
+// LoadTokenConfig builds a token configuration from the loaded settings:
+// a single HS256 secret (kid "default") and any kid=alg:path key entries,
+// e.g. "2024a=HS256:/run/secrets/jwt-2024a". HS256 files hold the secret;
+// RS256/EdDSA files hold a PEM private key, or a public key for a retired
+// verify-only key.
+//
+// Without any keys an ephemeral random secret is generated; the
+// configuration refuses that in production.
+func LoadTokenConfig(settings config.JWT) (TokenConfig, error) {
+	cfg := TokenConfig{
+		ActiveKID:  settings.ActiveKID,
+		TTL:        settings.TTL,
+		RefreshTTL: settings.RefreshTTL,
+		Issuer:     settings.Issuer,
+	}
+
+	if settings.Secret != "" {
+		cfg.Keys = append(cfg.Keys, SigningKey{
+			ID:        "default",
+			Method:    jwt.SigningMethodHS256,
+			SignKey:   []byte(settings.Secret),
+			VerifyKey: []byte(settings.Secret),
+		})
+	}
+
+	for _, entry := range settings.Keys {
+		key, err := parseKeySpec(entry)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Keys = append(cfg.Keys, key)
+	}
+
+	if len(cfg.Keys) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return cfg, err
+		}
+		log.Println("WARNING: no JWT keys configured, using an ephemeral secret; tokens will not survive a restart")
+		cfg.Keys = append(cfg.Keys, SigningKey{
+			ID:        "ephemeral",
+			Method:    jwt.SigningMethodHS256,
+			SignKey:   secret,
+			VerifyKey: secret,
+		})
+	}
+
+	return cfg, nil
+}
+
+// parseKeySpec loads a single kid=alg:path key entry
+func parseKeySpec(entry string) (SigningKey, error) {
+	spec, err := config.ParseKeySpec(entry)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	data, err := os.ReadFile(spec.Path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("reading JWT key %q: %w", spec.ID, err)
+	}
+
+	return ParseSigningKey(spec.ID, spec.Algorithm, data)
+}
+
+// ParseSigningKey builds a key from its algorithm and material: the raw
+// secret for HS256, or a PEM private or public key for RS256 and EdDSA
+func ParseSigningKey(kid, alg string, data []byte) (SigningKey, error) {
+	key := SigningKey{ID: kid}
+
+	switch alg {
+	case "HS256":
+		secret, err := config.HMACSecret(kid, data)
+		if err != nil {
+			return key, err
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.SignKey, key.VerifyKey = secret, secret
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		if strings.Contains(string(data), "PRIVATE KEY") {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return key, fmt.Errorf("JWT key %q: %w", kid, err)
+			}
+			key.SignKey, key.VerifyKey = private, &private.PublicKey
+		} else {
+			public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return key, fmt.Errorf("JWT key %q: %w", kid, err)
+			}
+			key.VerifyKey = public
+		}
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		if strings.Contains(string(data), "PRIVATE KEY") {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return key, fmt.Errorf("JWT key %q: %w", kid, err)
+			}
+			key.SignKey, key.VerifyKey = private, private.(ed25519.PrivateKey).Public()
+		} else {
+			public, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return key, fmt.Errorf("JWT key %q: %w", kid, err)
+			}
+			key.VerifyKey = public
+		}
+	default:
+		return key, fmt.Errorf("JWT key %q: unsupported algorithm %q (use HS256, RS256 or EdDSA)", kid, alg)
+	}
+
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set document
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the configured asymmetric verification keys so other
+// services can verify our tokens. Shared HS256 secrets are never published.
+func PublicJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range tokenConfig.Keys {
+		switch public := key.VerifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+// findKey looks up a key by kid
+func findKey(keys []SigningKey, kid string) (SigningKey, bool) {
+	for _, key := range keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// verificationKey is the jwt.Keyfunc that selects the key named by the
+// token's kid header and refuses tokens signed with a different algorithm
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := findKey(tokenConfig.Keys, kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), kid)
+	}
+	return key.VerifyKey, nil
+}
+
+// AccessTokenTTL returns the configured access token lifetime
+func AccessTokenTTL() time.Duration {
+	return tokenConfig.TTL
+}
+
+// RefreshTokenTTL returns the configured refresh token lifetime
+func RefreshTokenTTL() time.Duration {
+	return tokenConfig.RefreshTTL
+}
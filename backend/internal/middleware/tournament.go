@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/views"
+)
+
+var errInvalidResourceID = errors.New("invalid resource id")
+
+// TournamentResolver finds the tournament a request acts on. A zero ID
+// means the resource does not belong to a tournament.
+type TournamentResolver func(c *gin.Context, db *gorm.DB) (uint, error)
+
+// TournamentFromParam resolves the tournament from a tournament ID path parameter
+func TournamentFromParam(param string) TournamentResolver {
+	return func(c *gin.Context, db *gorm.DB) (uint, error) {
+		id, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			return 0, errInvalidResourceID
+		}
+
+		var tournament models.Tournament
+		if err := db.Select("id").First(&tournament, uint(id)).Error; err != nil {
+			return 0, err
+		}
+		return tournament.ID, nil
+	}
+}
+
+// TournamentFromMatch resolves the tournament from a match ID path parameter
+func TournamentFromMatch(param string) TournamentResolver {
+	return func(c *gin.Context, db *gorm.DB) (uint, error) {
+		id, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			return 0, errInvalidResourceID
+		}
+
+		var match models.Match
+		if err := db.Select("id", "tournament_id").First(&match, uint(id)).Error; err != nil {
+			return 0, err
+		}
+		if match.TournamentID == nil {
+			return 0, nil
+		}
+		return *match.TournamentID, nil
+	}
+}
+
+// RequireTournamentPermission middleware requires the user to hold a staff
+// role granting the permission in the tournament the request acts on.
+// Requests on resources outside any tournament are passed through for the
+// handler to authorize.
+func RequireTournamentPermission(db *gorm.DB, permission models.TournamentPermission, resolve TournamentResolver) gin.HandlerFunc {
+	pol := policy.New(db)
+
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, views.ErrorResponse{
+				Error:   "authentication_required",
+				Message: "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+		actor := policy.Actor{User: user.(*models.User), MFA: c.GetBool("mfa")}
+
+		tournamentID, err := resolve(c, db)
+		if err != nil {
+			switch {
+			case errors.Is(err, errInvalidResourceID):
+				c.JSON(http.StatusBadRequest, views.ErrorResponse{
+					Error:   "invalid_id",
+					Message: "Invalid ID",
+				})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, views.ErrorResponse{
+					Error:   "not_found",
+					Message: "Not found",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+					Error:   "database_error",
+					Message: "Failed to check tournament permissions",
+				})
+			}
+			c.Abort()
+			return
+		}
+		if tournamentID == 0 {
+			c.Next()
+			return
+		}
+
+		if err := pol.Tournament(actor, tournamentID, permission); err != nil {
+			var denied *policy.Denied
+			if errors.As(err, &denied) {
+				c.JSON(http.StatusForbidden, views.ErrorResponse{
+					Error:   denied.Code,
+					Message: denied.Reason,
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, views.ErrorResponse{
+					Error:   "database_error",
+					Message: "Failed to check tournament permissions",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("tournament_id", tournamentID)
+		c.Next()
+	}
+}
@@ -0,0 +1,382 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// initialSchema creates the schema as AutoMigrate left it before versioned
+// migrations. Databases created that way already have these tables, so it
+// only adds what is missing there, and carries over the data fixes that
+// used to run on every start.
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		// Users who signed up before email verification existed are trusted
+		grandfatherEmails := !tx.Migrator().HasColumn(&userV1{}, "email_verified_at")
+
+		if err := tx.AutoMigrate(initialSchemaTables...); err != nil {
+			return err
+		}
+
+		if grandfatherEmails {
+			if err := tx.Model(&userV1{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+				return err
+			}
+		}
+
+		// Tournaments created before the lifecycle state machine used
+		// "upcoming" for the registration phase
+		return tx.Model(&tournamentV1{}).Where("status = ?", "upcoming").Update("status", "registration_open").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		for i := len(initialSchemaTables) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(initialSchemaTables[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// initialSchemaTables are in creation order, referenced tables first
+var initialSchemaTables = []interface{}{
+	&userV1{},
+	&playerV1{},
+	&teamV1{},
+	&teamPlayerV1{},
+	&tournamentV1{},
+	&tournamentPlayerV1{},
+	&tournamentTeamV1{},
+	&matchV1{},
+	&notificationV1{},
+	&refundV1{},
+	&sessionV1{},
+	&userTokenV1{},
+	&loginThrottleV1{},
+	&auditLogV1{},
+	&recoveryCodeV1{},
+	&userIdentityV1{},
+	&oidcAuthRequestV1{},
+	&tournamentStaffV1{},
+	&matchResultV1{},
+	&apiKeyV1{},
+}
+
+// The types below freeze the models as they were at this version. Column,
+// index and constraint names come from the field names and tags, so those
+// must match the models of the time exactly. gorm.Model has the same
+// columns as models.BaseModel.
+
+type userV1 struct {
+	gorm.Model
+	Username        string `gorm:"unique;not null"`
+	Email           string `gorm:"unique;not null"`
+	Password        string `gorm:"not null"`
+	FullName        string `gorm:"not null"`
+	Role            string `gorm:"default:'player'"`
+	IsActive        bool   `gorm:"default:true"`
+	EmailVerifiedAt *time.Time
+	TOTPSecret      string
+	TOTPEnabledAt   *time.Time
+	TOTPLastStep    int64
+	Ranking         int `gorm:"default:0"`
+
+	PlayerTeams      []teamPlayerV1       `gorm:"foreignKey:PlayerID"`
+	AdminTournaments []tournamentV1       `gorm:"foreignKey:AdminID"`
+	Registrations    []tournamentPlayerV1 `gorm:"foreignKey:PlayerID"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type playerV1 struct {
+	gorm.Model
+	Name    string `gorm:"not null"`
+	Email   string `gorm:"unique;not null"`
+	Ranking int    `gorm:"default:0"`
+}
+
+func (playerV1) TableName() string { return "players" }
+
+type teamV1 struct {
+	gorm.Model
+	Name        string `gorm:"not null"`
+	Description string
+
+	Players     []teamPlayerV1     `gorm:"foreignKey:TeamID"`
+	Tournaments []tournamentTeamV1 `gorm:"foreignKey:TeamID"`
+}
+
+func (teamV1) TableName() string { return "teams" }
+
+type teamPlayerV1 struct {
+	gorm.Model
+	TeamID   uint   `gorm:"not null"`
+	PlayerID uint   `gorm:"not null"`
+	Role     string `gorm:"default:'player'"`
+
+	Team   teamV1 `gorm:"foreignKey:TeamID"`
+	Player userV1 `gorm:"foreignKey:PlayerID"`
+}
+
+func (teamPlayerV1) TableName() string { return "team_players" }
+
+type tournamentV1 struct {
+	gorm.Model
+	Name                 string `gorm:"not null"`
+	Description          string
+	Type                 string `gorm:"default:'singles'"`
+	StartDate            time.Time
+	EndDate              time.Time
+	Status               string  `gorm:"default:'draft'"`
+	MaxPlayers           int     `gorm:"default:16"`
+	MaxTeams             int     `gorm:"default:8"`
+	EntryFee             float64 `gorm:"default:0"`
+	PrizePool            float64 `gorm:"default:0"`
+	AdminID              uint    `gorm:"not null"`
+	CheckInWindowMinutes int     `gorm:"default:60"`
+	CheckInClosedAt      *time.Time
+
+	Admin   userV1               `gorm:"foreignKey:AdminID"`
+	Matches []matchV1            `gorm:"foreignKey:TournamentID"`
+	Players []tournamentPlayerV1 `gorm:"foreignKey:TournamentID"`
+	Teams   []tournamentTeamV1   `gorm:"foreignKey:TournamentID"`
+}
+
+func (tournamentV1) TableName() string { return "tournaments" }
+
+type tournamentPlayerV1 struct {
+	gorm.Model
+	TournamentID uint   `gorm:"not null"`
+	PlayerID     uint   `gorm:"not null"`
+	Status       string `gorm:"default:'registered'"`
+	CheckedInAt  *time.Time
+
+	Tournament tournamentV1 `gorm:"foreignKey:TournamentID"`
+	Player     userV1       `gorm:"foreignKey:PlayerID"`
+}
+
+func (tournamentPlayerV1) TableName() string { return "tournament_players" }
+
+type tournamentTeamV1 struct {
+	gorm.Model
+	TournamentID uint   `gorm:"not null"`
+	TeamID       uint   `gorm:"not null"`
+	Status       string `gorm:"default:'registered'"`
+	CheckedInAt  *time.Time
+
+	Tournament tournamentV1 `gorm:"foreignKey:TournamentID"`
+	Team       teamV1       `gorm:"foreignKey:TeamID"`
+}
+
+func (tournamentTeamV1) TableName() string { return "tournament_teams" }
+
+type matchV1 struct {
+	gorm.Model
+	TournamentID   *uint
+	Type           string `gorm:"default:'singles'"`
+	Status         string `gorm:"default:'pending'"`
+	MatchDate      time.Time
+	Round          string
+	Player1ID      *uint
+	Player2ID      *uint
+	Player1Score   int `gorm:"default:0"`
+	Player2Score   int `gorm:"default:0"`
+	Team1ID        *uint
+	Team2ID        *uint
+	Team1Score     int `gorm:"default:0"`
+	Team2Score     int `gorm:"default:0"`
+	WinnerPlayerID *uint
+	WinnerTeamID   *uint
+	CreatedByID    *uint
+
+	Tournament   *tournamentV1 `gorm:"foreignKey:TournamentID"`
+	Player1      *userV1       `gorm:"foreignKey:Player1ID"`
+	Player2      *userV1       `gorm:"foreignKey:Player2ID"`
+	Team1        *teamV1       `gorm:"foreignKey:Team1ID"`
+	Team2        *teamV1       `gorm:"foreignKey:Team2ID"`
+	WinnerPlayer *userV1       `gorm:"foreignKey:WinnerPlayerID"`
+	WinnerTeam   *teamV1       `gorm:"foreignKey:WinnerTeamID"`
+}
+
+func (matchV1) TableName() string { return "matches" }
+
+type notificationV1 struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;index"`
+	Type         string `gorm:"not null"`
+	Title        string `gorm:"not null"`
+	Message      string
+	TournamentID *uint
+	MatchID      *uint
+	ReadAt       *time.Time
+
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (notificationV1) TableName() string { return "notifications" }
+
+type refundV1 struct {
+	gorm.Model
+	TournamentID  uint `gorm:"not null;index"`
+	UserID        uint `gorm:"not null;index"`
+	TeamID        *uint
+	Amount        float64 `gorm:"not null"`
+	Reason        string
+	Status        string `gorm:"default:'pending'"`
+	ProcessedAt   *time.Time
+	ProcessedByID *uint
+
+	Tournament tournamentV1 `gorm:"foreignKey:TournamentID"`
+	User       userV1       `gorm:"foreignKey:UserID"`
+}
+
+func (refundV1) TableName() string { return "refunds" }
+
+type sessionV1 struct {
+	gorm.Model
+	UserID            uint   `gorm:"not null;index"`
+	RefreshTokenHash  string `gorm:"uniqueIndex;not null"`
+	PreviousTokenHash string `gorm:"index"`
+	ExpiresAt         time.Time
+	LastRefreshedAt   *time.Time
+	RevokedAt         *time.Time
+	UserAgent         string
+	IPAddress         string
+	MFA               bool `gorm:"default:false"`
+
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (sessionV1) TableName() string { return "sessions" }
+
+type userTokenV1 struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	Purpose   string `gorm:"not null"`
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (userTokenV1) TableName() string { return "user_tokens" }
+
+type loginThrottleV1 struct {
+	gorm.Model
+	Key           string `gorm:"uniqueIndex;not null"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	BlockedUntil  *time.Time
+	LockedOut     bool `gorm:"default:false"`
+}
+
+func (loginThrottleV1) TableName() string { return "login_throttles" }
+
+type auditLogV1 struct {
+	gorm.Model
+	ActorID    *uint  `gorm:"index"`
+	Action     string `gorm:"not null;index"`
+	TargetType string `gorm:"index:idx_audit_target"`
+	TargetID   *uint  `gorm:"index:idx_audit_target"`
+	IPAddress  string
+	Details    string
+	Changes    string
+	Method     string
+	Path       string
+	UserAgent  string
+	SessionID  *uint
+	APIKeyID   *uint
+}
+
+func (auditLogV1) TableName() string { return "audit_logs" }
+
+type recoveryCodeV1 struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"not null;index"`
+	UsedAt   *time.Time
+}
+
+func (recoveryCodeV1) TableName() string { return "recovery_codes" }
+
+type userIdentityV1 struct {
+	gorm.Model
+	UserID  uint   `gorm:"not null;index"`
+	Issuer  string `gorm:"not null;uniqueIndex:idx_identity_subject"`
+	Subject string `gorm:"not null;uniqueIndex:idx_identity_subject"`
+	Email   string
+
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (userIdentityV1) TableName() string { return "user_identities" }
+
+type oidcAuthRequestV1 struct {
+	gorm.Model
+	StateHash    string `gorm:"uniqueIndex;not null"`
+	Nonce        string `gorm:"not null"`
+	CodeVerifier string `gorm:"not null"`
+	LinkUserID   *uint
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+}
+
+// The name is what GORM derived from OIDCAuthRequest
+func (oidcAuthRequestV1) TableName() string { return "o_id_c_auth_requests" }
+
+type tournamentStaffV1 struct {
+	gorm.Model
+	TournamentID uint   `gorm:"not null;uniqueIndex:idx_tournament_staff_role"`
+	UserID       uint   `gorm:"not null;uniqueIndex:idx_tournament_staff_role;index"`
+	Role         string `gorm:"not null;uniqueIndex:idx_tournament_staff_role"`
+	AssignedByID uint
+
+	Tournament tournamentV1 `gorm:"foreignKey:TournamentID"`
+	User       userV1       `gorm:"foreignKey:UserID"`
+}
+
+func (tournamentStaffV1) TableName() string { return "tournament_staffs" }
+
+type matchResultV1 struct {
+	gorm.Model
+	MatchID        uint `gorm:"not null;index"`
+	ReportedByID   uint `gorm:"not null"`
+	ReporterSide   int  `gorm:"not null"`
+	Side1Score     int
+	Side2Score     int
+	Status         string `gorm:"not null;default:'pending';index"`
+	RespondedByID  *uint
+	RespondedAt    *time.Time
+	DisputeReason  string
+	ResolvedByID   *uint
+	ResolvedAt     *time.Time
+	ResolutionNote string
+
+	Match      matchV1 `gorm:"foreignKey:MatchID"`
+	ReportedBy userV1  `gorm:"foreignKey:ReportedByID"`
+}
+
+func (matchResultV1) TableName() string { return "match_results" }
+
+type apiKeyV1 struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Name        string `gorm:"not null"`
+	Prefix      string `gorm:"not null"`
+	KeyHash     string `gorm:"uniqueIndex;not null"`
+	Scope       string `gorm:"not null"`
+	CreatedByID uint
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	LastUsedIP  string
+	RevokedAt   *time.Time
+
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (apiKeyV1) TableName() string { return "api_keys" }
@@ -0,0 +1,243 @@
+package migrations
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// legacyPlayers turns every legacy player into a player account and drops
+// the players table. Players whose email already has an account, in any
+// letter case, are merged into it; so are players sharing an email. New
+// accounts get a generated username and an unknown password, and are left
+// pending until their owner claims them with an emailed invite.
+var legacyPlayers = Migration{
+	Version: 2,
+	Name:    "legacy_players_to_users",
+	Up: func(tx *gorm.DB) error {
+		for _, column := range []string{"LegacyPlayerID", "ClaimPending"} {
+			if !tx.Migrator().HasColumn(&userV2{}, column) {
+				if err := tx.Migrator().AddColumn(&userV2{}, column); err != nil {
+					return err
+				}
+			}
+		}
+		if !tx.Migrator().HasIndex(&userV2{}, "LegacyPlayerID") {
+			if err := tx.Migrator().CreateIndex(&userV2{}, "LegacyPlayerID"); err != nil {
+				return err
+			}
+		}
+
+		if !tx.Migrator().HasTable(&playerV2{}) {
+			return nil
+		}
+
+		var players []playerV2
+		if err := tx.Order("id").Find(&players).Error; err != nil {
+			return err
+		}
+
+		var emails []string
+		byEmail := make(map[string][]playerV2)
+		for _, player := range players {
+			email := strings.ToLower(strings.TrimSpace(player.Email))
+			if _, seen := byEmail[email]; !seen {
+				emails = append(emails, email)
+			}
+			byEmail[email] = append(byEmail[email], player)
+		}
+
+		usernames := newUsernameAllocator(tx)
+		for _, email := range emails {
+			if err := convertPlayers(tx, email, byEmail[email], usernames); err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().DropTable(&playerV2{})
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&playerV2{}); err != nil {
+			return err
+		}
+
+		// Restore a player for every converted email; the accounts stay
+		var users []userV2
+		if err := tx.Unscoped().Where("legacy_player_id IS NOT NULL").Order("legacy_player_id").Find(&users).Error; err != nil {
+			return err
+		}
+		for _, user := range users {
+			player := playerV2{Name: user.FullName, Email: user.Email, Ranking: user.Ranking}
+			player.ID = *user.LegacyPlayerID
+			if err := tx.Create(&player).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Migrator().DropIndex(&userV2{}, "LegacyPlayerID"); err != nil {
+			return err
+		}
+		// Dropped in place: rebuilding the referenced users table breaks
+		// foreign keys on SQLite
+		for _, column := range []string{"legacy_player_id", "claim_pending"} {
+			if err := tx.Exec("ALTER TABLE users DROP COLUMN " + column).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// convertPlayers merges the players sharing an email into the account with
+// that email, creating the account if there is none
+func convertPlayers(tx *gorm.DB, email string, players []playerV2, usernames *usernameAllocator) error {
+	first := players[0]
+	name := ""
+	ranking := 0
+	for _, player := range players {
+		if name == "" {
+			name = strings.TrimSpace(player.Name)
+		}
+		// 1 is the best ranking and 0 means unranked
+		if player.Ranking > 0 && (ranking == 0 || player.Ranking < ranking) {
+			ranking = player.Ranking
+		}
+	}
+
+	var user userV2
+	result := tx.Unscoped().Where("LOWER(email) = ?", email).Limit(1).Find(&user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if user.DeletedAt.Valid {
+			log.Printf("Legacy player %d not converted: the account for %s has been deleted", first.ID, first.Email)
+			return nil
+		}
+		updates := map[string]interface{}{}
+		if user.LegacyPlayerID == nil {
+			updates["legacy_player_id"] = first.ID
+		}
+		if user.Ranking == 0 && ranking > 0 {
+			updates["ranking"] = ranking
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&user).Updates(updates).Error
+	}
+
+	username, err := usernames.next(email)
+	if err != nil {
+		return err
+	}
+	password, err := placeholderPassword()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = username
+	}
+
+	legacyID := first.ID
+	user = userV2{
+		Username:       username,
+		Email:          strings.TrimSpace(first.Email),
+		Password:       password,
+		FullName:       name,
+		Role:           "player",
+		IsActive:       true,
+		Ranking:        ranking,
+		LegacyPlayerID: &legacyID,
+		ClaimPending:   true,
+	}
+	user.CreatedAt = first.CreatedAt
+	return tx.Create(&user).Error
+}
+
+// placeholderPassword hashes a random password nobody knows, so the
+// account cannot be logged into until it is claimed
+func placeholderPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(base64.RawURLEncoding.EncodeToString(b)), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// usernameAllocator hands out usernames derived from emails that are not
+// taken by existing accounts or earlier conversions
+type usernameAllocator struct {
+	tx    *gorm.DB
+	taken map[string]bool
+}
+
+func newUsernameAllocator(tx *gorm.DB) *usernameAllocator {
+	return &usernameAllocator{tx: tx, taken: make(map[string]bool)}
+}
+
+func (a *usernameAllocator) next(email string) (string, error) {
+	base := email
+	if at := strings.Index(base, "@"); at >= 0 {
+		base = base[:at]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(base, ""), "._-")
+	if len(base) < 3 {
+		base = "player"
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = base + strconv.Itoa(n)
+		}
+		if a.taken[candidate] {
+			continue
+		}
+		var count int64
+		if err := a.tx.Unscoped().Model(&userV2{}).Where("LOWER(username) = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			a.taken[candidate] = true
+			return candidate, nil
+		}
+	}
+}
+
+type playerV2 struct {
+	gorm.Model
+	Name    string `gorm:"not null"`
+	Email   string `gorm:"unique;not null"`
+	Ranking int    `gorm:"default:0"`
+}
+
+func (playerV2) TableName() string { return "players" }
+
+// userV2 has the user columns this migration reads and writes
+type userV2 struct {
+	gorm.Model
+	Username       string
+	Email          string
+	Password       string
+	FullName       string
+	Role           string
+	IsActive       bool
+	Ranking        int
+	LegacyPlayerID *uint `gorm:"uniqueIndex"`
+	ClaimPending   bool  `gorm:"default:false"`
+}
+
+func (userV2) TableName() string { return "users" }
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// registrationEntries are the registration tables and the column naming
+// the entrant, one registration per entrant and tournament
+var registrationEntries = []struct {
+	table, entrant, index string
+}{
+	{"tournament_players", "player_id", "idx_tournament_players_entrant"},
+	{"tournament_teams", "team_id", "idx_tournament_teams_entrant"},
+}
+
+// uniqueRegistrations enforces one live registration per player or team and
+// tournament. Duplicates left by concurrent registrations are soft-deleted
+// first, keeping the earliest that was not withdrawn, or the earliest
+// withdrawal when all were, so an entrant who withdrew and registered again
+// keeps their place.
+var uniqueRegistrations = Migration{
+	Version: 3,
+	Name:    "unique_registrations",
+	Up: func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, entry := range registrationEntries {
+			result := tx.Exec(`UPDATE `+entry.table+` SET deleted_at = ?
+				WHERE deleted_at IS NULL AND EXISTS (
+					SELECT 1 FROM `+entry.table+` kept
+					WHERE kept.tournament_id = `+entry.table+`.tournament_id
+					AND kept.`+entry.entrant+` = `+entry.table+`.`+entry.entrant+`
+					AND kept.deleted_at IS NULL
+					AND kept.id <> `+entry.table+`.id
+					AND (
+						(kept.status <> ? AND `+entry.table+`.status = ?)
+						OR ((kept.status = ?) = (`+entry.table+`.status = ?) AND kept.id < `+entry.table+`.id)
+					)
+				)`, now, "withdrawn", "withdrawn", "withdrawn", "withdrawn")
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("Removed %d duplicate registrations from %s", result.RowsAffected, entry.table)
+			}
+
+			// Partial so withdrawn history can be soft-deleted without
+			// blocking a new registration
+			if err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS ` + entry.index + ` ON ` + entry.table +
+				` (tournament_id, ` + entry.entrant + `) WHERE deleted_at IS NULL`).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		for _, entry := range registrationEntries {
+			if err := tx.Exec(`DROP INDEX IF EXISTS ` + entry.index).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
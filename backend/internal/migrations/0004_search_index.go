@@ -0,0 +1,158 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// searchIndex adds full-text search over player names, team names and
+// tournament names and descriptions, ignoring accents. The database keeps
+// the index current itself: triggers feeding an FTS5 table on SQLite, and
+// generated tsvector columns on PostgreSQL.
+var searchIndex = Migration{
+	Version: 4,
+	Name:    "search_index",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			return execAll(tx, postgresSearchUp)
+		}
+
+		var fts5 int
+		if err := tx.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5).Error; err != nil {
+			return err
+		}
+		if fts5 == 0 {
+			log.Println("SQLite was built without FTS5, skipping the search index; rebuild with -tags sqlite_fts5 and revert and reapply this migration to enable search")
+			return nil
+		}
+		return execAll(tx, sqliteSearchUp)
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			return execAll(tx, postgresSearchDown)
+		}
+		return execAll(tx, sqliteSearchDown)
+	},
+}
+
+func execAll(tx *gorm.DB, statements []string) error {
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The SQLite index holds one row per record, its rowid derived from the
+// record's ID so triggers can replace it directly. The unicode61 tokenizer
+// folds case and accents except đ, which has no Unicode decomposition.
+var sqliteSearchUp = []string{
+	`CREATE VIRTUAL TABLE search_index USING fts5(
+		kind UNINDEXED, ref_id UNINDEXED, title, body,
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`,
+
+	`CREATE TRIGGER search_users_insert AFTER INSERT ON users BEGIN
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 1, 'player', new.id, ` + sqliteFold("new.full_name") + `, ` + sqliteFold("new.username") + `);
+	END`,
+	`CREATE TRIGGER search_users_update AFTER UPDATE OF full_name, username ON users BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 1;
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 1, 'player', new.id, ` + sqliteFold("new.full_name") + `, ` + sqliteFold("new.username") + `);
+	END`,
+	`CREATE TRIGGER search_users_delete AFTER DELETE ON users BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 1;
+	END`,
+
+	`CREATE TRIGGER search_teams_insert AFTER INSERT ON teams BEGIN
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 2, 'team', new.id, ` + sqliteFold("new.name") + `, ` + sqliteFold("new.description") + `);
+	END`,
+	`CREATE TRIGGER search_teams_update AFTER UPDATE OF name, description ON teams BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 2;
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 2, 'team', new.id, ` + sqliteFold("new.name") + `, ` + sqliteFold("new.description") + `);
+	END`,
+	`CREATE TRIGGER search_teams_delete AFTER DELETE ON teams BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 2;
+	END`,
+
+	`CREATE TRIGGER search_tournaments_insert AFTER INSERT ON tournaments BEGIN
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 3, 'tournament', new.id, ` + sqliteFold("new.name") + `, ` + sqliteFold("new.description") + `);
+	END`,
+	`CREATE TRIGGER search_tournaments_update AFTER UPDATE OF name, description ON tournaments BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 3;
+		INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		VALUES (new.id * 4 + 3, 'tournament', new.id, ` + sqliteFold("new.name") + `, ` + sqliteFold("new.description") + `);
+	END`,
+	`CREATE TRIGGER search_tournaments_delete AFTER DELETE ON tournaments BEGIN
+		DELETE FROM search_index WHERE rowid = old.id * 4 + 3;
+	END`,
+
+	`INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		SELECT id * 4 + 1, 'player', id, ` + sqliteFold("full_name") + `, ` + sqliteFold("username") + ` FROM users`,
+	`INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		SELECT id * 4 + 2, 'team', id, ` + sqliteFold("name") + `, ` + sqliteFold("description") + ` FROM teams`,
+	`INSERT INTO search_index (rowid, kind, ref_id, title, body)
+		SELECT id * 4 + 3, 'tournament', id, ` + sqliteFold("name") + `, ` + sqliteFold("description") + ` FROM tournaments`,
+}
+
+var sqliteSearchDown = []string{
+	`DROP TRIGGER IF EXISTS search_users_insert`,
+	`DROP TRIGGER IF EXISTS search_users_update`,
+	`DROP TRIGGER IF EXISTS search_users_delete`,
+	`DROP TRIGGER IF EXISTS search_teams_insert`,
+	`DROP TRIGGER IF EXISTS search_teams_update`,
+	`DROP TRIGGER IF EXISTS search_teams_delete`,
+	`DROP TRIGGER IF EXISTS search_tournaments_insert`,
+	`DROP TRIGGER IF EXISTS search_tournaments_update`,
+	`DROP TRIGGER IF EXISTS search_tournaments_delete`,
+	`DROP TABLE IF EXISTS search_index`,
+}
+
+func sqliteFold(column string) string {
+	return "replace(replace(coalesce(" + column + ", ''), 'đ', 'd'), 'Đ', 'D')"
+}
+
+// Vietnamese letters and their plain equivalents for PostgreSQL's
+// translate, which unlike unaccent may be used in generated columns
+const (
+	vietnameseLetters = "àáảãạăằắẳẵặâầấẩẫậèéẻẽẹêềếểễệìíỉĩịòóỏõọôồốổỗộơờớởỡợùúủũụưừứửữựỳýỷỹỵđ" +
+		"ÀÁẢÃẠĂẰẮẲẴẶÂẦẤẨẪẬÈÉẺẼẸÊỀẾỂỄỆÌÍỈĨỊÒÓỎÕỌÔỒỐỔỖỘƠỜỚỞỠỢÙÚỦŨỤƯỪỨỬỮỰỲÝỶỸỴĐ"
+	plainLetters = "aaaaaaaaaaaaaaaaaeeeeeeeeeeeiiiiiooooooooooooooooouuuuuuuuuuuyyyyyd" +
+		"aaaaaaaaaaaaaaaaaeeeeeeeeeeeiiiiiooooooooooooooooouuuuuuuuuuuyyyyyd"
+)
+
+func postgresVector(weighted ...[2]string) string {
+	vector := ""
+	for i, column := range weighted {
+		if i > 0 {
+			vector += " || "
+		}
+		vector += "setweight(to_tsvector('simple', lower(translate(coalesce(" + column[0] + ", ''), '" +
+			vietnameseLetters + "', '" + plainLetters + "'))), '" + column[1] + "')"
+	}
+	return vector
+}
+
+var postgresSearchUp = []string{
+	`ALTER TABLE users ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (` +
+		postgresVector([2]string{"full_name", "A"}, [2]string{"username", "B"}) + `) STORED`,
+	`ALTER TABLE teams ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (` +
+		postgresVector([2]string{"name", "A"}, [2]string{"description", "B"}) + `) STORED`,
+	`ALTER TABLE tournaments ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (` +
+		postgresVector([2]string{"name", "A"}, [2]string{"description", "B"}) + `) STORED`,
+	`CREATE INDEX idx_users_search ON users USING GIN (search_vector)`,
+	`CREATE INDEX idx_teams_search ON teams USING GIN (search_vector)`,
+	`CREATE INDEX idx_tournaments_search ON tournaments USING GIN (search_vector)`,
+}
+
+var postgresSearchDown = []string{
+	`ALTER TABLE users DROP COLUMN IF EXISTS search_vector`,
+	`ALTER TABLE teams DROP COLUMN IF EXISTS search_vector`,
+	`ALTER TABLE tournaments DROP COLUMN IF EXISTS search_vector`,
+}
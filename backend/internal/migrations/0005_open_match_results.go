@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openMatchResults allows one open (pending or disputed) result per match.
+// Duplicates left by concurrent reports are soft-deleted first, keeping the
+// earliest.
+var openMatchResults = Migration{
+	Version: 5,
+	Name:    "open_match_results",
+	Up: func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE match_results SET deleted_at = ?
+			WHERE deleted_at IS NULL AND status IN ('pending', 'disputed') AND EXISTS (
+				SELECT 1 FROM match_results earlier
+				WHERE earlier.match_id = match_results.match_id
+				AND earlier.status IN ('pending', 'disputed')
+				AND earlier.deleted_at IS NULL
+				AND earlier.id < match_results.id
+			)`, time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Removed %d duplicate open match results", result.RowsAffected)
+		}
+
+		return tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_match_results_open ON match_results (match_id)
+			WHERE status IN ('pending', 'disputed') AND deleted_at IS NULL`).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Exec(`DROP INDEX IF EXISTS idx_match_results_open`).Error
+	},
+}
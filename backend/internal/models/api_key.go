@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// APIKeyScope limits what an API key can do
+type APIKeyScope string
+
+const (
+	APIKeyRead  APIKeyScope = "read"  // GET requests only
+	APIKeyScore APIKeyScope = "score" // read plus entering match results
+	APIKeyAdmin APIKeyScope = "admin" // everything the owner can do, for admins only
+)
+
+// IsValid checks if the scope is a known API key scope
+func (s APIKeyScope) IsValid() bool {
+	return s == APIKeyRead || s == APIKeyScore || s == APIKeyAdmin
+}
+
+// APIKey is a long-lived credential for scripts acting as a user. Only a
+// hash of the key is stored; the prefix identifies it in listings.
+type APIKey struct {
+	BaseModel
+	UserID      uint        `json:"user_id" gorm:"not null;index"`
+	Name        string      `json:"name" gorm:"not null"`
+	Prefix      string      `json:"prefix" gorm:"not null"`
+	KeyHash     string      `json:"-" gorm:"uniqueIndex;not null"`
+	Scope       APIKeyScope `json:"scope" gorm:"not null"`
+	CreatedByID uint        `json:"created_by_id"` // differs from UserID when an admin created the key
+	ExpiresAt   *time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time  `json:"last_used_at"`
+	LastUsedIP  string      `json:"last_used_ip"`
+	RevokedAt   *time.Time  `json:"revoked_at"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsActive checks if the key can still be used at the given time
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package models
+
+// AuditLog records a security-relevant event or a change made through the API
+type AuditLog struct {
+	BaseModel
+	ActorID    *uint  `json:"actor_id" gorm:"index"`                     // nil for system or anonymous events
+	Action     string `json:"action" gorm:"not null;index"`              // e.g. login.locked_out, match.updated
+	TargetType string `json:"target_type" gorm:"index:idx_audit_target"` // the resource, e.g. match
+	TargetID   *uint  `json:"target_id" gorm:"index:idx_audit_target"`
+	IPAddress  string `json:"ip_address"`
+	Details    string `json:"details"`
+
+	// Changes is a JSON object of the fields that changed, each with its
+	// before and after value
+	Changes string `json:"changes"`
+
+	// Request metadata
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	UserAgent string `json:"user_agent"`
+	SessionID *uint  `json:"session_id"`
+	APIKeyID  *uint  `json:"api_key_id"`
+}
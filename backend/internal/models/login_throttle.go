@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LoginThrottle tracks failed logins for one key, either an account
+// ("user:<id>" or "account:<identifier>") or a client ("ip:<address>")
+type LoginThrottle struct {
+	BaseModel
+	Key           string     `json:"key" gorm:"uniqueIndex;not null"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	BlockedUntil  *time.Time `json:"blocked_until"`
+	LockedOut     bool       `json:"locked_out" gorm:"default:false"`
+}
+
+// RetryAfter returns how long the key is still blocked at the given time
+func (t *LoginThrottle) RetryAfter(now time.Time) time.Duration {
+	if t.BlockedUntil == nil || !now.Before(*t.BlockedUntil) {
+		return 0
+	}
+	return t.BlockedUntil.Sub(now)
+}
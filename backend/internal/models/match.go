@@ -45,6 +45,10 @@ type Match struct {
 	WinnerPlayerID *uint `json:"winner_player_id"`
 	WinnerTeamID   *uint `json:"winner_team_id"`
 
+	// Set for matches created through the API; friendly matches belong to
+	// the player who created them
+	CreatedByID *uint `json:"created_by_id"`
+
 	// Relations
 	Tournament   *Tournament `json:"tournament,omitempty" gorm:"foreignKey:TournamentID"`
 	Player1      *User       `json:"player1,omitempty" gorm:"foreignKey:Player1ID"`
@@ -69,3 +73,81 @@ func (m *Match) SetWinner(playerID *uint, teamID *uint) {
 	}
 	m.Status = MatchCompleted
 }
+
+// ApplyResult records the scores and winner of a confirmed result on the
+// match and completes it
+func (m *Match) ApplyResult(side1Score, side2Score int) {
+	winnerSide := 2
+	if side1Score > side2Score {
+		winnerSide = 1
+	}
+
+	if m.IsTeamMatch() {
+		m.Team1Score, m.Team2Score = side1Score, side2Score
+		winner := m.Team1ID
+		if winnerSide == 2 {
+			winner = m.Team2ID
+		}
+		m.SetWinner(nil, cloneID(winner))
+		return
+	}
+
+	m.Player1Score, m.Player2Score = side1Score, side2Score
+	winner := m.Player1ID
+	if winnerSide == 2 {
+		winner = m.Player2ID
+	}
+	m.SetWinner(cloneID(winner), nil)
+}
+
+// WinnerSide returns the side that won, 1 or 2, or 0 while there is no
+// winner
+func (m *Match) WinnerSide() int {
+	side1, side2, winner := m.Player1ID, m.Player2ID, m.WinnerPlayerID
+	if m.IsTeamMatch() {
+		side1, side2, winner = m.Team1ID, m.Team2ID, m.WinnerTeamID
+	}
+
+	switch {
+	case winner == nil:
+		return 0
+	case side1 != nil && *side1 == *winner:
+		return 1
+	case side2 != nil && *side2 == *winner:
+		return 2
+	}
+	return 0
+}
+
+// IsWalkover checks if the match was won without an opponent, as a
+// first-round bye is
+func (m *Match) IsWalkover() bool {
+	side2, winner := m.Player2ID, m.WinnerPlayerID
+	if m.IsTeamMatch() {
+		side2, winner = m.Team2ID, m.WinnerTeamID
+	}
+	return m.Status == MatchCompleted && side2 == nil && winner != nil
+}
+
+// Clone returns a copy of the match that shares no ID pointers with it, so
+// binding request data into one leaves the other untouched
+func (m Match) Clone() Match {
+	clone := m
+	clone.TournamentID = cloneID(m.TournamentID)
+	clone.Player1ID = cloneID(m.Player1ID)
+	clone.Player2ID = cloneID(m.Player2ID)
+	clone.Team1ID = cloneID(m.Team1ID)
+	clone.Team2ID = cloneID(m.Team2ID)
+	clone.WinnerPlayerID = cloneID(m.WinnerPlayerID)
+	clone.WinnerTeamID = cloneID(m.WinnerTeamID)
+	clone.CreatedByID = cloneID(m.CreatedByID)
+	return clone
+}
+
+func cloneID(id *uint) *uint {
+	if id == nil {
+		return nil
+	}
+	value := *id
+	return &value
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// MatchResultStatus defines the states of a self-reported result
+type MatchResultStatus string
+
+const (
+	ResultPending   MatchResultStatus = "pending"   // waiting for the opponent
+	ResultConfirmed MatchResultStatus = "confirmed" // applied to the match
+	ResultDisputed  MatchResultStatus = "disputed"  // waiting for an admin
+	ResultRejected  MatchResultStatus = "rejected"  // discarded by an admin
+)
+
+// MatchResult is a result of a friendly match reported by one of its
+// participants. It only counts once the other side confirms it or an admin
+// accepts it after a dispute.
+type MatchResult struct {
+	BaseModel
+	MatchID      uint              `json:"match_id" gorm:"not null;index"`
+	ReportedByID uint              `json:"reported_by_id" gorm:"not null"`
+	ReporterSide int               `json:"reporter_side" gorm:"not null"` // 1 or 2
+	Side1Score   int               `json:"side1_score"`
+	Side2Score   int               `json:"side2_score"`
+	Status       MatchResultStatus `json:"status" gorm:"not null;default:'pending';index"`
+
+	// Set by the opponent who confirmed or disputed the result
+	RespondedByID *uint      `json:"responded_by_id"`
+	RespondedAt   *time.Time `json:"responded_at"`
+	DisputeReason string     `json:"dispute_reason"`
+
+	// Set by the admin who settled a dispute
+	ResolvedByID   *uint      `json:"resolved_by_id"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	ResolutionNote string     `json:"resolution_note"`
+
+	// Relations
+	Match      Match `json:"-" gorm:"foreignKey:MatchID"`
+	ReportedBy User  `json:"-" gorm:"foreignKey:ReportedByID"`
+}
+
+// IsOpen checks if the result is still waiting for the opponent or an admin
+func (r *MatchResult) IsOpen() bool {
+	return r.Status == ResultPending || r.Status == ResultDisputed
+}
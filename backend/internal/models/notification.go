@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Notification is an in-app message shown to a user
+type Notification struct {
+	BaseModel
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	Type         string     `json:"type" gorm:"not null"` // e.g. registration_closed, tournament_cancelled, result_reported
+	Title        string     `json:"title" gorm:"not null"`
+	Message      string     `json:"message"`
+	TournamentID *uint      `json:"tournament_id"`
+	MatchID      *uint      `json:"match_id"`
+	ReadAt       *time.Time `json:"read_at"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsRead checks if the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// RefundStatus defines refund statuses
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundProcessed RefundStatus = "processed"
+)
+
+// Refund is an entry fee owed back to a player, e.g. after a tournament is
+// cancelled. Team entry fees are refunded to the team captain.
+type Refund struct {
+	BaseModel
+	TournamentID  uint         `json:"tournament_id" gorm:"not null;index"`
+	UserID        uint         `json:"user_id" gorm:"not null;index"`
+	TeamID        *uint        `json:"team_id"`
+	Amount        float64      `json:"amount" gorm:"not null"`
+	Reason        string       `json:"reason"`
+	Status        RefundStatus `json:"status" gorm:"default:'pending'"`
+	ProcessedAt   *time.Time   `json:"processed_at"`
+	ProcessedByID *uint        `json:"processed_by_id"`
+
+	// Relations
+	Tournament Tournament `json:"tournament" gorm:"foreignKey:TournamentID"`
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+}
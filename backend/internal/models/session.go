@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Session is a login on one device. It backs a chain of rotating refresh
+// tokens; only hashes of the current and previous token are stored.
+type Session struct {
+	BaseModel
+	UserID            uint       `json:"user_id" gorm:"not null;index"`
+	RefreshTokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	PreviousTokenHash string     `json:"-" gorm:"index"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	LastRefreshedAt   *time.Time `json:"last_refreshed_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	UserAgent         string     `json:"user_agent"`
+	IPAddress         string     `json:"ip_address"`
+	MFA               bool       `json:"mfa" gorm:"default:false"` // second factor verified for this login
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsActive checks if the session can still be used at the given time
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
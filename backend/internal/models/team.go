@@ -1,5 +1,36 @@
 package models
 
+import "time"
+
+// RegistrationStatus defines tournament registration statuses
+type RegistrationStatus string
+
+const (
+	RegistrationRegistered RegistrationStatus = "registered"
+	RegistrationConfirmed  RegistrationStatus = "confirmed"
+	RegistrationWaitlisted RegistrationStatus = "waitlisted"
+	RegistrationCheckedIn  RegistrationStatus = "checked_in"
+	RegistrationNoShow     RegistrationStatus = "no_show"
+	RegistrationWithdrawn  RegistrationStatus = "withdrawn"
+	RegistrationCancelled  RegistrationStatus = "cancelled" // tournament was cancelled
+)
+
+// ActiveRegistrationStatuses are the statuses that hold a place in the draw
+var ActiveRegistrationStatuses = []RegistrationStatus{
+	RegistrationRegistered,
+	RegistrationConfirmed,
+	RegistrationCheckedIn,
+}
+
+// Team roles
+const (
+	TeamRoleCaptain = "captain"
+	TeamRolePlayer  = "player"
+)
+
+// MaxTeamSize is the number of players on a doubles team
+const MaxTeamSize = 2
+
 // Team represents a team for doubles tournaments
 type Team struct {
 	BaseModel
@@ -23,12 +54,33 @@ type TeamPlayer struct {
 	Player User `json:"player" gorm:"foreignKey:PlayerID"`
 }
 
+// CaptainID returns the ID of the team captain (requires Players to be loaded)
+func (t *Team) CaptainID() uint {
+	for _, tp := range t.Players {
+		if tp.Role == TeamRoleCaptain {
+			return tp.PlayerID
+		}
+	}
+	return 0
+}
+
+// HasMember checks if the user is on the team (requires Players to be loaded)
+func (t *Team) HasMember(userID uint) bool {
+	for _, tp := range t.Players {
+		if tp.PlayerID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // TournamentPlayer represents player registration in tournaments
 type TournamentPlayer struct {
 	BaseModel
-	TournamentID uint   `json:"tournament_id" gorm:"not null"`
-	PlayerID     uint   `json:"player_id" gorm:"not null"`
-	Status       string `json:"status" gorm:"default:'registered'"` // registered, confirmed, withdrawn
+	TournamentID uint               `json:"tournament_id" gorm:"not null"`
+	PlayerID     uint               `json:"player_id" gorm:"not null"`
+	Status       RegistrationStatus `json:"status" gorm:"default:'registered'"`
+	CheckedInAt  *time.Time         `json:"checked_in_at"`
 
 	// Relations
 	Tournament Tournament `json:"tournament" gorm:"foreignKey:TournamentID"`
@@ -38,9 +90,10 @@ type TournamentPlayer struct {
 // TournamentTeam represents team registration in tournaments
 type TournamentTeam struct {
 	BaseModel
-	TournamentID uint   `json:"tournament_id" gorm:"not null"`
-	TeamID       uint   `json:"team_id" gorm:"not null"`
-	Status       string `json:"status" gorm:"default:'registered'"` // registered, confirmed, withdrawn
+	TournamentID uint               `json:"tournament_id" gorm:"not null"`
+	TeamID       uint               `json:"team_id" gorm:"not null"`
+	Status       RegistrationStatus `json:"status" gorm:"default:'registered'"`
+	CheckedInAt  *time.Time         `json:"checked_in_at"`
 
 	// Relations
 	Tournament Tournament `json:"tournament" gorm:"foreignKey:TournamentID"`
@@ -14,12 +14,25 @@ const (
 type TournamentStatus string
 
 const (
-	TournamentUpcoming  TournamentStatus = "upcoming"
-	TournamentOngoing   TournamentStatus = "ongoing"
-	TournamentCompleted TournamentStatus = "completed"
-	TournamentCancelled TournamentStatus = "cancelled"
+	TournamentDraft              TournamentStatus = "draft"
+	TournamentRegistrationOpen   TournamentStatus = "registration_open"
+	TournamentRegistrationClosed TournamentStatus = "registration_closed"
+	TournamentDrawn              TournamentStatus = "drawn"
+	TournamentOngoing            TournamentStatus = "ongoing"
+	TournamentCompleted          TournamentStatus = "completed"
+	TournamentCancelled          TournamentStatus = "cancelled"
 )
 
+// tournamentTransitions lists the statuses reachable from each status.
+// Completed and cancelled tournaments are final.
+var tournamentTransitions = map[TournamentStatus][]TournamentStatus{
+	TournamentDraft:              {TournamentRegistrationOpen, TournamentCancelled},
+	TournamentRegistrationOpen:   {TournamentRegistrationClosed, TournamentCancelled},
+	TournamentRegistrationClosed: {TournamentRegistrationOpen, TournamentDrawn, TournamentCancelled},
+	TournamentDrawn:              {TournamentOngoing, TournamentCancelled},
+	TournamentOngoing:            {TournamentCompleted, TournamentCancelled},
+}
+
 // Tournament represents a badminton tournament
 type Tournament struct {
 	BaseModel
@@ -28,13 +41,18 @@ type Tournament struct {
 	Type        TournamentType   `json:"type" gorm:"default:'singles'"` // singles or doubles
 	StartDate   time.Time        `json:"start_date"`
 	EndDate     time.Time        `json:"end_date"`
-	Status      TournamentStatus `json:"status" gorm:"default:'upcoming'"`
+	Status      TournamentStatus `json:"status" gorm:"default:'draft'"`
 	MaxPlayers  int              `json:"max_players" gorm:"default:16"`
 	MaxTeams    int              `json:"max_teams" gorm:"default:8"` // for doubles tournaments
 	EntryFee    float64          `json:"entry_fee" gorm:"default:0"`
 	PrizePool   float64          `json:"prize_pool" gorm:"default:0"`
 	AdminID     uint             `json:"admin_id" gorm:"not null"`
 
+	// Check-in opens CheckInWindowMinutes before StartDate and stays open
+	// until an admin closes it
+	CheckInWindowMinutes int        `json:"check_in_window_minutes" gorm:"default:60"`
+	CheckInClosedAt      *time.Time `json:"check_in_closed_at"`
+
 	// Relations
 	Admin   User               `json:"admin" gorm:"foreignKey:AdminID"`
 	Matches []Match            `json:"matches,omitempty" gorm:"foreignKey:TournamentID"`
@@ -47,6 +65,21 @@ func (t *Tournament) IsTeamTournament() bool {
 	return t.Type == TournamentDoubles
 }
 
+// CanTransitionTo checks if the tournament may move to the given status
+func (t *Tournament) CanTransitionTo(next TournamentStatus) bool {
+	for _, status := range tournamentTransitions[t.Status] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished checks if the tournament is completed or cancelled
+func (t *Tournament) IsFinished() bool {
+	return t.Status == TournamentCompleted || t.Status == TournamentCancelled
+}
+
 // GetMaxParticipants returns max participants based on tournament type
 func (t *Tournament) GetMaxParticipants() int {
 	if t.IsTeamTournament() {
@@ -54,3 +87,27 @@ func (t *Tournament) GetMaxParticipants() int {
 	}
 	return t.MaxPlayers
 }
+
+// CheckInOpensAt returns when self-service check-in opens
+func (t *Tournament) CheckInOpensAt() time.Time {
+	return t.StartDate.Add(-time.Duration(t.CheckInWindowMinutes) * time.Minute)
+}
+
+// IsCheckInClosed checks if check-in has been closed
+func (t *Tournament) IsCheckInClosed() bool {
+	return t.CheckInClosedAt != nil
+}
+
+// IsCheckInAllowed checks if entrants can be checked in: during the
+// registration phase and until check-in is closed
+func (t *Tournament) IsCheckInAllowed() bool {
+	if t.IsCheckInClosed() {
+		return false
+	}
+	return t.Status == TournamentRegistrationOpen || t.Status == TournamentRegistrationClosed
+}
+
+// IsSelfCheckInOpen checks if entrants can check themselves in at the given time
+func (t *Tournament) IsSelfCheckInOpen(now time.Time) bool {
+	return !t.IsCheckInClosed() && !now.Before(t.CheckInOpensAt()) && now.Before(t.StartDate)
+}
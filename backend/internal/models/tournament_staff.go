@@ -0,0 +1,74 @@
+package models
+
+// StaffRole defines the roles a user can hold within a single tournament
+type StaffRole string
+
+const (
+	StaffOrganizer StaffRole = "organizer"
+	StaffReferee   StaffRole = "referee"
+	StaffScorer    StaffRole = "scorer"
+	StaffDesk      StaffRole = "desk"
+)
+
+// TournamentPermission is an action on a tournament that requires a staff role
+type TournamentPermission string
+
+const (
+	PermManageTournament TournamentPermission = "manage_tournament" // edit details, lifecycle, delete
+	PermManageStaff      TournamentPermission = "manage_staff"
+	PermManageEntries    TournamentPermission = "manage_entries" // check-in desk and entrant lists
+	PermManageDraw       TournamentPermission = "manage_draw"
+	PermManageMatches    TournamentPermission = "manage_matches" // create, reschedule and delete matches
+	PermScoreMatches     TournamentPermission = "score_matches"
+)
+
+// staffPermissions lists what each role may do. The tournament's admin is
+// always treated as an organizer.
+var staffPermissions = map[StaffRole][]TournamentPermission{
+	StaffOrganizer: {PermManageTournament, PermManageStaff, PermManageEntries, PermManageDraw, PermManageMatches, PermScoreMatches},
+	StaffReferee:   {PermManageMatches, PermScoreMatches},
+	StaffScorer:    {PermScoreMatches},
+	StaffDesk:      {PermManageEntries},
+}
+
+// IsValid checks if the role is a known staff role
+func (r StaffRole) IsValid() bool {
+	_, ok := staffPermissions[r]
+	return ok
+}
+
+// Can checks if the role grants the given permission
+func (r StaffRole) Can(permission TournamentPermission) bool {
+	for _, p := range staffPermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions granted by the role
+func (r StaffRole) Permissions() []TournamentPermission {
+	return staffPermissions[r]
+}
+
+// AdminOverridable checks if site admins may use the permission on any
+// tournament. Only staff management is, so an admin can recover a
+// tournament whose organizer is gone without gaining score or draw access.
+func (p TournamentPermission) AdminOverridable() bool {
+	return p == PermManageStaff
+}
+
+// TournamentStaff assigns a user a role within one tournament. A user may
+// hold several roles in the same tournament.
+type TournamentStaff struct {
+	BaseModel
+	TournamentID uint      `json:"tournament_id" gorm:"not null;uniqueIndex:idx_tournament_staff_role"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_tournament_staff_role;index"`
+	Role         StaffRole `json:"role" gorm:"not null;uniqueIndex:idx_tournament_staff_role"`
+	AssignedByID uint      `json:"assigned_by_id"`
+
+	// Relations
+	Tournament Tournament `json:"-" gorm:"foreignKey:TournamentID"`
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // UserRole defines user roles
 type UserRole string
@@ -20,9 +24,22 @@ type User struct {
 	Role     UserRole `json:"role" gorm:"default:'player'"`
 	IsActive bool     `json:"is_active" gorm:"default:true"`
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	// Two-factor authentication. The secret is set on setup and only
+	// enforced once TOTPEnabledAt is set.
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+	TOTPLastStep  int64      `json:"-"` // last accepted time step, to stop codes being replayed
+
 	// Player-specific fields (only used when Role = player)
 	Ranking int `json:"ranking,omitempty" gorm:"default:0"`
 
+	// Accounts converted from legacy players keep the old player ID and
+	// stay pending until their owner claims them with an emailed invite
+	LegacyPlayerID *uint `json:"-" gorm:"uniqueIndex"`
+	ClaimPending   bool  `json:"claim_pending" gorm:"default:false"`
+
 	// Relations
 	PlayerTeams      []TeamPlayer       `json:"player_teams,omitempty" gorm:"foreignKey:PlayerID"`
 	AdminTournaments []Tournament       `json:"admin_tournaments,omitempty" gorm:"foreignKey:AdminID"`
@@ -45,6 +62,16 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
+// IsEmailVerified checks if the user has confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsTOTPEnabled checks if the user has two-factor authentication turned on
+func (u *User) IsTOTPEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
 // IsAdmin checks if user is admin
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to an account at an external OpenID provider
+type UserIdentity struct {
+	BaseModel
+	UserID  uint   `json:"user_id" gorm:"not null;index"`
+	Issuer  string `json:"issuer" gorm:"not null;uniqueIndex:idx_identity_subject"`
+	Subject string `json:"subject" gorm:"not null;uniqueIndex:idx_identity_subject"`
+	Email   string `json:"email"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// OIDCAuthRequest is an authorization request in flight to the provider.
+// It keeps the PKCE verifier server-side; the browser that started it
+// holds the state's hash in a cookie the callback checks.
+type OIDCAuthRequest struct {
+	BaseModel
+	StateHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Nonce        string     `json:"-" gorm:"not null"`
+	CodeVerifier string     `json:"-" gorm:"not null"`
+	LinkUserID   *uint      `json:"link_user_id"` // set when a signed-in user is linking an identity
+	ExpiresAt    time.Time  `json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+}
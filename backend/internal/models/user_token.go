@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// UserTokenPurpose defines what a one-time user token can be used for
+type UserTokenPurpose string
+
+const (
+	TokenEmailVerification UserTokenPurpose = "email_verification"
+	TokenPasswordReset     UserTokenPurpose = "password_reset"
+	TokenMFAChallenge      UserTokenPurpose = "mfa_challenge" // second login step after a correct password
+	TokenOIDCLogin         UserTokenPurpose = "oidc_login"    // hands a single sign-on login to the frontend
+	TokenAccountClaim      UserTokenPurpose = "account_claim" // invites a converted legacy player to claim their account
+)
+
+// UserToken is a single-use, time-limited token emailed to a user.
+// Only a hash of the token is stored.
+type UserToken struct {
+	BaseModel
+	UserID    uint             `json:"user_id" gorm:"not null;index"`
+	Purpose   UserTokenPurpose `json:"purpose" gorm:"not null"`
+	TokenHash string           `json:"-" gorm:"uniqueIndex;not null"`
+	Email     string           `json:"email"` // address the token was sent to
+	ExpiresAt time.Time        `json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsUsable checks if the token is unused and unexpired at the given time
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
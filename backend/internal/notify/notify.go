@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// Notifier delivers in-app notifications to users
+type Notifier struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Notifier {
+	return &Notifier{db: db}
+}
+
+// Notify sends the same notification to each user. The notification's
+// UserID is ignored.
+func (n *Notifier) Notify(userIDs []uint, notification models.Notification) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = notification
+		notifications[i].UserID = userID
+	}
+
+	return n.db.Create(&notifications).Error
+}
+
+// NotifyEntrants sends a notification to every player entered in the
+// tournament, including all members of entered teams. Withdrawn entrants
+// are skipped.
+func (n *Notifier) NotifyEntrants(tournament *models.Tournament, notification models.Notification) error {
+	userIDs, err := n.entrantUserIDs(tournament)
+	if err != nil {
+		return err
+	}
+
+	notification.TournamentID = &tournament.ID
+	return n.Notify(userIDs, notification)
+}
+
+// entrantUserIDs returns the distinct user IDs of the tournament's entrants
+func (n *Notifier) entrantUserIDs(tournament *models.Tournament) ([]uint, error) {
+	var userIDs []uint
+
+	if tournament.IsTeamTournament() {
+		err := n.db.Model(&models.TeamPlayer{}).
+			Distinct("team_players.player_id").
+			Joins("JOIN tournament_teams ON tournament_teams.team_id = team_players.team_id AND tournament_teams.deleted_at IS NULL").
+			Where("tournament_teams.tournament_id = ? AND tournament_teams.status != ?", tournament.ID, models.RegistrationWithdrawn).
+			Pluck("team_players.player_id", &userIDs).Error
+		return userIDs, err
+	}
+
+	err := n.db.Model(&models.TournamentPlayer{}).
+		Distinct("player_id").
+		Where("tournament_id = ? AND status != ?", tournament.ID, models.RegistrationWithdrawn).
+		Pluck("player_id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,285 @@
+// Package openapi describes the API as an OpenAPI 3 document. Paths and
+// methods come from the routes registered with Gin, everything else from an
+// Endpoint written for each route, with schemas reflected from the Go types
+// the handler binds and responds with.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"badminton-backend/internal/listing"
+)
+
+// Version is the OpenAPI version documents are written in
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on a path by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string               `json:"summary"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// Security overrides the document's; public operations have an empty one
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path or query
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// SecurityRequirement names the schemes an operation accepts
+type SecurityRequirement map[string][]string
+
+// bearerAuth is the one security scheme: an access token or an API key in
+// the Authorization header
+const bearerAuth = "bearerAuth"
+
+const jsonContent = "application/json"
+
+// Endpoint documents one route
+type Endpoint struct {
+	Summary string
+	Tag     string
+	// Public routes need no token
+	Public bool
+	// Query lists the query parameters the handler reads
+	Query []Param
+	// Body is a value of the type the handler binds its JSON body to, nil
+	// when it reads none
+	Body interface{}
+	// Status is the success status, 200 when zero
+	Status int
+	// Response is a value of the success response body. Interface fields
+	// holding a value are described by that value's type, so
+	// views.SuccessResponse{Data: []views.TeamResponse{}} is a list of teams.
+	Response interface{}
+	// Redirect marks handlers that send the browser elsewhere instead of
+	// answering with JSON
+	Redirect bool
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// ListParams are the page, limit and sort parameters of a list endpoint and
+// the filters its spec accepts
+func ListParams(spec listing.Spec) []Param {
+	sorts := make([]string, 0, len(spec.Sorts))
+	for key := range spec.Sorts {
+		sorts = append(sorts, key)
+	}
+	sort.Strings(sorts)
+
+	params := []Param{
+		{Name: "page", Description: "Page number, from 1"},
+		{Name: "limit", Description: "Results per page, " + strconv.Itoa(listing.DefaultLimit) + " by default and at most " + strconv.Itoa(listing.MaxLimit)},
+		{Name: "sort", Description: "Comma separated sort keys, each prefixed with - for descending order: " + strings.Join(sorts, ", ") + ". Defaults to " + spec.DefaultSort},
+	}
+
+	filters := make([]string, 0, len(spec.Filters))
+	for name := range spec.Filters {
+		filters = append(filters, name)
+	}
+	sort.Strings(filters)
+	for _, name := range filters {
+		params = append(params, Param{Name: name, Description: "Filter"})
+	}
+	return params
+}
+
+// Build describes the routes. Endpoints are keyed by method and path as
+// in "GET /api/v1/matches"; every route needs one and every endpoint a
+// route. errorBody is a value of the body of every error response.
+func Build(info Info, routes gin.RoutesInfo, endpoints map[string]Endpoint, errorBody interface{}) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "An access token from /login, or an API key",
+				},
+			},
+		},
+		Security: []SecurityRequirement{{bearerAuth: {}}},
+	}
+	r := newReflector(doc.Components.Schemas)
+	errorSchema := r.schema(reflect.ValueOf(errorBody), false)
+
+	var undocumented []string
+	documented := map[string]bool{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		endpoint, ok := endpoints[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			continue
+		}
+		documented[key] = true
+
+		path := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = r.operation(route.Path, endpoint, errorSchema)
+	}
+
+	var unrouted []string
+	for key := range endpoints {
+		if !documented[key] {
+			unrouted = append(unrouted, key)
+		}
+	}
+
+	var problems []string
+	if len(undocumented) > 0 {
+		sort.Strings(undocumented)
+		problems = append(problems, "routes without an endpoint: "+strings.Join(undocumented, ", "))
+	}
+	if len(unrouted) > 0 {
+		sort.Strings(unrouted)
+		problems = append(problems, "endpoints without a route: "+strings.Join(unrouted, ", "))
+	}
+	problems = append(problems, r.problems...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("describing the API: %s", strings.Join(problems, "; "))
+	}
+	return doc, nil
+}
+
+func (r *reflector) operation(route string, endpoint Endpoint, errorSchema *Schema) *Operation {
+	op := &Operation{
+		Summary:   endpoint.Summary,
+		Responses: map[string]*Response{},
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+	if endpoint.Public {
+		op.Security = &[]SecurityRequirement{}
+	}
+
+	for _, segment := range strings.Split(route, "/") {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = &Schema{Type: "integer", Minimum: float(1)}
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	for _, param := range endpoint.Query {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if endpoint.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonContent: {Schema: r.schema(reflect.ValueOf(endpoint.Body), true)}},
+		}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	switch {
+	case endpoint.Redirect:
+		op.Responses[strconv.Itoa(http.StatusFound)] = &Response{Description: http.StatusText(http.StatusFound)}
+	case endpoint.Response != nil:
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{jsonContent: {Schema: r.schema(reflect.ValueOf(endpoint.Response), false)}},
+		}
+	default:
+		op.Responses[strconv.Itoa(status)] = &Response{Description: http.StatusText(status)}
+	}
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]MediaType{jsonContent: {Schema: errorSchema}},
+	}
+	return op
+}
+
+// openAPIPath turns Gin's :name and *name parameters into {name}
+func openAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
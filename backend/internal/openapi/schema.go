@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of OpenAPI schemas the Go types need
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // a *Schema, or false when no others are allowed
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+}
+
+// OneOf documents a value of any one of the given values' types, such as
+// a login answered either with tokens or with a two-factor challenge
+func OneOf(values ...interface{}) interface{} {
+	return oneOf(values)
+}
+
+type oneOf []interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	oneOfType      = reflect.TypeOf(oneOf{})
+)
+
+const componentPrefix = "#/components/schemas/"
+
+// reflector turns Go values into schemas. Named structs become components
+// shared by reference.
+type reflector struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+	problems   []string
+}
+
+func newReflector(components map[string]*Schema) *reflector {
+	return &reflector{components: components, types: map[string]reflect.Type{}}
+}
+
+// schema describes v's type. Request bodies have required fields marked
+// by their binding tags; responses have every field required that is not
+// omitempty, and no fields but the documented ones.
+func (r *reflector) schema(v reflect.Value, request bool) *Schema {
+	return r.typeSchema(v.Type(), v, request)
+}
+
+// typeSchema describes t. v is a value of t when one is at hand, which
+// only matters for interface fields.
+func (r *reflector) typeSchema(t reflect.Type, v reflect.Value, request bool) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	case oneOfType:
+		choices := &Schema{}
+		for i := 0; i < v.Len(); i++ {
+			choices.OneOf = append(choices.OneOf, r.schema(v.Index(i).Elem(), request))
+		}
+		return choices
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Pointer:
+		var elem reflect.Value
+		if v.IsValid() && !v.IsNil() {
+			elem = v.Elem()
+		}
+		return nullable(r.typeSchema(t.Elem(), elem, request))
+	case reflect.Interface:
+		if v.IsValid() && !v.IsNil() {
+			return r.schema(v.Elem(), request)
+		}
+		return &Schema{}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		var first reflect.Value
+		if v.IsValid() && v.Len() > 0 {
+			first = v.Index(0)
+		}
+		return &Schema{Type: "array", Items: r.typeSchema(t.Elem(), first, request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.typeSchema(t.Elem(), reflect.Value{}, request)}
+	case reflect.Struct:
+		if t.Name() == "" || hasInterface(t) {
+			return r.object(t, v, request)
+		}
+		return r.component(t, request)
+	}
+
+	r.problems = append(r.problems, "cannot describe "+t.String())
+	return &Schema{}
+}
+
+// component describes a named struct once and refers to it from then on
+func (r *reflector) component(t reflect.Type, request bool) *Schema {
+	name := t.Name()
+	if other, ok := r.types[name]; ok && other != t {
+		// Same name in another package
+		name = pkgName(t) + name
+	}
+	if _, ok := r.types[name]; !ok {
+		r.types[name] = t
+		r.components[name] = &Schema{} // placeholder for types that refer to themselves
+		r.components[name] = r.object(t, reflect.Value{}, request)
+	}
+	return &Schema{Ref: componentPrefix + name}
+}
+
+func (r *reflector) object(t reflect.Type, v reflect.Value, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if !request {
+		s.AdditionalProperties = false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var value reflect.Value
+		if v.IsValid() {
+			value = v.Field(i)
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		// Embedded structs without a name of their own share their fields
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded, value = embedded.Elem(), reflect.Value{}
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.object(embedded, value, request)
+				for property, schema := range inner.Properties {
+					s.Properties[property] = schema
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := r.typeSchema(field.Type, value, request)
+		binding := strings.Split(field.Tag.Get("binding"), ",")
+		if request {
+			constrain(schema, binding)
+		}
+		s.Properties[name] = schema
+
+		required := !strings.Contains(options, "omitempty")
+		if request {
+			required = contains(binding, "required")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// constrain adds the limits of a binding tag to a field's schema
+func constrain(s *Schema, binding []string) {
+	for _, rule := range binding {
+		key, value, _ := strings.Cut(rule, "=")
+		n, err := strconv.Atoi(value)
+		switch {
+		case key == "email":
+			s.Format = "email"
+		case err != nil:
+		case s.Type == "string" && key == "min":
+			s.MinLength = &n
+		case s.Type == "string" && key == "max":
+			s.MaxLength = &n
+		case key == "min":
+			s.Minimum = float(n)
+		case key == "max":
+			s.Maximum = float(n)
+		}
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	copied := *s
+	copied.Nullable = true
+	return &copied
+}
+
+// hasInterface reports whether the struct has interface fields, whose
+// schema depends on the value they hold
+func hasInterface(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() == reflect.Interface {
+			return true
+		}
+	}
+	return false
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	name := path[strings.LastIndex(path, "/")+1:]
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func float(n int) *float64 {
+	f := float64(n)
+	return &f
+}
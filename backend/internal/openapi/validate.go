@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateResponse checks a response to the route, given by its method
+// and Gin path, against the document. Error statuses are checked against
+// the error body; success statuses must be the documented one.
+func (d *Document) ValidateResponse(method, route string, status int, body []byte) error {
+	op := d.Paths[openAPIPath(route)][strings.ToLower(method)]
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, route)
+	}
+
+	response := op.Responses[strconv.Itoa(status)]
+	if response == nil && status >= 400 {
+		response = op.Responses["default"]
+	}
+	if response == nil {
+		return fmt.Errorf("%s %s: status %d is not documented", method, route, status)
+	}
+	media, ok := response.Content[jsonContent]
+	if !ok {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s %s: body is not JSON: %v", method, route, err)
+	}
+	if err := d.validate(media.Schema, value, "body"); err != nil {
+		return fmt.Errorf("%s %s: %v", method, route, err)
+	}
+	return nil
+}
+
+func (d *Document) validate(s *Schema, value interface{}, path string) error {
+	if s.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, componentPrefix)]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, s.Ref)
+		}
+		s = resolved
+	}
+
+	if value == nil {
+		if s.Nullable || (s.Type == "" && s.AllOf == nil && s.OneOf == nil) {
+			return nil
+		}
+		return fmt.Errorf("%s is null", path)
+	}
+
+	for _, part := range s.AllOf {
+		if err := d.validate(part, value, path); err != nil {
+			return err
+		}
+	}
+	if s.OneOf != nil {
+		var errs []string
+		for _, choice := range s.OneOf {
+			err := d.validate(choice, value, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("%s matches none of its schemas (%s)", path, strings.Join(errs, "; "))
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", path)
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s.%s is missing", path, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				if err := d.validate(property, object[name], path+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s.%s is not documented", path, name)
+				}
+			case *Schema:
+				if err := d.validate(additional, object[name], path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an array", path)
+		}
+		for i, item := range array {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s is not a string", path)
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s is not an integer", path)
+		}
+		if _, err := number.Int64(); err != nil {
+			return fmt.Errorf("%s is not an integer", path)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s is not a number", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s is not a boolean", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"badminton-backend/internal/models"
+)
+
+// Match decides whether the actor may perform the action on the match.
+//
+// Tournament matches follow the tournament's staff roles. Friendly matches
+// belong to their participants: a player may only create matches they play
+// in and report results of their own matches for the opponent to confirm,
+// while changing or deleting a match is left to whoever created it until it
+// has been played. Admins may do anything to a friendly match.
+func (p *Policy) Match(actor Actor, match *models.Match, action Action) error {
+	if match.TournamentID != nil {
+		if action == ActionReport {
+			return deny("tournament_match", "Tournament results are entered by the tournament staff")
+		}
+		permission := models.PermManageMatches
+		if action == ActionScore {
+			permission = models.PermScoreMatches
+		}
+		return p.Tournament(actor, *match.TournamentID, permission)
+	}
+
+	if actor.IsAdmin() && action != ActionReport {
+		return nil
+	}
+
+	switch action {
+	case ActionCreate:
+		side, err := p.ParticipantSide(match, actor.User.ID)
+		if err != nil {
+			return err
+		}
+		if side == 0 {
+			return deny("not_participant", "You can only create matches you play in")
+		}
+		return nil
+
+	case ActionScore, ActionReport:
+		if match.Status == models.MatchCompleted || match.Status == models.MatchCancelled {
+			return deny("match_closed", "The result of a finished match can only be changed by an admin")
+		}
+		side, err := p.ParticipantSide(match, actor.User.ID)
+		if err != nil {
+			return err
+		}
+		if side == 0 {
+			return deny("not_participant", "You can only record results for your own matches")
+		}
+		if action == ActionScore {
+			return deny("result_confirmation_required", "Report the result with POST /matches/:id/result so your opponent can confirm it")
+		}
+		return nil
+
+	case ActionUpdate, ActionDelete:
+		if match.CreatedByID == nil || *match.CreatedByID != actor.User.ID {
+			return deny("not_owner", "Only the player who created this match can change it")
+		}
+		if match.Status != models.MatchPending {
+			return deny("match_started", "A match that has started can only be changed by an admin")
+		}
+		return nil
+	}
+
+	return deny("forbidden", "You do not have permission to do this")
+}
+
+// RespondToResult decides whether the actor may confirm or dispute a
+// reported result: only a participant on the other side of the match may
+func (p *Policy) RespondToResult(actor Actor, match *models.Match, result *models.MatchResult) error {
+	if result.Status != models.ResultPending {
+		return deny("result_not_pending", "This result is no longer waiting for confirmation")
+	}
+
+	side, err := p.ParticipantSide(match, actor.User.ID)
+	if err != nil {
+		return err
+	}
+	if side == 0 || side == result.ReporterSide {
+		return deny("not_opponent", "Only the opponent can confirm or dispute this result")
+	}
+	return nil
+}
+
+// ParticipantSide returns which side of the match the user plays on: 1 or
+// 2, or 0 if they are not taking part. Doubles sides are the teams' members.
+func (p *Policy) ParticipantSide(match *models.Match, userID uint) (int, error) {
+	if match.IsTeamMatch() {
+		for side, teamID := range []*uint{match.Team1ID, match.Team2ID} {
+			if teamID == nil {
+				continue
+			}
+			var count int64
+			if err := p.db.Model(&models.TeamPlayer{}).
+				Where("team_id = ? AND player_id = ?", *teamID, userID).
+				Count(&count).Error; err != nil {
+				return 0, err
+			}
+			if count > 0 {
+				return side + 1, nil
+			}
+		}
+		return 0, nil
+	}
+
+	if match.Player1ID != nil && *match.Player1ID == userID {
+		return 1, nil
+	}
+	if match.Player2ID != nil && *match.Player2ID == userID {
+		return 2, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,40 @@
+package policy
+
+import (
+	"badminton-backend/internal/models"
+)
+
+// Player decides whether the actor may perform the action on a player
+// account through the legacy player endpoints. Players may edit their own
+// profile; creating and deleting accounts is for admins. A nil player is
+// used for ActionCreate.
+func (p *Policy) Player(actor Actor, player *models.User, action Action) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	switch action {
+	case ActionUpdate:
+		if player != nil && player.ID == actor.User.ID {
+			return nil
+		}
+		return deny("not_owner", "You can only edit your own player profile")
+	case ActionCreate:
+		return deny("admin_required", "Only admins can create player accounts")
+	case ActionDelete:
+		return deny("admin_required", "Only admins can delete player accounts")
+	}
+
+	return deny("forbidden", "You do not have permission to do this")
+}
+
+// PlayerAccountFields decides whether the actor may change a player's
+// ranking or email through the legacy player endpoints. Rankings are set
+// by admins, and players change their own email through their profile so
+// it is verified again.
+func (p *Policy) PlayerAccountFields(actor Actor) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	return deny("admin_required", "Only admins can change a player's ranking or email; use your profile to change your own email")
+}
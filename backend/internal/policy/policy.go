@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// Action is a mutation a policy decides on
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update" // change anything on the resource
+	ActionScore  Action = "score"  // record a match result directly
+	ActionReport Action = "report" // submit a result for the opponent to confirm
+	ActionDelete Action = "delete"
+)
+
+// Denied is returned when the actor may not perform an action
+type Denied struct {
+	Code   string
+	Reason string
+}
+
+func (d *Denied) Error() string {
+	return d.Reason
+}
+
+func deny(code, reason string) error {
+	return &Denied{Code: code, Reason: reason}
+}
+
+// IsDenied reports whether err is a policy denial rather than a failure to decide
+func IsDenied(err error) bool {
+	var denied *Denied
+	return errors.As(err, &denied)
+}
+
+// Actor is the user performing an action
+type Actor struct {
+	User *models.User
+	MFA  bool // the session passed two-factor authentication
+}
+
+// IsAdmin checks if the actor may use admin powers, which like the admin
+// routes requires a login that passed two-factor authentication
+func (a Actor) IsAdmin() bool {
+	return a.User.IsAdmin() && a.User.IsTOTPEnabled() && a.MFA
+}
+
+// Policy decides who may mutate which resources
+type Policy struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Policy {
+	return &Policy{db: db}
+}
+
+// TournamentRoles returns the staff roles a user holds in a tournament,
+// counting the tournament's admin as its organizer
+func (p *Policy) TournamentRoles(user *models.User, tournamentID uint) ([]models.StaffRole, error) {
+	var tournament models.Tournament
+	if err := p.db.Select("id", "admin_id").First(&tournament, tournamentID).Error; err != nil {
+		return nil, err
+	}
+
+	var roles []models.StaffRole
+	if err := p.db.Model(&models.TournamentStaff{}).
+		Where("tournament_id = ? AND user_id = ?", tournamentID, user.ID).
+		Pluck("role", &roles).Error; err != nil {
+		return nil, err
+	}
+
+	if tournament.AdminID == user.ID {
+		roles = append([]models.StaffRole{models.StaffOrganizer}, roles...)
+	}
+	return roles, nil
+}
+
+// HasTournamentPermission checks if any of the user's roles in the
+// tournament grants the permission
+func (p *Policy) HasTournamentPermission(user *models.User, tournamentID uint, permission models.TournamentPermission) (bool, error) {
+	roles, err := p.TournamentRoles(user, tournamentID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Can(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Tournament checks that the actor holds the permission in the tournament.
+// Site admins only pass where the permission allows an admin override.
+func (p *Policy) Tournament(actor Actor, tournamentID uint, permission models.TournamentPermission) error {
+	allowed, err := p.HasTournamentPermission(actor.User, tournamentID, permission)
+	if err != nil {
+		return err
+	}
+	if allowed || (permission.AdminOverridable() && actor.IsAdmin()) {
+		return nil
+	}
+	return deny("tournament_permission_required", "You do not have permission to do this in this tournament")
+}
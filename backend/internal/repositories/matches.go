@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/models"
+)
+
+// MatchRepository stores matches
+type MatchRepository interface {
+	// List returns a page of matches with their players, teams and tournament
+	List(q *listing.Query) ([]models.Match, listing.Page, error)
+	// Get returns the match without its relations
+	Get(id uint) (*models.Match, error)
+	// LoadRelations fills in the match's players, teams and tournament
+	LoadRelations(match *models.Match) error
+	// Create and Save write the match's own fields; players, teams and
+	// the tournament are referenced by ID only
+	Create(match *models.Match) error
+	CreateAll(matches []models.Match) error
+	Save(match *models.Match) error
+	Delete(match *models.Match) error
+	// CountByTournament counts the tournament's matches, only those in the
+	// given statuses if any are given
+	CountByTournament(tournamentID uint, statuses ...models.MatchStatus) (int64, error)
+	// CancelUnfinished cancels the tournament's pending and ongoing matches
+	// and returns how many there were
+	CancelUnfinished(tournamentID uint) (int64, error)
+}
+
+type gormMatchRepository struct {
+	db *gorm.DB
+}
+
+// withRelations preloads what match responses show: the players of a
+// singles match, the teams and their members of a doubles match
+func (r *gormMatchRepository) withRelations() *gorm.DB {
+	return r.db.Preload("Player1").Preload("Player2").
+		Preload("Team1.Players.Player").Preload("Team2.Players.Player").
+		Preload("Tournament")
+}
+
+func (r *gormMatchRepository) List(q *listing.Query) ([]models.Match, listing.Page, error) {
+	var matches []models.Match
+	page, err := q.Find(r.withRelations(), &matches)
+	return matches, page, err
+}
+
+func (r *gormMatchRepository) Get(id uint) (*models.Match, error) {
+	var match models.Match
+	if err := r.db.First(&match, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &match, nil
+}
+
+func (r *gormMatchRepository) LoadRelations(match *models.Match) error {
+	return notFound(r.withRelations().First(match, match.ID).Error)
+}
+
+func (r *gormMatchRepository) Create(match *models.Match) error {
+	return r.db.Omit(clause.Associations).Create(match).Error
+}
+
+func (r *gormMatchRepository) CreateAll(matches []models.Match) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&matches).Error
+}
+
+func (r *gormMatchRepository) Save(match *models.Match) error {
+	return r.db.Omit(clause.Associations).Save(match).Error
+}
+
+func (r *gormMatchRepository) Delete(match *models.Match) error {
+	return r.db.Delete(match).Error
+}
+
+func (r *gormMatchRepository) CountByTournament(tournamentID uint, statuses ...models.MatchStatus) (int64, error) {
+	query := r.db.Model(&models.Match{}).Where("tournament_id = ?", tournamentID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *gormMatchRepository) CancelUnfinished(tournamentID uint) (int64, error) {
+	result := r.db.Model(&models.Match{}).
+		Where("tournament_id = ? AND status IN ?", tournamentID, []models.MatchStatus{models.MatchPending, models.MatchOngoing}).
+		Update("status", models.MatchCancelled)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// RefundRepository stores refunds
+type RefundRepository interface {
+	CreateAll(refunds []models.Refund) error
+}
+
+type gormRefundRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRefundRepository) CreateAll(refunds []models.Refund) error {
+	if len(refunds) == 0 {
+		return nil
+	}
+	return r.db.Create(&refunds).Error
+}
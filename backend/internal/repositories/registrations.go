@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// RegistrationRepository stores player and team tournament registrations
+type RegistrationRepository interface {
+	FindPlayer(tournamentID, playerID uint) (*models.TournamentPlayer, error)
+	FindTeam(tournamentID, teamID uint) (*models.TournamentTeam, error)
+	// FindTeamByMember returns the registration in the given statuses of a
+	// team the player plays for
+	FindTeamByMember(tournamentID, playerID uint, statuses []models.RegistrationStatus) (*models.TournamentTeam, error)
+	// ListByPlayer returns the player's registrations that have not been
+	// withdrawn, with their tournaments
+	ListByPlayer(playerID uint) ([]models.TournamentPlayer, error)
+	// ListPlayers and ListTeams return all of the tournament's
+	// registrations in registration order, with their player or team
+	ListPlayers(tournamentID uint) ([]models.TournamentPlayer, error)
+	ListTeams(tournamentID uint) ([]models.TournamentTeam, error)
+
+	// CountPlayers and CountTeams count the tournament's registrations in
+	// the given statuses
+	CountPlayers(tournamentID uint, statuses []models.RegistrationStatus) (int64, error)
+	CountTeams(tournamentID uint, statuses []models.RegistrationStatus) (int64, error)
+
+	// PlayersWithStatus returns the tournament's player registrations in
+	// the given statuses in registration order, with their players
+	PlayersWithStatus(tournamentID uint, statuses []models.RegistrationStatus) ([]models.TournamentPlayer, error)
+	// TeamsWithStatus returns the tournament's team registrations in the
+	// given statuses in registration order, with their members
+	TeamsWithStatus(tournamentID uint, statuses []models.RegistrationStatus) ([]models.TournamentTeam, error)
+
+	// CreatePlayer and CreateTeam return ErrDuplicate when the player or
+	// team already has a registration for the tournament
+	CreatePlayer(registration *models.TournamentPlayer) error
+	CreateTeam(registration *models.TournamentTeam) error
+	SavePlayer(registration *models.TournamentPlayer) error
+
+	// CheckInPlayer and CheckInTeam record the entrant's arrival and move
+	// it to the given status, failing with ErrStale if it has changed
+	// status or checked in since it was loaded
+	CheckInPlayer(registration *models.TournamentPlayer, status models.RegistrationStatus, at time.Time) error
+	CheckInTeam(registration *models.TournamentTeam, status models.RegistrationStatus, at time.Time) error
+	// MarkNoShows moves the tournament's player and team registrations in
+	// the given statuses to no-show and returns how many there were
+	MarkNoShows(tournamentID uint, statuses []models.RegistrationStatus) (int64, error)
+	// PromotePlayers and PromoteTeams check in up to limit of the
+	// tournament's waitlisted registrations that have arrived, earliest
+	// registration first, and return how many there were
+	PromotePlayers(tournamentID uint, limit int) (int64, error)
+	PromoteTeams(tournamentID uint, limit int) (int64, error)
+
+	// CancelAll cancels the tournament's player and team registrations in
+	// the given statuses and returns how many there were
+	CancelAll(tournamentID uint, statuses []models.RegistrationStatus) (int64, error)
+
+	// IsTeamMember reports whether the user plays for the team
+	IsTeamMember(teamID, userID uint) (bool, error)
+	// CountUnverifiedMembers counts the team's members who have not
+	// verified their email address
+	CountUnverifiedMembers(teamID uint) (int64, error)
+}
+
+type gormRegistrationRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRegistrationRepository) FindPlayer(tournamentID, playerID uint) (*models.TournamentPlayer, error) {
+	var registration models.TournamentPlayer
+	if err := r.db.Where("tournament_id = ? AND player_id = ?", tournamentID, playerID).First(&registration).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &registration, nil
+}
+
+func (r *gormRegistrationRepository) FindTeam(tournamentID, teamID uint) (*models.TournamentTeam, error) {
+	var registration models.TournamentTeam
+	if err := r.db.Where("tournament_id = ? AND team_id = ?", tournamentID, teamID).First(&registration).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &registration, nil
+}
+
+func (r *gormRegistrationRepository) FindTeamByMember(tournamentID, playerID uint, statuses []models.RegistrationStatus) (*models.TournamentTeam, error) {
+	var registration models.TournamentTeam
+	err := r.db.Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+		Where("team_id IN (?)", r.db.Model(&models.TeamPlayer{}).Select("team_id").Where("player_id = ?", playerID)).
+		First(&registration).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &registration, nil
+}
+
+func (r *gormRegistrationRepository) ListByPlayer(playerID uint) ([]models.TournamentPlayer, error) {
+	var registrations []models.TournamentPlayer
+	err := r.db.Preload("Tournament").
+		Where("player_id = ? AND status != ?", playerID, models.RegistrationWithdrawn).
+		Find(&registrations).Error
+	return registrations, err
+}
+
+func (r *gormRegistrationRepository) ListPlayers(tournamentID uint) ([]models.TournamentPlayer, error) {
+	var registrations []models.TournamentPlayer
+	err := r.db.Preload("Player").Where("tournament_id = ?", tournamentID).Order("created_at").Find(&registrations).Error
+	return registrations, err
+}
+
+func (r *gormRegistrationRepository) ListTeams(tournamentID uint) ([]models.TournamentTeam, error) {
+	var registrations []models.TournamentTeam
+	err := r.db.Preload("Team").Where("tournament_id = ?", tournamentID).Order("created_at").Find(&registrations).Error
+	return registrations, err
+}
+
+func (r *gormRegistrationRepository) CountPlayers(tournamentID uint, statuses []models.RegistrationStatus) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.TournamentPlayer{}).
+		Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormRegistrationRepository) CountTeams(tournamentID uint, statuses []models.RegistrationStatus) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.TournamentTeam{}).
+		Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormRegistrationRepository) PlayersWithStatus(tournamentID uint, statuses []models.RegistrationStatus) ([]models.TournamentPlayer, error) {
+	var registrations []models.TournamentPlayer
+	err := r.db.Preload("Player").
+		Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+		Order("created_at").Find(&registrations).Error
+	return registrations, err
+}
+
+func (r *gormRegistrationRepository) TeamsWithStatus(tournamentID uint, statuses []models.RegistrationStatus) ([]models.TournamentTeam, error) {
+	var registrations []models.TournamentTeam
+	err := r.db.Preload("Team.Players.Player").
+		Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+		Order("created_at").Find(&registrations).Error
+	return registrations, err
+}
+
+func (r *gormRegistrationRepository) CreatePlayer(registration *models.TournamentPlayer) error {
+	return duplicate(r.db.Create(registration).Error)
+}
+
+func (r *gormRegistrationRepository) CreateTeam(registration *models.TournamentTeam) error {
+	return duplicate(r.db.Create(registration).Error)
+}
+
+func (r *gormRegistrationRepository) SavePlayer(registration *models.TournamentPlayer) error {
+	return r.db.Save(registration).Error
+}
+
+func (r *gormRegistrationRepository) CheckInPlayer(registration *models.TournamentPlayer, status models.RegistrationStatus, at time.Time) error {
+	if err := r.checkIn(registration, registration.ID, registration.Status, status, at); err != nil {
+		return err
+	}
+	registration.Status = status
+	registration.CheckedInAt = &at
+	return nil
+}
+
+func (r *gormRegistrationRepository) CheckInTeam(registration *models.TournamentTeam, status models.RegistrationStatus, at time.Time) error {
+	if err := r.checkIn(registration, registration.ID, registration.Status, status, at); err != nil {
+		return err
+	}
+	registration.Status = status
+	registration.CheckedInAt = &at
+	return nil
+}
+
+// checkIn updates the registration only if it still has the status it was
+// loaded with and has not checked in
+func (r *gormRegistrationRepository) checkIn(entry interface{}, id uint, from, to models.RegistrationStatus, at time.Time) error {
+	result := r.db.Model(entry).
+		Where("id = ? AND status = ? AND checked_in_at IS NULL", id, from).
+		Updates(map[string]interface{}{"status": to, "checked_in_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+	return nil
+}
+
+func (r *gormRegistrationRepository) MarkNoShows(tournamentID uint, statuses []models.RegistrationStatus) (int64, error) {
+	var noShows int64
+	for _, entry := range []interface{}{&models.TournamentPlayer{}, &models.TournamentTeam{}} {
+		result := r.db.Model(entry).
+			Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+			Update("status", models.RegistrationNoShow)
+		if result.Error != nil {
+			return noShows, result.Error
+		}
+		noShows += result.RowsAffected
+	}
+	return noShows, nil
+}
+
+func (r *gormRegistrationRepository) PromotePlayers(tournamentID uint, limit int) (int64, error) {
+	return r.promote(&models.TournamentPlayer{}, tournamentID, limit)
+}
+
+func (r *gormRegistrationRepository) PromoteTeams(tournamentID uint, limit int) (int64, error) {
+	return r.promote(&models.TournamentTeam{}, tournamentID, limit)
+}
+
+func (r *gormRegistrationRepository) promote(entry interface{}, tournamentID uint, limit int) (int64, error) {
+	var ids []uint
+	err := r.db.Model(entry).
+		Where("tournament_id = ? AND status = ? AND checked_in_at IS NOT NULL", tournamentID, models.RegistrationWaitlisted).
+		Order("created_at").Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result := r.db.Model(entry).Where("id IN ?", ids).Update("status", models.RegistrationCheckedIn)
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormRegistrationRepository) CancelAll(tournamentID uint, statuses []models.RegistrationStatus) (int64, error) {
+	var cancelled int64
+	for _, entry := range []interface{}{&models.TournamentPlayer{}, &models.TournamentTeam{}} {
+		result := r.db.Model(entry).
+			Where("tournament_id = ? AND status IN ?", tournamentID, statuses).
+			Update("status", models.RegistrationCancelled)
+		if result.Error != nil {
+			return cancelled, result.Error
+		}
+		cancelled += result.RowsAffected
+	}
+	return cancelled, nil
+}
+
+func (r *gormRegistrationRepository) IsTeamMember(teamID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.TeamPlayer{}).Where("team_id = ? AND player_id = ?", teamID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormRegistrationRepository) CountUnverifiedMembers(teamID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Joins("JOIN team_players ON team_players.player_id = users.id AND team_players.deleted_at IS NULL").
+		Where("team_players.team_id = ? AND users.email_verified_at IS NULL", teamID).
+		Count(&count).Error
+	return count, err
+}
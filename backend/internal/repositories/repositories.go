@@ -0,0 +1,80 @@
+// Package repositories stores and loads the domain models. Services depend
+// on the interfaces here; the GORM implementations are the only ones the
+// server uses.
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when a looked up record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a write would break a unique constraint
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrStale is returned when a record changed after it was loaded, so a
+	// conditional update matched nothing
+	ErrStale = errors.New("record changed")
+)
+
+// Store gives access to every repository and runs work in a transaction
+type Store interface {
+	Tournaments() TournamentRepository
+	Matches() MatchRepository
+	Registrations() RegistrationRepository
+	Refunds() RefundRepository
+
+	// Transaction runs fn with a store whose repositories all use one
+	// transaction, committed if fn returns nil
+	Transaction(fn func(tx Store) error) error
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewStore returns a store backed by the database
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Tournaments() TournamentRepository {
+	return &gormTournamentRepository{db: s.db}
+}
+
+func (s *gormStore) Matches() MatchRepository {
+	return &gormMatchRepository{db: s.db}
+}
+
+func (s *gormStore) Registrations() RegistrationRepository {
+	return &gormRegistrationRepository{db: s.db}
+}
+
+func (s *gormStore) Refunds() RefundRepository {
+	return &gormRefundRepository{db: s.db}
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// notFound translates GORM's missing record error
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// duplicate translates GORM's unique constraint error. The connection must
+// be opened with TranslateError for the driver error to be recognised.
+func duplicate(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
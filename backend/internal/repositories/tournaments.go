@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/models"
+)
+
+// TournamentRepository stores tournaments
+type TournamentRepository interface {
+	// List returns a page of tournaments with their matches
+	List(q *listing.Query) ([]models.Tournament, listing.Page, error)
+	// Get returns the tournament with its matches
+	Get(id uint) (*models.Tournament, error)
+	// Lock returns the tournament without its matches and holds its row
+	// until the surrounding transaction ends, so checks made against it
+	// cannot be overtaken by a concurrent transaction
+	Lock(id uint) (*models.Tournament, error)
+	Create(tournament *models.Tournament) error
+	// Save writes the tournament's own fields, never its relations
+	Save(tournament *models.Tournament) error
+	Delete(tournament *models.Tournament) error
+	// UpdateStatus moves the tournament on from the status it was loaded
+	// with, failing with ErrStale if it has changed since
+	UpdateStatus(tournament *models.Tournament, status models.TournamentStatus) error
+}
+
+type gormTournamentRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTournamentRepository) List(q *listing.Query) ([]models.Tournament, listing.Page, error) {
+	var tournaments []models.Tournament
+	page, err := q.Find(r.db.Preload("Matches"), &tournaments)
+	return tournaments, page, err
+}
+
+func (r *gormTournamentRepository) Get(id uint) (*models.Tournament, error) {
+	var tournament models.Tournament
+	if err := r.db.Preload("Matches").First(&tournament, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &tournament, nil
+}
+
+// Lock uses SELECT ... FOR UPDATE. SQLite has no row locks and GORM drops
+// the clause there; its transactions begin immediate instead, which holds
+// the database write lock for the same effect.
+func (r *gormTournamentRepository) Lock(id uint) (*models.Tournament, error) {
+	var tournament models.Tournament
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &tournament, nil
+}
+
+func (r *gormTournamentRepository) Create(tournament *models.Tournament) error {
+	return r.db.Omit(clause.Associations).Create(tournament).Error
+}
+
+func (r *gormTournamentRepository) Save(tournament *models.Tournament) error {
+	return r.db.Omit(clause.Associations).Save(tournament).Error
+}
+
+func (r *gormTournamentRepository) Delete(tournament *models.Tournament) error {
+	return r.db.Delete(tournament).Error
+}
+
+func (r *gormTournamentRepository) UpdateStatus(tournament *models.Tournament, status models.TournamentStatus) error {
+	result := r.db.Model(&models.Tournament{}).
+		Where("id = ? AND status = ?", tournament.ID, tournament.Status).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+	tournament.Status = status
+	return nil
+}
@@ -0,0 +1,490 @@
+package routes
+
+import (
+	"net/http"
+
+	"badminton-backend/internal/controllers"
+	"badminton-backend/internal/middleware"
+	"badminton-backend/internal/openapi"
+	"badminton-backend/internal/services"
+	"badminton-backend/internal/views"
+)
+
+// Info heads the OpenAPI document
+var Info = openapi.Info{
+	Title:       "Badminton API",
+	Version:     "1",
+	Description: "Tournaments, matches, teams and players. Responses wrap their payload in data, with meta on paged lists; errors carry a stable error code.",
+}
+
+// Endpoints document every route Register adds, by method and path. A
+// route missing here keeps the server from starting.
+var Endpoints = map[string]openapi.Endpoint{
+	// Auth
+	"POST /api/v1/register": {
+		Summary: "Create a player account and sign in", Tag: "Auth", Public: true,
+		Body: views.RegisterRequest{}, Status: http.StatusCreated, Response: data(views.AuthResponse{}),
+	},
+	"POST /api/v1/login": {
+		Summary: "Sign in, or get a two-factor challenge", Tag: "Auth", Public: true,
+		Body: views.LoginRequest{}, Response: data(loginResult),
+	},
+	"POST /api/v1/login/mfa": {
+		Summary: "Complete a two-factor login", Tag: "Auth", Public: true,
+		Body: views.LoginMFARequest{}, Response: data(views.AuthResponse{}),
+	},
+	"POST /api/v1/token/refresh": {
+		Summary: "Exchange a refresh token for new tokens", Tag: "Auth", Public: true,
+		Body: views.RefreshTokenRequest{}, Response: data(views.AuthResponse{}),
+	},
+	"POST /api/v1/verify-email": {
+		Summary: "Verify an email address with the emailed token", Tag: "Auth", Public: true,
+		Body: views.TokenRequest{}, Response: data(views.UserResponse{}),
+	},
+	"POST /api/v1/forgot-password": {
+		Summary: "Email a password reset link", Tag: "Auth", Public: true,
+		Body: views.ForgotPasswordRequest{}, Response: message,
+	},
+	"POST /api/v1/reset-password": {
+		Summary: "Set a new password with the emailed token", Tag: "Auth", Public: true,
+		Body: views.ResetPasswordRequest{}, Response: message,
+	},
+	"POST /api/v1/claim-account": {
+		Summary: "Claim a converted legacy player account", Tag: "Auth", Public: true,
+		Body: views.ClaimAccountRequest{}, Response: data(views.AuthResponse{}),
+	},
+	"GET /.well-known/jwks.json": {
+		Summary: "Public keys for verifying access tokens", Tag: "Auth", Public: true,
+		Response: middleware.JWKSet{},
+	},
+
+	// Single sign-on
+	"GET /api/v1/auth/oidc/login": {
+		Summary: "Start a single sign-on login at the provider", Tag: "Single sign-on", Public: true,
+		Redirect: true,
+	},
+	"GET /api/v1/auth/oidc/callback": {
+		Summary: "Return from the provider to the frontend", Tag: "Single sign-on", Public: true,
+		Query: []openapi.Param{
+			{Name: "code", Description: "Authorization code"},
+			{Name: "state", Description: "State of the login or link in progress"},
+			{Name: "error", Description: "Set by the provider when the user did not sign in"},
+		},
+		Redirect: true,
+	},
+	"POST /api/v1/auth/oidc/exchange": {
+		Summary: "Sign in with the one-time code from the callback", Tag: "Single sign-on", Public: true,
+		Body: views.OIDCExchangeRequest{}, Response: data(loginResult),
+	},
+	"GET /api/v1/auth/oidc/identities": {
+		Summary: "List linked provider accounts", Tag: "Single sign-on",
+		Response: data([]views.IdentityResponse{}),
+	},
+	"POST /api/v1/auth/oidc/link": {
+		Summary: "Start linking a provider account", Tag: "Single sign-on",
+		Response: data(views.AuthorizationURLResponse{}),
+	},
+	"DELETE /api/v1/auth/oidc/identities/:id": {
+		Summary: "Unlink a provider account", Tag: "Single sign-on",
+		Response: message,
+	},
+
+	// Profile
+	"GET /api/v1/profile": {
+		Summary: "Get the current user", Tag: "Profile",
+		Response: views.UserResponse{},
+	},
+	"PUT /api/v1/profile": {
+		Summary: "Update the current user", Tag: "Profile",
+		Body: views.UpdateProfileRequest{}, Response: data(views.UserResponse{}),
+	},
+	"POST /api/v1/change-password": {
+		Summary: "Change password, ending other sessions", Tag: "Profile",
+		Body: views.ChangePasswordRequest{}, Response: data(views.AuthResponse{}),
+	},
+	"POST /api/v1/logout": {
+		Summary: "End the current session", Tag: "Profile",
+		Response: message,
+	},
+	"POST /api/v1/logout-all": {
+		Summary: "End every session", Tag: "Profile",
+		Response: message,
+	},
+	"POST /api/v1/resend-verification": {
+		Summary: "Email a new verification link", Tag: "Profile",
+		Response: message,
+	},
+
+	// Two-factor authentication
+	"GET /api/v1/2fa": {
+		Summary: "Get two-factor status", Tag: "Two-factor authentication",
+		Response: data(views.TwoFactorStatusResponse{}),
+	},
+	"POST /api/v1/2fa/setup": {
+		Summary: "Generate a secret for an authenticator app", Tag: "Two-factor authentication",
+		Response: data(views.TwoFactorSetupResponse{}),
+	},
+	"POST /api/v1/2fa/enable": {
+		Summary: "Turn two-factor on and get recovery codes", Tag: "Two-factor authentication",
+		Body: views.TwoFactorCodeRequest{}, Response: data(views.RecoveryCodesResponse{}),
+	},
+	"POST /api/v1/2fa/disable": {
+		Summary: "Turn two-factor off", Tag: "Two-factor authentication",
+		Body: views.DisableTwoFactorRequest{}, Response: message,
+	},
+	"POST /api/v1/2fa/recovery-codes": {
+		Summary: "Replace the recovery codes", Tag: "Two-factor authentication",
+		Body: views.TwoFactorCodeRequest{}, Response: data(views.RecoveryCodesResponse{}),
+	},
+
+	// API keys
+	"GET /api/v1/api-keys": {
+		Summary: "List the current user's API keys", Tag: "API keys",
+		Response: data([]views.APIKeyResponse{}),
+	},
+	"POST /api/v1/api-keys": {
+		Summary: "Create an API key", Tag: "API keys",
+		Body: views.CreateAPIKeyRequest{}, Status: http.StatusCreated, Response: data(views.CreatedAPIKeyResponse{}),
+	},
+	"DELETE /api/v1/api-keys/:id": {
+		Summary: "Revoke an API key", Tag: "API keys",
+		Response: data(views.APIKeyResponse{}),
+	},
+
+	// Users (admin)
+	"GET /api/v1/users": {
+		Summary: "List users", Tag: "Users",
+		Query: openapi.ListParams(controllers.UserListing), Response: page([]views.UserResponse{}),
+	},
+	"PUT /api/v1/users/:user_id/role": {
+		Summary: "Change a user's role", Tag: "Users",
+		Body: views.UpdateUserRoleRequest{}, Response: data(views.UserResponse{}),
+	},
+	"PUT /api/v1/users/:user_id/status": {
+		Summary: "Activate or deactivate a user", Tag: "Users",
+		Body: views.UpdateUserStatusRequest{}, Response: data(views.UserResponse{}),
+	},
+	"POST /api/v1/users/:user_id/unlock": {
+		Summary: "Clear a user's failed login lockout", Tag: "Users",
+		Body: views.UnlockUserRequest{}, Response: message,
+	},
+	"DELETE /api/v1/users/:user_id/2fa": {
+		Summary: "Turn off a user's two-factor authentication", Tag: "Users",
+		Response: data(views.UserResponse{}),
+	},
+	"GET /api/v1/users/:user_id/api-keys": {
+		Summary: "List a user's API keys", Tag: "Users",
+		Response: data([]views.APIKeyResponse{}),
+	},
+	"POST /api/v1/users/:user_id/api-keys": {
+		Summary: "Create an API key for a user", Tag: "Users",
+		Body: views.CreateAPIKeyRequest{}, Status: http.StatusCreated, Response: data(views.CreatedAPIKeyResponse{}),
+	},
+	"DELETE /api/v1/users/:user_id/api-keys/:id": {
+		Summary: "Revoke a user's API key", Tag: "Users",
+		Response: data(views.APIKeyResponse{}),
+	},
+
+	// Search
+	"GET /api/v1/search": {
+		Summary: "Search players, teams and tournaments by name", Tag: "Search",
+		Query: []openapi.Param{
+			{Name: "q", Description: "Words to find, matched as prefixes ignoring case and accents", Required: true},
+			{Name: "limit", Description: "Most results to return"},
+		},
+		Response: data([]views.SearchResultResponse{}),
+	},
+
+	// Players
+	"GET /api/v1/players": {
+		Summary: "List players", Tag: "Players",
+		Query: openapi.ListParams(controllers.PlayerListing), Response: page([]views.PlayerResponse{}),
+	},
+	"POST /api/v1/players": {
+		Summary: "Create a player", Tag: "Players",
+		Body: views.CreatePlayerRequest{}, Status: http.StatusCreated, Response: data(views.PlayerResponse{}),
+	},
+	"GET /api/v1/players/:id": {
+		Summary: "Get a player", Tag: "Players",
+		Response: data(views.PlayerResponse{}),
+	},
+	"PUT /api/v1/players/:id": {
+		Summary: "Update a player", Tag: "Players",
+		Body: views.UpdatePlayerRequest{}, Response: data(views.PlayerResponse{}),
+	},
+	"DELETE /api/v1/players/:id": {
+		Summary: "Delete a player", Tag: "Players",
+		Response: message,
+	},
+
+	// Matches
+	"GET /api/v1/matches": {
+		Summary: "List matches", Tag: "Matches",
+		Query: openapi.ListParams(services.MatchListing), Response: page([]views.MatchResponse{}),
+	},
+	"POST /api/v1/matches": {
+		Summary: "Create a match", Tag: "Matches",
+		Body: views.MatchRequest{}, Status: http.StatusCreated, Response: data(views.MatchResponse{}),
+	},
+	"GET /api/v1/matches/:id": {
+		Summary: "Get a match", Tag: "Matches",
+		Response: data(views.MatchResponse{}),
+	},
+	"PUT /api/v1/matches/:id": {
+		Summary: "Update a match or record its result", Tag: "Matches",
+		Body: views.MatchRequest{}, Response: data(views.MatchResponse{}),
+	},
+	"DELETE /api/v1/matches/:id": {
+		Summary: "Delete a match", Tag: "Matches",
+		Response: message,
+	},
+
+	// Match results
+	"POST /api/v1/matches/:id/result": {
+		Summary: "Report the result of a friendly match", Tag: "Match results",
+		Body: views.ReportResultRequest{}, Status: http.StatusCreated, Response: data(views.MatchResultResponse{}),
+	},
+	"GET /api/v1/matches/:id/results": {
+		Summary: "List the results reported for a match", Tag: "Match results",
+		Response: data([]views.MatchResultResponse{}),
+	},
+	"GET /api/v1/match-results/pending": {
+		Summary: "List results waiting for the current user to confirm", Tag: "Match results",
+		Response: data([]views.MatchResultResponse{}),
+	},
+	"POST /api/v1/match-results/:id/confirm": {
+		Summary: "Confirm a reported result", Tag: "Match results",
+		Response: data(views.MatchResultResponse{}),
+	},
+	"POST /api/v1/match-results/:id/dispute": {
+		Summary: "Dispute a reported result", Tag: "Match results",
+		Body: views.DisputeResultRequest{}, Response: data(views.MatchResultResponse{}),
+	},
+	"GET /api/v1/match-results/disputed": {
+		Summary: "List disputed results", Tag: "Match results",
+		Response: data([]views.MatchResultResponse{}),
+	},
+	"POST /api/v1/match-results/:id/resolve": {
+		Summary: "Resolve a disputed result", Tag: "Match results",
+		Body: views.ResolveResultRequest{}, Response: data(views.MatchResultResponse{}),
+	},
+	"GET /api/v1/standings": {
+		Summary: "Standings from completed matches", Tag: "Match results",
+		Query: []openapi.Param{
+			{Name: "type", Description: "singles (default) or doubles"},
+			{Name: "tournament_id", Description: "Only this tournament's matches"},
+		},
+		Response: data([]views.StandingResponse{}),
+	},
+
+	// Tournaments
+	"GET /api/v1/tournaments": {
+		Summary: "List tournaments", Tag: "Tournaments",
+		Query: openapi.ListParams(services.TournamentListing), Response: page([]views.TournamentResponse{}),
+	},
+	"POST /api/v1/tournaments": {
+		Summary: "Create a draft tournament", Tag: "Tournaments",
+		Body: views.CreateTournamentRequest{}, Status: http.StatusCreated, Response: data(views.TournamentResponse{}),
+	},
+	"GET /api/v1/tournaments/:id": {
+		Summary: "Get a tournament", Tag: "Tournaments",
+		Response: data(views.TournamentResponse{}),
+	},
+	"PUT /api/v1/tournaments/:id": {
+		Summary: "Edit a tournament's details", Tag: "Tournaments",
+		Body: views.UpdateTournamentRequest{}, Response: data(views.TournamentResponse{}),
+	},
+	"DELETE /api/v1/tournaments/:id": {
+		Summary: "Delete a draft tournament", Tag: "Tournaments",
+		Response: message,
+	},
+	"POST /api/v1/tournaments/:id/open-registration": {
+		Summary: "Open registration", Tag: "Tournaments",
+		Response: data(views.TournamentResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/close-registration": {
+		Summary: "Close registration", Tag: "Tournaments",
+		Response: data(views.TournamentResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/start": {
+		Summary: "Start the tournament", Tag: "Tournaments",
+		Response: data(views.TournamentResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/complete": {
+		Summary: "Complete the tournament", Tag: "Tournaments",
+		Response: data(views.TournamentResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/cancel": {
+		Summary: "Cancel the tournament, refunding entrants", Tag: "Tournaments",
+		Body: views.CancelTournamentRequest{}, Response: data(views.CancellationResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/draw": {
+		Summary: "Draw the first round from checked-in entrants", Tag: "Tournaments",
+		Status: http.StatusCreated, Response: data(views.DrawResponse{}),
+	},
+
+	// Check-in
+	"GET /api/v1/tournaments/:id/check-in": {
+		Summary: "List entrants with their check-in state", Tag: "Check-in",
+		Response: data(views.CheckInListResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/check-in": {
+		Summary: "Check in the current player or their team", Tag: "Check-in",
+		Response: data(views.RegistrationStatusResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/check-in/players/:player_id": {
+		Summary: "Check in a player at the desk", Tag: "Check-in",
+		Response: data(views.RegistrationStatusResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/check-in/teams/:team_id": {
+		Summary: "Check in a team at the desk", Tag: "Check-in",
+		Response: data(views.RegistrationStatusResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/check-in/close": {
+		Summary: "Close check-in, marking no-shows", Tag: "Check-in",
+		Body: views.CloseCheckInRequest{}, Response: data(views.CheckInClosedResponse{}),
+	},
+
+	// Tournament staff
+	"GET /api/v1/tournaments/:id/staff": {
+		Summary: "List the tournament's staff", Tag: "Tournament staff",
+		Response: data(views.TournamentStaffListResponse{}),
+	},
+	"POST /api/v1/tournaments/:id/staff": {
+		Summary: "Give a user a role in the tournament", Tag: "Tournament staff",
+		Body: views.AddStaffRequest{}, Status: http.StatusCreated, Response: data(views.TournamentStaffResponse{}),
+	},
+	"DELETE /api/v1/tournaments/:id/staff/:staff_id": {
+		Summary: "Remove a staff role", Tag: "Tournament staff",
+		Response: message,
+	},
+	"GET /api/v1/tournaments/:id/my-roles": {
+		Summary: "The current user's roles and permissions in the tournament", Tag: "Tournament staff",
+		Response: data(views.TournamentRolesResponse{}),
+	},
+
+	// Registration
+	"POST /api/v1/tournament-registration/:tournament_id": {
+		Summary: "Register the current player", Tag: "Registration",
+		Status: http.StatusCreated, Response: data(views.RegistrationStatusResponse{}),
+	},
+	"DELETE /api/v1/tournament-registration/:tournament_id": {
+		Summary: "Withdraw the current player", Tag: "Registration",
+		Response: message,
+	},
+	"GET /api/v1/my-registrations": {
+		Summary: "List the current player's registrations", Tag: "Registration",
+		Response: data([]views.PlayerRegistrationResponse{}),
+	},
+	"POST /api/v1/team-registration/:tournament_id": {
+		Summary: "Register a team the current player captains", Tag: "Registration",
+		Body: views.RegisterTeamRequest{}, Status: http.StatusCreated, Response: data(views.RegistrationStatusResponse{}),
+	},
+
+	// Refunds
+	"GET /api/v1/refunds": {
+		Summary: "List refunds", Tag: "Refunds",
+		Query: []openapi.Param{
+			{Name: "status", Description: "pending or processed"},
+			{Name: "tournament_id", Description: "Only this tournament's refunds"},
+		},
+		Response: data([]views.RefundResponse{}),
+	},
+	"POST /api/v1/refunds/:id/process": {
+		Summary: "Mark a refund as paid", Tag: "Refunds",
+		Response: data(views.RefundResponse{}),
+	},
+	"GET /api/v1/my-refunds": {
+		Summary: "List the current user's refunds", Tag: "Refunds",
+		Response: data([]views.RefundResponse{}),
+	},
+
+	// Audit
+	"GET /api/v1/audit": {
+		Summary: "List audit log entries, newest first", Tag: "Audit",
+		Query: []openapi.Param{
+			{Name: "resource", Description: "Type of the changed resource"},
+			{Name: "id", Description: "ID of the changed resource"},
+			{Name: "actor_id", Description: "User who made the change"},
+			{Name: "action", Description: "Action, such as tournament.updated"},
+			{Name: "limit", Description: "Most entries to return"},
+		},
+		Response: data([]views.AuditLogResponse{}),
+	},
+
+	// Notifications
+	"GET /api/v1/notifications": {
+		Summary: "List the current user's notifications, newest first", Tag: "Notifications",
+		Query:    []openapi.Param{{Name: "unread", Description: "true for unread notifications only"}},
+		Response: data([]views.NotificationResponse{}),
+	},
+	"POST /api/v1/notifications/:id/read": {
+		Summary: "Mark a notification read", Tag: "Notifications",
+		Response: message,
+	},
+	"POST /api/v1/notifications/read-all": {
+		Summary: "Mark every notification read", Tag: "Notifications",
+		Response: message,
+	},
+
+	// Teams
+	"POST /api/v1/teams": {
+		Summary: "Create a team with a partner", Tag: "Teams",
+		Body: views.CreateTeamRequest{}, Status: http.StatusCreated, Response: data(views.CreatedTeamResponse{}),
+	},
+	"GET /api/v1/my-teams": {
+		Summary: "List the current player's teams", Tag: "Teams",
+		Response: data([]views.TeamResponse{}),
+	},
+	"GET /api/v1/teams/:id": {
+		Summary: "Get a team", Tag: "Teams",
+		Response: data(views.TeamResponse{}),
+	},
+	"PUT /api/v1/teams/:id": {
+		Summary: "Update a team", Tag: "Teams",
+		Body: views.UpdateTeamRequest{}, Response: data(views.TeamResponse{}),
+	},
+	"DELETE /api/v1/teams/:id": {
+		Summary: "Delete a team", Tag: "Teams",
+		Response: message,
+	},
+	"POST /api/v1/teams/:id/members": {
+		Summary: "Add a player to the team", Tag: "Teams",
+		Body: views.TeamPlayerRequest{}, Response: data(views.TeamResponse{}),
+	},
+	"DELETE /api/v1/teams/:id/members/:player_id": {
+		Summary: "Remove a player from the team", Tag: "Teams",
+		Response: data(views.TeamResponse{}),
+	},
+	"POST /api/v1/teams/:id/captain": {
+		Summary: "Hand the captaincy to another member", Tag: "Teams",
+		Body: views.TeamPlayerRequest{}, Response: data(views.TeamResponse{}),
+	},
+
+	// Service
+	"GET /api/v1/openapi.json": {
+		Summary: "This document", Tag: "Service", Public: true,
+		Response: map[string]interface{}{},
+	},
+	"GET /health": {
+		Summary: "Health check", Tag: "Service", Public: true,
+		Response: views.HealthResponse{},
+	},
+}
+
+// message is a response with nothing but a message
+var message = views.SuccessResponse{}
+
+// loginResult is what a correct password gets: tokens, or a challenge when
+// two-factor authentication is on
+var loginResult = openapi.OneOf(views.AuthResponse{}, views.MFAChallengeResponse{})
+
+// data is a response carrying a payload of the type of v
+func data(v interface{}) views.SuccessResponse {
+	return views.SuccessResponse{Data: v}
+}
+
+// page is a response carrying one page of a list
+func page(v interface{}) views.SuccessResponse {
+	return views.SuccessResponse{Data: v, Meta: &views.PaginationMeta{}}
+}
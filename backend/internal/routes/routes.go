@@ -0,0 +1,246 @@
+// Package routes registers the API's handlers with Gin and describes them
+// in an OpenAPI document.
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/apikeys"
+	"badminton-backend/internal/audit"
+	"badminton-backend/internal/controllers"
+	"badminton-backend/internal/mailer"
+	"badminton-backend/internal/middleware"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/notify"
+	"badminton-backend/internal/oidc"
+	"badminton-backend/internal/openapi"
+	"badminton-backend/internal/policy"
+	"badminton-backend/internal/repositories"
+	"badminton-backend/internal/search"
+	"badminton-backend/internal/services"
+	"badminton-backend/internal/sessions"
+	"badminton-backend/internal/throttle"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+// Dependencies are what the handlers need besides the database
+type Dependencies struct {
+	Mailer mailer.Mailer
+	Tokens *usertokens.Manager
+	OIDC   *oidc.Provider // nil when single sign-on is off
+	AppURL string
+}
+
+// Register adds the API routes to r and returns their OpenAPI document,
+// which is also served at /api/v1/openapi.json. Routes added to r later
+// are not described.
+func Register(r *gin.Engine, db *gorm.DB, deps Dependencies) (*openapi.Document, error) {
+	var doc *openapi.Document
+
+	// Initialize controllers
+	sessionManager := sessions.NewManager(db)
+	apiKeyManager := apikeys.NewManager(db)
+	auditLog := audit.New(db)
+	accessPolicy := policy.New(db)
+	authController := controllers.NewAuthController(db, controllers.AuthServices{
+		Sessions: sessionManager,
+		APIKeys:  apiKeyManager,
+		Tokens:   deps.Tokens,
+		Mailer:   deps.Mailer,
+		Guard:    throttle.New(db),
+		Audit:    auditLog,
+		OIDC:     deps.OIDC,
+		AppURL:   deps.AppURL,
+	})
+	playerController := controllers.NewPlayerController(db, accessPolicy)
+	notifier := notify.New(db)
+	store := repositories.NewStore(db)
+	matchController := controllers.NewMatchController(services.NewMatchService(store), accessPolicy, auditLog)
+	tournamentController := controllers.NewTournamentController(services.NewTournamentService(store, notifier), auditLog)
+	tournamentRegController := controllers.NewTournamentRegistrationController(services.NewRegistrationService(store), auditLog)
+	teamController := controllers.NewTeamController(db)
+	checkInController := controllers.NewCheckInController(services.NewCheckInService(store), auditLog)
+	notificationController := controllers.NewNotificationController(db)
+	refundController := controllers.NewRefundController(db, auditLog)
+	staffController := controllers.NewTournamentStaffController(db, accessPolicy, auditLog)
+	matchResultController := controllers.NewMatchResultController(db, accessPolicy, notifier, auditLog)
+	apiKeyController := controllers.NewAPIKeyController(db, apiKeyManager, auditLog)
+	auditController := controllers.NewAuditController(db)
+	searchController := controllers.NewSearchController(search.New(db))
+
+	// Tournament-scoped permission checks
+	tournamentParam := middleware.TournamentFromParam("id")
+	matchTournament := middleware.TournamentFromMatch("id")
+	manageTournament := middleware.RequireTournamentPermission(db, models.PermManageTournament, tournamentParam)
+	manageEntries := middleware.RequireTournamentPermission(db, models.PermManageEntries, tournamentParam)
+	manageStaff := middleware.RequireTournamentPermission(db, models.PermManageStaff, tournamentParam)
+
+	// API v1 routes
+	v1 := r.Group("/api/v1")
+	{
+		// This API's OpenAPI description
+		v1.GET("/openapi.json", func(c *gin.Context) {
+			c.JSON(http.StatusOK, doc)
+		})
+
+		// Auth routes (public)
+		v1.POST("/register", authController.Register)
+		v1.POST("/login", authController.Login)
+		v1.POST("/login/mfa", authController.LoginMFA)
+		v1.POST("/token/refresh", authController.RefreshToken)
+		v1.POST("/verify-email", authController.VerifyEmail)
+		v1.POST("/forgot-password", authController.ForgotPassword)
+		v1.POST("/reset-password", authController.ResetPassword)
+		v1.POST("/claim-account", authController.ClaimAccount)
+
+		// Single sign-on routes (public)
+		v1.GET("/auth/oidc/login", authController.OIDCLogin)
+		v1.GET("/auth/oidc/callback", authController.OIDCCallback)
+		v1.POST("/auth/oidc/exchange", authController.ExchangeOIDCLogin)
+
+		// Protected routes
+		authorized := v1.Group("/")
+		authorized.Use(middleware.AuthMiddleware(db))
+		{
+			// Profile routes
+			authorized.GET("/profile", authController.GetProfile)
+			authorized.PUT("/profile", middleware.RequireSession(), authController.UpdateProfile)
+			authorized.POST("/change-password", middleware.RequireSession(), authController.ChangePassword)
+			authorized.POST("/logout", middleware.RequireSession(), authController.Logout)
+			authorized.POST("/logout-all", middleware.RequireSession(), authController.LogoutAll)
+			authorized.POST("/resend-verification", middleware.RequireSession(), authController.ResendVerification)
+
+			// Two-factor authentication routes
+			authorized.GET("/2fa", authController.GetTwoFactorStatus)
+			authorized.POST("/2fa/setup", middleware.RequireSession(), authController.SetupTwoFactor)
+			authorized.POST("/2fa/enable", middleware.RequireSession(), authController.EnableTwoFactor)
+			authorized.POST("/2fa/disable", middleware.RequireSession(), authController.DisableTwoFactor)
+			authorized.POST("/2fa/recovery-codes", middleware.RequireSession(), authController.RegenerateRecoveryCodes)
+
+			// Linked single sign-on accounts
+			authorized.GET("/auth/oidc/identities", authController.GetOIDCIdentities)
+			authorized.POST("/auth/oidc/link", middleware.RequireSession(), authController.LinkOIDCIdentity)
+			authorized.DELETE("/auth/oidc/identities/:id", middleware.RequireSession(), authController.UnlinkOIDCIdentity)
+
+			// API keys
+			authorized.GET("/api-keys", apiKeyController.GetMyAPIKeys)
+			authorized.POST("/api-keys", middleware.RequireSession(), apiKeyController.CreateAPIKey)
+			authorized.DELETE("/api-keys/:id", middleware.RequireSession(), apiKeyController.RevokeAPIKey)
+
+			// Admin-only user management routes
+			authorized.GET("/users", middleware.RequireAdmin(), authController.GetAllUsers)
+			authorized.PUT("/users/:user_id/role", middleware.RequireAdmin(), authController.UpdateUserRole)
+			authorized.PUT("/users/:user_id/status", middleware.RequireAdmin(), authController.UpdateUserStatus)
+			authorized.POST("/users/:user_id/unlock", middleware.RequireAdmin(), authController.UnlockUser)
+			authorized.DELETE("/users/:user_id/2fa", middleware.RequireAdmin(), authController.ResetUserTwoFactor)
+			authorized.GET("/users/:user_id/api-keys", middleware.RequireAdmin(), apiKeyController.GetUserAPIKeys)
+			authorized.POST("/users/:user_id/api-keys", middleware.RequireAdmin(), middleware.RequireSession(), apiKeyController.CreateUserAPIKey)
+			authorized.DELETE("/users/:user_id/api-keys/:id", middleware.RequireAdmin(), apiKeyController.RevokeUserAPIKey)
+
+			// Search across players, teams and tournaments
+			authorized.GET("/search", searchController.Search)
+
+			// Player routes (keep for backward compatibility)
+			authorized.GET("/players", playerController.GetPlayers)
+			authorized.POST("/players", playerController.CreatePlayer)
+			authorized.GET("/players/:id", playerController.GetPlayer)
+			authorized.PUT("/players/:id", playerController.UpdatePlayer)
+			authorized.DELETE("/players/:id", playerController.DeletePlayer)
+
+			// Match routes
+			authorized.GET("/matches", matchController.GetMatches)
+			authorized.POST("/matches", matchController.CreateMatch)
+			authorized.GET("/matches/:id", matchController.GetMatch)
+			authorized.PUT("/matches/:id", middleware.RequireTournamentPermission(db, models.PermScoreMatches, matchTournament), matchController.UpdateMatch)
+			authorized.DELETE("/matches/:id", middleware.RequireTournamentPermission(db, models.PermManageMatches, matchTournament), matchController.DeleteMatch)
+
+			// Self-reported results of friendly matches
+			authorized.POST("/matches/:id/result", matchResultController.ReportResult)
+			authorized.GET("/matches/:id/results", matchResultController.GetMatchResults)
+			authorized.GET("/match-results/pending", matchResultController.GetPendingResults)
+			authorized.POST("/match-results/:id/confirm", matchResultController.ConfirmResult)
+			authorized.POST("/match-results/:id/dispute", matchResultController.DisputeResult)
+			authorized.GET("/match-results/disputed", middleware.RequireAdmin(), matchResultController.GetDisputedResults)
+			authorized.POST("/match-results/:id/resolve", middleware.RequireAdmin(), matchResultController.ResolveResult)
+			authorized.GET("/standings", matchResultController.GetStandings)
+
+			// Tournament routes
+			authorized.GET("/tournaments", tournamentController.GetTournaments)
+			authorized.POST("/tournaments", middleware.RequireAdmin(), tournamentController.CreateTournament)
+			authorized.GET("/tournaments/:id", tournamentController.GetTournament)
+			authorized.PUT("/tournaments/:id", manageTournament, tournamentController.UpdateTournament)
+			authorized.DELETE("/tournaments/:id", manageTournament, tournamentController.DeleteTournament)
+
+			// Tournament lifecycle routes
+			authorized.POST("/tournaments/:id/open-registration", manageTournament, tournamentController.OpenRegistration)
+			authorized.POST("/tournaments/:id/close-registration", manageTournament, tournamentController.CloseRegistration)
+			authorized.POST("/tournaments/:id/start", manageTournament, tournamentController.StartTournament)
+			authorized.POST("/tournaments/:id/complete", manageTournament, tournamentController.CompleteTournament)
+			authorized.POST("/tournaments/:id/cancel", manageTournament, tournamentController.CancelTournament)
+
+			// Tournament check-in and draw routes
+			authorized.GET("/tournaments/:id/check-in", manageEntries, checkInController.GetCheckIns)
+			authorized.POST("/tournaments/:id/check-in", middleware.RequirePlayer(), checkInController.SelfCheckIn)
+			authorized.POST("/tournaments/:id/check-in/players/:player_id", manageEntries, checkInController.DeskCheckInPlayer)
+			authorized.POST("/tournaments/:id/check-in/teams/:team_id", manageEntries, checkInController.DeskCheckInTeam)
+			authorized.POST("/tournaments/:id/check-in/close", manageEntries, checkInController.CloseCheckIn)
+			authorized.POST("/tournaments/:id/draw", middleware.RequireTournamentPermission(db, models.PermManageDraw, tournamentParam), tournamentController.GenerateDraw)
+
+			// Tournament staff routes
+			authorized.GET("/tournaments/:id/staff", staffController.GetStaff)
+			authorized.POST("/tournaments/:id/staff", manageStaff, staffController.AddStaff)
+			authorized.DELETE("/tournaments/:id/staff/:staff_id", manageStaff, staffController.RemoveStaff)
+			authorized.GET("/tournaments/:id/my-roles", staffController.GetMyTournamentRoles)
+
+			// Tournament registration routes
+			authorized.POST("/tournament-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.RegisterForTournament)
+			authorized.DELETE("/tournament-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.UnregisterFromTournament)
+			authorized.GET("/my-registrations", middleware.RequirePlayer(), tournamentRegController.GetMyRegistrations)
+
+			// Refund routes
+			authorized.GET("/refunds", middleware.RequireAdmin(), refundController.GetRefunds)
+			authorized.POST("/refunds/:id/process", middleware.RequireAdmin(), refundController.ProcessRefund)
+			authorized.GET("/my-refunds", refundController.GetMyRefunds)
+
+			// Audit routes
+			authorized.GET("/audit", middleware.RequireAdmin(), auditController.GetAuditLogs)
+
+			// Notification routes
+			authorized.GET("/notifications", notificationController.GetNotifications)
+			authorized.POST("/notifications/:id/read", notificationController.MarkNotificationRead)
+			authorized.POST("/notifications/read-all", notificationController.MarkAllNotificationsRead)
+
+			// Team routes
+			authorized.POST("/teams", middleware.RequirePlayer(), teamController.CreateTeam)
+			authorized.GET("/my-teams", middleware.RequirePlayer(), teamController.GetMyTeams)
+			authorized.GET("/teams/:id", teamController.GetTeam)
+			authorized.PUT("/teams/:id", middleware.RequirePlayer(), teamController.UpdateTeam)
+			authorized.DELETE("/teams/:id", middleware.RequirePlayer(), teamController.DeleteTeam)
+			authorized.POST("/teams/:id/members", middleware.RequirePlayer(), teamController.AddTeamMember)
+			authorized.DELETE("/teams/:id/members/:player_id", middleware.RequirePlayer(), teamController.RemoveTeamMember)
+			authorized.POST("/teams/:id/captain", middleware.RequirePlayer(), teamController.TransferCaptain)
+			authorized.POST("/team-registration/:tournament_id", middleware.RequirePlayer(), tournamentRegController.RegisterTeamForTournament)
+		}
+	}
+
+	// Public keys for verifying our tokens
+	r.GET("/.well-known/jwks.json", authController.GetJWKS)
+
+	// Health check
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, views.HealthResponse{Status: "ok"})
+	})
+
+	// Describe every route registered above
+	var err error
+	doc, err = openapi.Build(Info, r.Routes(), Endpoints, views.ErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+
+}
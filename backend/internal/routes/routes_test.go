@@ -0,0 +1,830 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"badminton-backend/internal/config"
+	"badminton-backend/internal/database"
+	"badminton-backend/internal/invites"
+	"badminton-backend/internal/mailer"
+	"badminton-backend/internal/middleware"
+	"badminton-backend/internal/migrations"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/oidc"
+	"badminton-backend/internal/oidc/mockissuer"
+	"badminton-backend/internal/openapi"
+	"badminton-backend/internal/throttle"
+	"badminton-backend/internal/totp"
+	"badminton-backend/internal/usertokens"
+	"badminton-backend/internal/views"
+)
+
+// outbox keeps the emails the handlers send
+type outbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(msg mailer.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// sent counts the emails sent so far
+func (o *outbox) sent() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.messages)
+}
+
+// wait blocks until at least n emails have been sent, for handlers that
+// send in the background
+func (o *outbox) wait(t *testing.T, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if o.sent() >= n {
+			return
+		}
+	}
+	t.Fatalf("expected %d emails to be sent", n)
+}
+
+var linkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// token returns the token in the last link emailed to the address
+func (o *outbox) token(t *testing.T, to string) string {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To != to {
+			continue
+		}
+		if match := linkToken.FindStringSubmatch(o.messages[i].Body); match != nil {
+			token, err := url.QueryUnescape(match[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}
+	}
+	t.Fatalf("no link emailed to %s", to)
+	return ""
+}
+
+// apiClient sends requests to the API and checks every response against
+// its OpenAPI document
+type apiClient struct {
+	t      *testing.T
+	router *gin.Engine
+	doc    *openapi.Document
+	route  string          // Gin path of the last request
+	called map[string]bool // routes whose success response was checked
+}
+
+// call sends the request and fails the test unless the response has the
+// wanted status and matches the document. The response's data is decoded
+// into out when it is not nil.
+func (c *apiClient) call(method, path, token string, body interface{}, want int, out interface{}) {
+	c.t.Helper()
+	c.send(method, path, token, body, want, out)
+}
+
+// send is call with cookies, returning the response for its headers
+func (c *apiClient) send(method, path, token string, body interface{}, want int, out interface{}, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	res := httptest.NewRecorder()
+	c.route = ""
+	c.router.ServeHTTP(res, req)
+
+	if res.Code != want {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", method, path, res.Code, want, res.Body.String())
+	}
+	if err := c.doc.ValidateResponse(method, c.route, res.Code, res.Body.Bytes()); err != nil {
+		c.t.Fatal(err)
+	}
+	if res.Code < 400 {
+		c.called[method+" "+c.route] = true
+	}
+
+	if out != nil {
+		envelope := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(res.Body.Bytes(), &envelope); err != nil {
+			c.t.Fatal(err)
+		}
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			c.t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return res
+}
+
+// provider is the mock OpenID provider the API signs users in with
+type provider struct {
+	*httptest.Server
+}
+
+// signIn follows an authorization URL as the user with the email and
+// returns the API callback path the provider redirects back to
+func (p *provider) signIn(t *testing.T, authorizationURL, email string) string {
+	t.Helper()
+
+	client := p.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	res, err := client.Get(authorizationURL + "&login_hint=" + url.QueryEscape(email))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("provider sign-in: status %d", res.StatusCode)
+	}
+
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return callback.RequestURI()
+}
+
+// stateCookie returns the single sign-on state cookie the response set
+func stateCookie(t *testing.T, res *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == "oidc_state" && cookie.Value != "" {
+			return cookie
+		}
+	}
+	t.Fatal("no single sign-on state cookie was set")
+	return nil
+}
+
+// redirectQuery returns a query parameter of the response's redirect
+func redirectQuery(t *testing.T, res *httptest.ResponseRecorder, name string) string {
+	t.Helper()
+	location, err := url.Parse(res.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query().Get(name)
+}
+
+func newAPIClient(t *testing.T) (*apiClient, *gorm.DB, *outbox, *provider) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := database.ConfigFrom(config.Database{
+		Driver: database.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	db, err := database.Open(cfg, &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := migrations.New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenConfig, err := middleware.LoadTokenConfig(config.JWT{
+		Secret:     "contract-test-secret-that-is-long-enough",
+		TTL:        time.Hour,
+		RefreshTTL: 24 * time.Hour,
+		Issuer:     "badminton-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := middleware.ConfigureTokens(tokenConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &apiClient{t: t, router: gin.New(), called: map[string]bool{}}
+	client.router.Use(func(c *gin.Context) {
+		client.route = c.FullPath()
+	})
+
+	var issuer *mockissuer.Issuer
+	sso := &provider{httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.ServeHTTP(w, r)
+	}))}
+	t.Cleanup(sso.Close)
+	if issuer, err = mockissuer.New(sso.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := &outbox{}
+	client.doc, err = Register(client.router, db, Dependencies{
+		Mailer: mail,
+		Tokens: usertokens.NewManager(db),
+		OIDC: oidc.New(oidc.Config{
+			Issuer:      sso.URL,
+			ClientID:    "badminton-test",
+			RedirectURL: "http://api.test/api/v1/auth/oidc/callback",
+			Scopes:      []string{"openid", "email", "profile"},
+		}),
+		AppURL: "http://app.test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, db, mail, sso
+}
+
+// TestResponsesMatchOpenAPI walks through a tournament's life and checks
+// every response against the OpenAPI document
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	api, db, mail, sso := newAPIClient(t)
+
+	// Routes this build cannot serve successfully, with the reason. Every
+	// other route must get a checked success response below.
+	uncheckedRoutes := map[string]string{}
+
+	api.call("GET", "/health", "", nil, http.StatusOK, nil)
+	api.call("GET", "/.well-known/jwks.json", "", nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/openapi.json", "", nil, http.StatusOK, nil)
+
+	// Players sign up and verify their email
+	register := func(username string) views.AuthResponse {
+		var auth views.AuthResponse
+		api.call("POST", "/api/v1/register", "", views.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "password",
+			FullName: strings.ToUpper(username[:1]) + username[1:] + " Nguyễn",
+		}, http.StatusCreated, &auth)
+		return auth
+	}
+	alice, bob, carol, dan := register("alice"), register("bob"), register("carol"), register("dan")
+	for _, username := range []string{"alice", "bob", "carol", "dan"} {
+		api.call("POST", "/api/v1/verify-email", "", views.TokenRequest{Token: mail.token(t, username+"@example.com")}, http.StatusOK, nil)
+	}
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "alice", Password: "wrong"}, http.StatusUnauthorized, nil)
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "alice", Password: "password"}, http.StatusOK, &alice)
+	api.call("POST", "/api/v1/token/refresh", "", views.RefreshTokenRequest{RefreshToken: alice.RefreshToken}, http.StatusOK, &alice)
+
+	sent := mail.sent()
+	api.call("POST", "/api/v1/forgot-password", "", views.ForgotPasswordRequest{Email: "bob@example.com"}, http.StatusOK, nil)
+	mail.wait(t, sent+1)
+	api.call("POST", "/api/v1/reset-password", "", views.ResetPasswordRequest{
+		Token:       mail.token(t, "bob@example.com"),
+		NewPassword: "new-password",
+	}, http.StatusOK, nil)
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "bob", Password: "new-password"}, http.StatusOK, &bob)
+	api.call("POST", "/api/v1/change-password", bob.Token, views.ChangePasswordRequest{
+		CurrentPassword: "new-password",
+		NewPassword:     "password",
+	}, http.StatusOK, &bob)
+
+	api.call("GET", "/api/v1/profile", alice.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/profile", "", nil, http.StatusUnauthorized, nil)
+	api.call("PUT", "/api/v1/profile", alice.Token, views.UpdateProfileRequest{Ranking: 1200}, http.StatusOK, nil)
+	api.call("GET", "/api/v1/2fa", alice.Token, nil, http.StatusOK, nil)
+
+	// A converted legacy player claims their account from the emailed invite
+	legacy := models.User{Username: "erin", Email: "erin@example.com", FullName: "Erin Trần", Role: models.RolePlayer, IsActive: true, ClaimPending: true}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invites.NewSender(db, usertokens.NewManager(db), mail, "http://app.test").SendPending(); err != nil {
+		t.Fatal(err)
+	}
+	var erin views.AuthResponse
+	api.call("POST", "/api/v1/claim-account", "", views.ClaimAccountRequest{Token: mail.token(t, "erin@example.com"), Password: "password", Username: "Alice"}, http.StatusConflict, nil)
+	api.call("POST", "/api/v1/claim-account", "", views.ClaimAccountRequest{Token: mail.token(t, "erin@example.com"), Password: "password"}, http.StatusOK, &erin)
+
+	// Single sign-on through the mock provider creates an account
+	login := api.send("GET", "/api/v1/auth/oidc/login", "", nil, http.StatusFound, nil)
+	callback := api.send("GET", sso.signIn(t, login.Header().Get("Location"), "frank@example.com"), "", nil, http.StatusFound, nil, stateCookie(t, login))
+	var frank views.AuthResponse
+	api.call("POST", "/api/v1/auth/oidc/exchange", "", views.OIDCExchangeRequest{Code: redirectQuery(t, callback, "code")}, http.StatusOK, &frank)
+	if frank.User.Email != "frank@example.com" {
+		t.Fatalf("single sign-on signed in %+v", frank.User)
+	}
+
+	// and links a provider account to an existing one
+	var link views.AuthorizationURLResponse
+	linkStart := api.send("POST", "/api/v1/auth/oidc/link", alice.Token, nil, http.StatusOK, &link)
+	callback = api.send("GET", sso.signIn(t, link.AuthorizationURL, "alice.work@example.com"), "", nil, http.StatusFound, nil, stateCookie(t, linkStart))
+	if redirectQuery(t, callback, "linked") != "1" {
+		t.Fatalf("linking redirected to %s", callback.Header().Get("Location"))
+	}
+	var identities []views.IdentityResponse
+	api.call("GET", "/api/v1/auth/oidc/identities", alice.Token, nil, http.StatusOK, &identities)
+	if len(identities) != 1 || identities[0].Email != "alice.work@example.com" {
+		t.Fatalf("alice's linked identities are %+v", identities)
+	}
+	api.call("DELETE", "/api/v1/auth/oidc/identities/"+id(identities[0].ID), alice.Token, nil, http.StatusOK, nil)
+
+	// An admin enrolls in two-factor authentication, which admin routes need
+	admin := register("admin")
+	api.call("POST", "/api/v1/resend-verification", admin.Token, nil, http.StatusOK, nil)
+	if err := db.Model(&models.User{}).Where("id = ?", admin.User.ID).Update("role", models.RoleAdmin).Error; err != nil {
+		t.Fatal(err)
+	}
+	api.call("GET", "/api/v1/users", admin.Token, nil, http.StatusForbidden, nil)
+	var setup views.TwoFactorSetupResponse
+	api.call("POST", "/api/v1/2fa/setup", admin.Token, nil, http.StatusOK, &setup)
+	code, err := totp.Code(setup.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recovery views.RecoveryCodesResponse
+	api.call("POST", "/api/v1/2fa/enable", admin.Token, views.TwoFactorCodeRequest{Code: code}, http.StatusOK, &recovery)
+	var challenge views.MFAChallengeResponse
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "admin", Password: "password"}, http.StatusOK, &challenge)
+	api.call("POST", "/api/v1/login/mfa", "", views.LoginMFARequest{MFAToken: challenge.MFAToken, Code: recovery.RecoveryCodes[0]}, http.StatusOK, &admin)
+	code, err = totp.Code(setup.Secret, totp.Step(time.Now())+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.call("POST", "/api/v1/2fa/recovery-codes", admin.Token, views.TwoFactorCodeRequest{Code: code}, http.StatusOK, &recovery)
+
+	// Users and API keys
+	api.call("GET", "/api/v1/users?sort=-created_at&limit=2", admin.Token, nil, http.StatusOK, nil)
+	carolPath := "/api/v1/users/" + id(carol.User.ID)
+	api.call("PUT", carolPath+"/status", admin.Token, views.UpdateUserStatusRequest{IsActive: boolPtr(true)}, http.StatusOK, nil)
+	api.call("PUT", carolPath+"/role", admin.Token, views.UpdateUserRoleRequest{Role: string(models.RolePlayer)}, http.StatusOK, nil)
+	api.call("POST", carolPath+"/unlock", admin.Token, views.UnlockUserRequest{}, http.StatusOK, nil)
+	api.call("POST", "/api/v1/2fa/setup", carol.Token, nil, http.StatusOK, &setup)
+	code, err = totp.Code(setup.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.call("POST", "/api/v1/2fa/enable", carol.Token, views.TwoFactorCodeRequest{Code: code}, http.StatusOK, nil)
+	api.call("DELETE", carolPath+"/2fa", admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "carol", Password: "password"}, http.StatusOK, &carol)
+
+	var key views.CreatedAPIKeyResponse
+	api.call("POST", "/api/v1/api-keys", alice.Token, views.CreateAPIKeyRequest{Name: "scores", Scope: models.APIKeyRead}, http.StatusCreated, &key)
+	api.call("GET", "/api/v1/api-keys", alice.Token, nil, http.StatusOK, nil)
+	api.call("DELETE", "/api/v1/api-keys/"+id(key.APIKey.ID), alice.Token, nil, http.StatusOK, nil)
+	api.call("POST", carolPath+"/api-keys", admin.Token, views.CreateAPIKeyRequest{Name: "bot", Scope: models.APIKeyRead}, http.StatusCreated, &key)
+	api.call("GET", carolPath+"/api-keys", admin.Token, nil, http.StatusOK, nil)
+	api.call("DELETE", carolPath+"/api-keys/"+id(key.APIKey.ID), admin.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/users/carol/api-keys", admin.Token, nil, http.StatusBadRequest, nil)
+	api.call("GET", "/api/v1/users/999999/api-keys", admin.Token, nil, http.StatusNotFound, nil)
+	api.call("POST", "/api/v1/api-keys", admin.Token, views.CreateAPIKeyRequest{Name: "ops", Scope: models.APIKeyAdmin, ExpiresInDays: 90}, http.StatusBadRequest, nil)
+	api.call("POST", "/api/v1/api-keys", admin.Token, views.CreateAPIKeyRequest{Name: "ops", Scope: models.APIKeyAdmin}, http.StatusCreated, &key)
+	if key.APIKey.ExpiresAt == nil {
+		t.Error("admin API key was created without an expiry")
+	}
+	var bobKey views.CreatedAPIKeyResponse
+	api.call("POST", "/api/v1/api-keys", bob.Token, views.CreateAPIKeyRequest{Name: "scores", Scope: models.APIKeyRead}, http.StatusCreated, &bobKey)
+
+	// Players
+	api.call("GET", "/api/v1/players?sort=name", alice.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/players/"+id(bob.User.ID), alice.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/players/0", alice.Token, nil, http.StatusNotFound, nil)
+	var dave views.PlayerResponse
+	api.call("POST", "/api/v1/players", admin.Token, views.CreatePlayerRequest{
+		Name: "Dave Trần", Email: "dave@example.com", Username: "dave", Password: "password",
+	}, http.StatusCreated, &dave)
+	api.call("PUT", "/api/v1/players/"+id(dave.ID), admin.Token, views.UpdatePlayerRequest{Ranking: intPtr(900)}, http.StatusOK, nil)
+	api.call("DELETE", "/api/v1/players/"+id(dave.ID), admin.Token, nil, http.StatusOK, nil)
+
+	// Teams
+	var team views.CreatedTeamResponse
+	api.call("POST", "/api/v1/teams", alice.Token, views.CreateTeamRequest{Name: "Shuttle Stars", PartnerID: bob.User.ID}, http.StatusCreated, &team)
+	teamPath := "/api/v1/teams/" + id(team.TeamID)
+	api.call("GET", "/api/v1/my-teams", bob.Token, nil, http.StatusOK, nil)
+	api.call("GET", teamPath, carol.Token, nil, http.StatusOK, nil)
+	api.call("PUT", teamPath, alice.Token, views.UpdateTeamRequest{Description: stringPtr("Weekend doubles")}, http.StatusOK, nil)
+	api.call("POST", teamPath+"/captain", alice.Token, views.TeamPlayerRequest{PlayerID: bob.User.ID}, http.StatusOK, nil)
+	api.call("DELETE", teamPath+"/members/"+id(alice.User.ID), bob.Token, nil, http.StatusOK, nil)
+	api.call("POST", teamPath+"/members", bob.Token, views.TeamPlayerRequest{PlayerID: alice.User.ID}, http.StatusOK, nil)
+
+	// A singles tournament from draft to completion
+	var spring views.TournamentResponse
+	api.call("POST", "/api/v1/tournaments", admin.Token, views.CreateTournamentRequest{
+		Name:                 "Spring Open",
+		Type:                 models.TournamentSingles,
+		StartDate:            time.Now().Add(30 * time.Minute),
+		EndDate:              time.Now().Add(8 * time.Hour),
+		MaxPlayers:           8,
+		CheckInWindowMinutes: 60,
+	}, http.StatusCreated, &spring)
+	springPath := "/api/v1/tournaments/" + id(spring.ID)
+	api.call("GET", "/api/v1/tournaments?status=draft", alice.Token, nil, http.StatusOK, nil)
+	api.call("PUT", springPath, admin.Token, views.UpdateTournamentRequest{}, http.StatusOK, nil)
+	api.call("POST", springPath+"/open-registration", admin.Token, nil, http.StatusOK, nil)
+	api.call("GET", springPath, alice.Token, nil, http.StatusOK, nil)
+
+	api.call("POST", "/api/v1/tournament-registration/"+id(spring.ID), alice.Token, nil, http.StatusCreated, nil)
+	api.call("POST", "/api/v1/tournament-registration/"+id(spring.ID), alice.Token, nil, http.StatusConflict, nil)
+	api.call("POST", "/api/v1/tournament-registration/"+id(spring.ID), bob.Token, nil, http.StatusCreated, nil)
+	api.call("POST", "/api/v1/tournament-registration/"+id(spring.ID), carol.Token, nil, http.StatusCreated, nil)
+	api.call("DELETE", "/api/v1/tournament-registration/"+id(spring.ID), carol.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/my-registrations", alice.Token, nil, http.StatusOK, nil)
+
+	var staff views.TournamentStaffResponse
+	api.call("POST", springPath+"/staff", admin.Token, views.AddStaffRequest{UserID: carol.User.ID, Role: models.StaffDesk}, http.StatusCreated, &staff)
+	api.call("GET", springPath+"/staff", alice.Token, nil, http.StatusOK, nil)
+	api.call("GET", springPath+"/my-roles", carol.Token, nil, http.StatusOK, nil)
+
+	api.call("POST", springPath+"/close-registration", admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", springPath+"/check-in", alice.Token, nil, http.StatusOK, nil)
+	api.call("POST", springPath+"/check-in/players/"+id(bob.User.ID), carol.Token, nil, http.StatusOK, nil)
+	api.call("GET", springPath+"/check-in", carol.Token, nil, http.StatusOK, nil)
+	api.call("POST", springPath+"/check-in/close", carol.Token, views.CloseCheckInRequest{}, http.StatusOK, nil)
+	api.call("DELETE", springPath+"/staff/"+id(staff.ID), admin.Token, nil, http.StatusOK, nil)
+
+	api.call("POST", springPath+"/draw", admin.Token, nil, http.StatusCreated, nil)
+	api.call("POST", springPath+"/start", admin.Token, nil, http.StatusOK, nil)
+	var drawn []views.MatchResponse
+	api.call("GET", "/api/v1/matches?tournament_id="+id(spring.ID), alice.Token, nil, http.StatusOK, &drawn)
+	if len(drawn) != 1 || len(drawn[0].Side1.Team.Members) != 1 || len(drawn[0].Side2.Team.Members) != 1 {
+		t.Fatalf("drawing two players made %+v", drawn)
+	}
+	var final views.MatchResponse
+	api.call("PUT", "/api/v1/matches/"+id(drawn[0].ID), admin.Token, map[string]interface{}{
+		"status":           models.MatchCompleted,
+		"player1_score":    21,
+		"player2_score":    15,
+		"winner_player_id": drawn[0].Side1.Team.Members[0].ID,
+	}, http.StatusOK, &final)
+	if final.WinnerSide == nil || *final.WinnerSide != 1 || final.Side1.Score != 21 {
+		t.Fatalf("scoring the final gave %+v", final)
+	}
+	api.call("POST", springPath+"/complete", admin.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/standings?tournament_id="+id(spring.ID), alice.Token, nil, http.StatusOK, nil)
+
+	// A doubles tournament that is cancelled and refunded
+	var autumn views.TournamentResponse
+	api.call("POST", "/api/v1/tournaments", admin.Token, views.CreateTournamentRequest{
+		Name:      "Autumn Doubles",
+		Type:      models.TournamentDoubles,
+		StartDate: time.Now().Add(30 * 24 * time.Hour),
+		EndDate:   time.Now().Add(31 * 24 * time.Hour),
+		MaxTeams:  8,
+		EntryFee:  100000,
+	}, http.StatusCreated, &autumn)
+	autumnPath := "/api/v1/tournaments/" + id(autumn.ID)
+	api.call("POST", autumnPath+"/open-registration", admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/team-registration/"+id(autumn.ID), bob.Token, views.RegisterTeamRequest{TeamID: team.TeamID}, http.StatusCreated, nil)
+	api.call("POST", autumnPath+"/check-in/teams/"+id(team.TeamID), admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", autumnPath+"/cancel", admin.Token, views.CancelTournamentRequest{Reason: "Hall unavailable"}, http.StatusOK, nil)
+
+	var refunds []views.RefundResponse
+	api.call("GET", "/api/v1/refunds?status=pending", admin.Token, nil, http.StatusOK, &refunds)
+	if len(refunds) == 0 {
+		t.Fatal("cancelling a paid tournament made no refunds")
+	}
+	api.call("POST", "/api/v1/refunds/"+id(refunds[0].ID)+"/process", admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/refunds/"+id(refunds[0].ID)+"/process", admin.Token, nil, http.StatusConflict, nil)
+	var processed []views.AuditLogResponse
+	api.call("GET", "/api/v1/audit?resource=refund&id="+id(refunds[0].ID), admin.Token, nil, http.StatusOK, &processed)
+	if len(processed) != 1 || processed[0].Action != "refund.processed" {
+		t.Fatalf("processing a refund was audited as %+v", processed)
+	}
+	api.call("GET", "/api/v1/my-refunds", bob.Token, nil, http.StatusOK, nil)
+
+	var draft views.TournamentResponse
+	api.call("POST", "/api/v1/tournaments", admin.Token, views.CreateTournamentRequest{Name: "Scratch", Type: models.TournamentSingles}, http.StatusCreated, &draft)
+	api.call("DELETE", "/api/v1/tournaments/"+id(draft.ID), admin.Token, nil, http.StatusOK, nil)
+
+	// Self-reported friendly results
+	var friendly views.MatchResponse
+	api.call("POST", "/api/v1/matches", alice.Token, map[string]interface{}{
+		"type": models.MatchSingles, "player1_id": alice.User.ID, "player2_id": bob.User.ID,
+	}, http.StatusCreated, &friendly)
+	if members := friendly.Side2.Team.Members; len(members) != 1 || members[0].ID != bob.User.ID || friendly.Side2.Team.Name != "Bob Nguyễn" {
+		t.Fatalf("the friendly match's second side is %+v", friendly.Side2)
+	}
+	matchPath := "/api/v1/matches/" + id(friendly.ID)
+	api.call("PUT", matchPath, admin.Token, map[string]interface{}{
+		"status": models.MatchCompleted, "player1_score": 21, "player2_score": 10, "winner_player_id": carol.User.ID,
+	}, http.StatusBadRequest, nil)
+	var result views.MatchResultResponse
+	api.call("POST", matchPath+"/result", alice.Token, views.ReportResultRequest{Side1Score: intPtr(21), Side2Score: intPtr(19)}, http.StatusCreated, &result)
+	api.call("GET", matchPath+"/results", alice.Token, nil, http.StatusOK, nil)
+	api.call("POST", matchPath+"/result", bob.Token, views.ReportResultRequest{Side1Score: intPtr(19), Side2Score: intPtr(21)}, http.StatusConflict, nil)
+	var pending []views.MatchResultResponse
+	api.call("GET", "/api/v1/match-results/pending", bob.Token, nil, http.StatusOK, &pending)
+	if len(pending) != 1 || pending[0].ID != result.ID {
+		t.Fatalf("the opponent's pending results are %+v", pending)
+	}
+	api.call("GET", "/api/v1/match-results/pending", alice.Token, nil, http.StatusOK, &pending)
+	if len(pending) != 0 {
+		t.Fatalf("the reporter's pending results are %+v", pending)
+	}
+	api.call("POST", "/api/v1/match-results/"+id(result.ID)+"/dispute", bob.Token, views.DisputeResultRequest{Reason: "It was 19-21"}, http.StatusOK, nil)
+	api.call("GET", "/api/v1/match-results/disputed", admin.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/match-results/"+id(result.ID)+"/resolve", admin.Token, views.ResolveResultRequest{Accept: boolPtr(true)}, http.StatusOK, nil)
+
+	// A friendly doubles match shows each side's team and members
+	var rivals views.CreatedTeamResponse
+	api.call("POST", "/api/v1/teams", carol.Token, views.CreateTeamRequest{Name: "Net Rushers", PartnerID: dan.User.ID}, http.StatusCreated, &rivals)
+	var doubles views.MatchResponse
+	api.call("POST", "/api/v1/matches", bob.Token, map[string]interface{}{
+		"type": models.MatchDoubles, "team1_id": team.TeamID, "team2_id": rivals.TeamID,
+	}, http.StatusCreated, &doubles)
+	api.call("POST", "/api/v1/matches/"+id(doubles.ID)+"/result", bob.Token, views.ReportResultRequest{Side1Score: intPtr(21), Side2Score: intPtr(12)}, http.StatusCreated, &result)
+	api.call("POST", "/api/v1/match-results/"+id(result.ID)+"/confirm", dan.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/matches/"+id(doubles.ID), carol.Token, nil, http.StatusOK, &doubles)
+	if doubles.Side1.Team.ID == nil || *doubles.Side1.Team.ID != team.TeamID || len(doubles.Side1.Team.Members) != 2 ||
+		len(doubles.Side2.Team.Members) != 2 || doubles.Side1.Score != 21 || doubles.WinnerSide == nil || *doubles.WinnerSide != 1 {
+		t.Fatalf("the confirmed doubles match is %+v", doubles)
+	}
+	api.call("GET", "/api/v1/matches?type=doubles&player_id="+id(dan.User.ID), dan.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/standings?type=doubles", alice.Token, nil, http.StatusOK, nil)
+
+	var cancelled views.MatchResponse
+	api.call("POST", "/api/v1/matches", carol.Token, map[string]interface{}{
+		"type": models.MatchSingles, "player1_id": carol.User.ID, "player2_id": dan.User.ID,
+	}, http.StatusCreated, &cancelled)
+	api.call("DELETE", "/api/v1/matches/"+id(cancelled.ID), carol.Token, nil, http.StatusOK, nil)
+
+	// Notifications, audit and search
+	var notifications []views.NotificationResponse
+	api.call("GET", "/api/v1/notifications", bob.Token, nil, http.StatusOK, &notifications)
+	if len(notifications) == 0 {
+		t.Fatal("bob has no notifications")
+	}
+	api.call("POST", "/api/v1/notifications/"+id(notifications[0].ID)+"/read", bob.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/notifications/read-all", bob.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/audit?resource=tournament", admin.Token, nil, http.StatusOK, nil)
+	var deskCheckIns []views.AuditLogResponse
+	api.call("GET", "/api/v1/audit?resource=registration&action=registration.checked_in&actor_id="+id(carol.User.ID), admin.Token, nil, http.StatusOK, &deskCheckIns)
+	if len(deskCheckIns) != 1 || deskCheckIns[0].Details != "tournament "+id(spring.ID) {
+		t.Fatalf("desk check-ins audited as %+v", deskCheckIns)
+	}
+	api.call("GET", "/api/v1/search", alice.Token, nil, http.StatusBadRequest, nil)
+	if db.Migrator().HasTable("search_index") {
+		var found []views.SearchResultResponse
+		api.call("GET", "/api/v1/search?q=nguyen", alice.Token, nil, http.StatusOK, &found)
+		if len(found) == 0 {
+			t.Fatal("searching without accents found no players")
+		}
+	} else {
+		// SQLite built without FTS5 has no index; run the tests with
+		// -tags sqlite_fts5 to check search
+		api.call("GET", "/api/v1/search?q=nguyen", alice.Token, nil, http.StatusServiceUnavailable, nil)
+		uncheckedRoutes["GET /api/v1/search"] = "SQLite was built without FTS5"
+	}
+
+	// Sessions end
+	api.call("DELETE", teamPath, bob.Token, nil, http.StatusOK, nil)
+	api.call("POST", "/api/v1/2fa/disable", admin.Token, views.DisableTwoFactorRequest{Password: "password", Code: recovery.RecoveryCodes[1]}, http.StatusOK, nil)
+	api.call("POST", "/api/v1/logout", alice.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/profile", alice.Token, nil, http.StatusUnauthorized, nil)
+	api.call("POST", "/api/v1/logout-all", bob.Token, nil, http.StatusOK, nil)
+	api.call("GET", "/api/v1/profile", bobKey.Key, nil, http.StatusUnauthorized, nil)
+
+	var unchecked []string
+	for _, route := range api.router.Routes() {
+		key := route.Method + " " + route.Path
+		if reason, ok := uncheckedRoutes[key]; ok {
+			t.Logf("%s not checked: %s", key, reason)
+			continue
+		}
+		if !api.called[key] {
+			unchecked = append(unchecked, key)
+		}
+	}
+	sort.Strings(unchecked)
+	if len(unchecked) > 0 {
+		t.Errorf("%d routes without a checked success response: %s", len(unchecked), strings.Join(unchecked, ", "))
+	}
+}
+
+// Wrong passwords typed under a user's username and email count against
+// the same account, which locks at the policy's threshold
+func TestLoginLockoutAcrossNames(t *testing.T) {
+	api, db, _, _ := newAPIClient(t)
+	api.call("POST", "/api/v1/register", "", views.RegisterRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password",
+		FullName: "Alice Nguyễn",
+	}, http.StatusCreated, nil)
+
+	for i := 0; i < throttle.AccountPolicy.LockoutThreshold; i++ {
+		name := "alice"
+		if i%2 == 1 {
+			name = "alice@example.com"
+		}
+		api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: name, Password: "wrong"}, http.StatusUnauthorized, nil)
+
+		// Skip the backoff between attempts, leaving lockouts in place
+		if err := db.Model(&models.LoginThrottle{}).Where("locked_out = ?", false).Update("blocked_until", nil).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "alice", Password: "password"}, http.StatusTooManyRequests, nil)
+	api.call("POST", "/api/v1/login", "", views.LoginRequest{Username: "alice@example.com", Password: "password"}, http.StatusTooManyRequests, nil)
+}
+
+// Standings count only completed matches with a winner, for the side that
+// won
+func TestStandingsCountOnlyDecidedMatches(t *testing.T) {
+	api, db, _, _ := newAPIClient(t)
+	var auth views.AuthResponse
+	api.call("POST", "/api/v1/register", "", views.RegisterRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password",
+		FullName: "Alice Nguyễn",
+	}, http.StatusCreated, &auth)
+	bob := models.User{Username: "bob", Email: "bob@example.com", Password: "unused", FullName: "Bob Trần", Role: models.RolePlayer, IsActive: true}
+	if err := db.Create(&bob).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	alice := auth.User.ID
+	matches := []models.Match{
+		// Won by side 2
+		{Type: models.MatchSingles, Status: models.MatchCompleted, Player1ID: &alice, Player2ID: &bob.ID, Player1Score: 15, Player2Score: 21, WinnerPlayerID: &bob.ID},
+		// Completed without a winner
+		{Type: models.MatchSingles, Status: models.MatchCompleted, Player1ID: &alice, Player2ID: &bob.ID, Player1Score: 21, Player2Score: 19},
+	}
+	if err := db.Create(&matches).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var standings []views.StandingResponse
+	api.call("GET", "/api/v1/standings", auth.Token, nil, http.StatusOK, &standings)
+	want := []views.StandingResponse{
+		{ID: bob.ID, Name: "Bob Trần", Played: 1, Won: 1, PointsFor: 21, PointsAgainst: 15},
+		{ID: alice, Name: "Alice Nguyễn", Played: 1, Lost: 1, PointsFor: 15, PointsAgainst: 21},
+	}
+	if !reflect.DeepEqual(standings, want) {
+		t.Errorf("standings are %+v, want %+v", standings, want)
+	}
+}
+
+// A captain creating a team with the same partner several times at once
+// gets exactly one team; the other attempts are told the pair has one
+func TestCreateTeamConcurrentDuplicate(t *testing.T) {
+	api, db, mail, _ := newAPIClient(t)
+	captain, partner := registerPlayer(t, api, mail, "alice"), registerPlayer(t, api, mail, "bob")
+
+	paths := make([]string, 10)
+	for i := range paths {
+		paths[i] = "/api/v1/teams"
+	}
+	statuses := sendConcurrently(t, db, captain.Token, paths, views.CreateTeamRequest{Name: "Smash", PartnerID: partner.User.ID})
+	if created := countStatuses(t, statuses, http.StatusCreated, http.StatusConflict); created != 1 {
+		t.Errorf("%d teams were created, want 1", created)
+	}
+
+	var teams int64
+	if err := db.Model(&models.Team{}).Count(&teams).Error; err != nil {
+		t.Fatal(err)
+	}
+	if teams != 1 {
+		t.Errorf("got %d teams, want 1", teams)
+	}
+}
+
+// A captain adding the same partner to two of their teams at once gets
+// them into exactly one; the other attempts are told the pair exists
+func TestAddTeamMemberConcurrentDuplicate(t *testing.T) {
+	api, db, mail, _ := newAPIClient(t)
+	captain, partner := registerPlayer(t, api, mail, "alice"), registerPlayer(t, api, mail, "bob")
+
+	var teams []uint
+	for _, name := range []string{"Smash", "Drop"} {
+		team := models.Team{Name: name, Players: []models.TeamPlayer{{PlayerID: captain.User.ID, Role: models.TeamRoleCaptain}}}
+		if err := db.Create(&team).Error; err != nil {
+			t.Fatal(err)
+		}
+		teams = append(teams, team.ID)
+	}
+
+	paths := make([]string, 10)
+	for i := range paths {
+		paths[i] = "/api/v1/teams/" + id(teams[i%len(teams)]) + "/members"
+	}
+	statuses := sendConcurrently(t, db, captain.Token, paths, views.TeamPlayerRequest{PlayerID: partner.User.ID})
+	if added := countStatuses(t, statuses, http.StatusOK, http.StatusConflict); added != 1 {
+		t.Errorf("%d adds succeeded, want 1", added)
+	}
+
+	var memberships int64
+	if err := db.Model(&models.TeamPlayer{}).Where("player_id = ?", partner.User.ID).Count(&memberships).Error; err != nil {
+		t.Fatal(err)
+	}
+	if memberships != 1 {
+		t.Errorf("partner is on %d teams, want 1", memberships)
+	}
+}
+
+// registerPlayer signs up a player with a verified email
+func registerPlayer(t *testing.T, api *apiClient, mail *outbox, username string) views.AuthResponse {
+	t.Helper()
+	var auth views.AuthResponse
+	api.call("POST", "/api/v1/register", "", views.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: "password",
+		FullName: username,
+	}, http.StatusCreated, &auth)
+	api.call("POST", "/api/v1/verify-email", "", views.TokenRequest{Token: mail.token(t, username+"@example.com")}, http.StatusOK, nil)
+	return auth
+}
+
+// sendConcurrently posts the body to every path at once and returns the
+// response statuses. The client records the route of its one request in
+// flight, so the requests go through a router of their own.
+func sendConcurrently(t *testing.T, db *gorm.DB, token string, paths []string, body interface{}) []int {
+	t.Helper()
+	router := gin.New()
+	if _, err := Register(router, db, Dependencies{Mailer: &outbox{}, Tokens: usertokens.NewManager(db), AppURL: "http://app.test"}); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := make([]int, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", path, bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+			statuses[i] = res.Code
+		}(i, path)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// countStatuses counts the successes, failing the test on any status but
+// success and the expected rejection
+func countStatuses(t *testing.T, statuses []int, success, rejected int) int {
+	t.Helper()
+	succeeded := 0
+	for _, status := range statuses {
+		switch status {
+		case success:
+			succeeded++
+		case rejected:
+		default:
+			t.Errorf("unexpected status %d", status)
+		}
+	}
+	return succeeded
+}
+
+func id(n uint) string {
+	return strconv.FormatUint(uint64(n), 10)
+}
+
+func intPtr(n int) *int          { return &n }
+func boolPtr(b bool) *bool       { return &b }
+func stringPtr(s string) *string { return &s }
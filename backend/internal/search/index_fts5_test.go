@@ -0,0 +1,104 @@
+//go:build sqlite_fts5
+
+package search_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"badminton-backend/internal/config"
+	"badminton-backend/internal/database"
+	"badminton-backend/internal/migrations"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/search"
+)
+
+// Run with -tags sqlite_fts5: SQLite builds without FTS5 have no index
+func TestSQLiteIndexIgnoresAccents(t *testing.T) {
+	cfg := database.ConfigFrom(config.Database{
+		Driver: database.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "search.db"),
+	})
+	db, err := database.Open(cfg, &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := migrations.New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	verifiedAt := time.Now()
+	player := models.User{
+		Username:        "ducnguyen",
+		Email:           "duc@example.com",
+		Password:        "unused",
+		FullName:        "Nguyễn Văn Đức",
+		Role:            models.RolePlayer,
+		IsActive:        true,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := db.Create(&player).Error; err != nil {
+		t.Fatal(err)
+	}
+	team := models.Team{Name: "Cầu Lông Đà Nẵng", Description: "Đội đôi nam"}
+	if err := db.Create(&team).Error; err != nil {
+		t.Fatal(err)
+	}
+	tournament := models.Tournament{
+		Name:        "Giải Đà Nẵng Mở Rộng",
+		Description: "Giải đơn nam tại Huế",
+		Type:        models.TournamentSingles,
+		Status:      models.TournamentDraft,
+		AdminID:     player.ID,
+	}
+	if err := db.Create(&tournament).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	index := search.New(db)
+	tests := []struct {
+		query string
+		want  []string // kinds of the results, in any order
+	}{
+		{"nguyen duc", []string{search.KindPlayer}},
+		{"NGUYỄN", []string{search.KindPlayer}},
+		{"ngu", []string{search.KindPlayer}},
+		{"da nang", []string{search.KindTeam, search.KindTournament}},
+		{"Đà Nẵng", []string{search.KindTeam, search.KindTournament}},
+		{"cau long", []string{search.KindTeam}},
+		{"hue", []string{search.KindTournament}},
+		{"nguyen nang", nil},
+		{"hanoi", nil},
+	}
+
+	for _, tt := range tests {
+		results, err := index.Search(tt.query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+
+		got := make(map[string]int)
+		for _, result := range results {
+			got[result.Kind]++
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("Search(%q) returned %+v, want kinds %v", tt.query, results, tt.want)
+			continue
+		}
+		for _, kind := range tt.want {
+			if got[kind] != 1 {
+				t.Errorf("Search(%q) returned %+v, want kinds %v", tt.query, results, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// postgresIndex queries the search_vector columns of users, teams and
+// tournaments. They are generated from the names with Vietnamese letters
+// translated to plain ones, so no unaccent extension is needed.
+type postgresIndex struct {
+	db *gorm.DB
+}
+
+const postgresSearch = `
+SELECT 'player' AS kind, id, full_name AS title, username AS subtitle, ts_rank(search_vector, query) AS rank
+FROM users, to_tsquery('simple', ?) query
+WHERE search_vector @@ query AND deleted_at IS NULL AND role = 'player' AND is_active
+UNION ALL
+SELECT 'team', id, name, description, ts_rank(search_vector, query)
+FROM teams, to_tsquery('simple', ?) query
+WHERE search_vector @@ query AND deleted_at IS NULL
+UNION ALL
+SELECT 'tournament', id, name, description, ts_rank(search_vector, query)
+FROM tournaments, to_tsquery('simple', ?) query
+WHERE search_vector @@ query AND deleted_at IS NULL
+ORDER BY rank DESC, kind, id
+LIMIT ?`
+
+func (i *postgresIndex) Search(query string, limit int) ([]Result, error) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Every term must match as a word prefix
+	prefixes := make([]string, len(terms))
+	for n, term := range terms {
+		prefixes[n] = term + ":*"
+	}
+	tsquery := strings.Join(prefixes, " & ")
+
+	var results []Result
+	err := i.db.Raw(postgresSearch, tsquery, tsquery, tsquery, limit).Scan(&results).Error
+	return results, err
+}
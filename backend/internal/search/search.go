@@ -0,0 +1,74 @@
+// Package search finds players, teams and tournaments by name. Matching
+// ignores letter case and accents, so "nguyen duc" finds "Nguyễn Đức".
+// SQLite uses an FTS5 table and PostgreSQL tsvector columns, both kept up
+// to date by the database itself; see the search_index migration.
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// Result kinds
+const (
+	KindPlayer     = "player"
+	KindTeam       = "team"
+	KindTournament = "tournament"
+)
+
+// ErrUnavailable is returned when the database has no search index, as
+// with SQLite builds without FTS5
+var ErrUnavailable = errors.New("search index unavailable")
+
+// Result is one match, best first
+type Result struct {
+	Kind     string
+	ID       uint
+	Title    string  // player full name, team or tournament name
+	Subtitle string  // username or description
+	Rank     float64 // higher is a better match, comparable within one search
+}
+
+// Index searches the players, teams and tournaments in the database
+type Index interface {
+	// Search returns up to limit results matching every word of the query,
+	// each as a word prefix
+	Search(query string, limit int) ([]Result, error)
+}
+
+// New returns the index for the database's driver
+func New(db *gorm.DB) Index {
+	if db.Dialector.Name() == "postgres" {
+		return &postgresIndex{db: db}
+	}
+	return &sqliteIndex{db: db}
+}
+
+// Terms splits a query into lower case words without accents. Anything but
+// letters and digits separates words, so terms are safe to place in FTS5
+// and tsquery expressions.
+func Terms(query string) []string {
+	return strings.FieldsFunc(Fold(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Fold lower-cases text and strips its accents. Đ has no decomposition in
+// Unicode, so it is mapped to d by hand.
+func Fold(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ' || r == 'Đ':
+			r = 'd'
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Nguyễn", "nguyen"},
+		{"Đà Nẵng", "da nang"},
+		{"ĐỖ ĐỨC", "do duc"},
+		{"Huế", "hue"},
+		{"Trần Thị Ánh", "tran thi anh"},
+		{"Café Open 2024", "cafe open 2024"},
+		{"plain ascii", "plain ascii"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Fold(tt.text); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Nguyễn Đức", []string{"nguyen", "duc"}},
+		{"  Đà   Nẵng  ", []string{"da", "nang"}},
+		{"Hà Nội Open 2024", []string{"ha", "noi", "open", "2024"}},
+		// FTS5 and tsquery operators only separate words
+		{`"smash" OR net* & !drop:* (doubles)`, []string{"smash", "or", "net", "drop", "doubles"}},
+		{"anh-tuấn_lê", []string{"anh", "tuan", "le"}},
+		{"", []string{}},
+		{"*&|!", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sqliteIndex queries the search_index FTS5 table. The table holds names
+// with đ already replaced; its unicode61 tokenizer removes the other
+// accents. Rows of deleted records are skipped by joining to their tables.
+type sqliteIndex struct {
+	db *gorm.DB
+}
+
+// bm25 weights for search_index's kind, ref_id, title and body columns.
+// Names count for more than usernames and descriptions.
+const sqliteRank = "-bm25(search_index, 0, 0, 10.0, 2.0)"
+
+const sqliteSearch = `
+SELECT 'player' AS kind, users.id, users.full_name AS title, users.username AS subtitle, ` + sqliteRank + ` AS rank
+FROM search_index JOIN users ON users.id = search_index.ref_id
+WHERE search_index MATCH ? AND search_index.kind = 'player'
+	AND users.deleted_at IS NULL AND users.role = 'player' AND users.is_active
+UNION ALL
+SELECT 'team', teams.id, teams.name, teams.description, ` + sqliteRank + `
+FROM search_index JOIN teams ON teams.id = search_index.ref_id
+WHERE search_index MATCH ? AND search_index.kind = 'team' AND teams.deleted_at IS NULL
+UNION ALL
+SELECT 'tournament', tournaments.id, tournaments.name, tournaments.description, ` + sqliteRank + `
+FROM search_index JOIN tournaments ON tournaments.id = search_index.ref_id
+WHERE search_index MATCH ? AND search_index.kind = 'tournament' AND tournaments.deleted_at IS NULL
+ORDER BY rank DESC, kind, id
+LIMIT ?`
+
+func (i *sqliteIndex) Search(query string, limit int) ([]Result, error) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	if !i.db.Migrator().HasTable("search_index") {
+		return nil, ErrUnavailable
+	}
+
+	// Every term must match as a word prefix
+	phrases := make([]string, len(terms))
+	for n, term := range terms {
+		phrases[n] = `"` + term + `"*`
+	}
+	match := strings.Join(phrases, " ")
+
+	var results []Result
+	err := i.db.Raw(sqliteSearch, match, match, match, limit).Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"time"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// CheckInService records entrants arriving at a tournament and closes
+// check-in before the draw
+type CheckInService interface {
+	// Tournament returns the tournament with its matches
+	Tournament(id uint) (*models.Tournament, error)
+	// ListPlayers and ListTeams return all of the tournament's entrants
+	// in registration order
+	ListPlayers(tournamentID uint) ([]models.TournamentPlayer, error)
+	ListTeams(tournamentID uint) ([]models.TournamentTeam, error)
+
+	// FindPlayer, FindTeam and FindTeamByMember return a registration
+	// that can still check in
+	FindPlayer(tournamentID, playerID uint) (*models.TournamentPlayer, error)
+	FindTeam(tournamentID, teamID uint) (*models.TournamentTeam, error)
+	FindTeamByMember(tournamentID, playerID uint) (*models.TournamentTeam, error)
+
+	// CheckInPlayer and CheckInTeam record the entrant's arrival. Waitlisted
+	// entrants keep their status until check-in closes and they are promoted.
+	CheckInPlayer(registration *models.TournamentPlayer) error
+	CheckInTeam(registration *models.TournamentTeam) error
+	// Close marks entrants who have not arrived as no-shows and, if asked,
+	// fills the freed places from waitlisted entrants who have
+	Close(tournament *models.Tournament, promoteWaitlist bool) (*CheckInClosure, error)
+}
+
+// CheckInClosure is what closing check-in changed
+type CheckInClosure struct {
+	NoShows  int64
+	Promoted int64
+}
+
+// checkInEligibleStatuses are the registration statuses that can still check in
+var checkInEligibleStatuses = []models.RegistrationStatus{
+	models.RegistrationRegistered,
+	models.RegistrationConfirmed,
+	models.RegistrationWaitlisted,
+	models.RegistrationCheckedIn,
+}
+
+type checkInService struct {
+	store repositories.Store
+}
+
+func NewCheckInService(store repositories.Store) CheckInService {
+	return &checkInService{store: store}
+}
+
+func (s *checkInService) Tournament(id uint) (*models.Tournament, error) {
+	tournament, err := s.store.Tournaments().Get(id)
+	if err != nil {
+		return nil, orNotFound(err, notFound("not_found", "Tournament not found"))
+	}
+	return tournament, nil
+}
+
+func (s *checkInService) ListPlayers(tournamentID uint) ([]models.TournamentPlayer, error) {
+	return s.store.Registrations().ListPlayers(tournamentID)
+}
+
+func (s *checkInService) ListTeams(tournamentID uint) ([]models.TournamentTeam, error) {
+	return s.store.Registrations().ListTeams(tournamentID)
+}
+
+func (s *checkInService) FindPlayer(tournamentID, playerID uint) (*models.TournamentPlayer, error) {
+	registration, err := s.store.Registrations().FindPlayer(tournamentID, playerID)
+	if err != nil {
+		return nil, orNotFound(err, registrationNotFound())
+	}
+	if !isCheckInEligible(registration.Status) {
+		return nil, registrationNotFound()
+	}
+	return registration, nil
+}
+
+func (s *checkInService) FindTeam(tournamentID, teamID uint) (*models.TournamentTeam, error) {
+	registration, err := s.store.Registrations().FindTeam(tournamentID, teamID)
+	if err != nil {
+		return nil, orNotFound(err, registrationNotFound())
+	}
+	if !isCheckInEligible(registration.Status) {
+		return nil, registrationNotFound()
+	}
+	return registration, nil
+}
+
+func (s *checkInService) FindTeamByMember(tournamentID, playerID uint) (*models.TournamentTeam, error) {
+	registration, err := s.store.Registrations().FindTeamByMember(tournamentID, playerID, checkInEligibleStatuses)
+	if err != nil {
+		return nil, orNotFound(err, registrationNotFound())
+	}
+	return registration, nil
+}
+
+// CheckInPlayer runs in a transaction holding the tournament row, so it
+// cannot interleave with Close, and only updates the registration if it is
+// unchanged since it was loaded
+func (s *checkInService) CheckInPlayer(registration *models.TournamentPlayer) error {
+	if registration.CheckedInAt != nil {
+		return alreadyCheckedIn()
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if err := checkInOpen(tx, registration.TournamentID); err != nil {
+			return err
+		}
+		status := arrivedStatus(registration.Status)
+		err := tx.Registrations().CheckInPlayer(registration, status, time.Now())
+		return orStale(err, registrationChanged())
+	})
+}
+
+// CheckInTeam runs like CheckInPlayer
+func (s *checkInService) CheckInTeam(registration *models.TournamentTeam) error {
+	if registration.CheckedInAt != nil {
+		return alreadyCheckedIn()
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if err := checkInOpen(tx, registration.TournamentID); err != nil {
+			return err
+		}
+		status := arrivedStatus(registration.Status)
+		err := tx.Registrations().CheckInTeam(registration, status, time.Now())
+		return orStale(err, registrationChanged())
+	})
+}
+
+// Close runs in a transaction holding the tournament row, so check-ins
+// either land before the no-shows are marked or are rejected after
+func (s *checkInService) Close(tournament *models.Tournament, promoteWaitlist bool) (*CheckInClosure, error) {
+	var closure CheckInClosure
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		locked, err := tx.Tournaments().Lock(tournament.ID)
+		if err != nil {
+			return orNotFound(err, notFound("not_found", "Tournament not found"))
+		}
+		if !locked.IsCheckInAllowed() {
+			return checkInClosed()
+		}
+		if locked.Status != models.TournamentRegistrationClosed {
+			return invalid("registration_open", "Registration must be closed before check-in is closed")
+		}
+
+		closure.NoShows, err = tx.Registrations().MarkNoShows(locked.ID, []models.RegistrationStatus{models.RegistrationRegistered, models.RegistrationConfirmed})
+		if err != nil {
+			return err
+		}
+
+		if promoteWaitlist {
+			if closure.Promoted, err = fillFromWaitlist(tx, locked); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		locked.CheckInClosedAt = &now
+		if err := tx.Tournaments().Save(locked); err != nil {
+			return err
+		}
+		tournament.CheckInClosedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+// fillFromWaitlist checks in waitlisted entrants who have arrived, up to the
+// places left free by no-shows
+func fillFromWaitlist(tx repositories.Store, tournament *models.Tournament) (int64, error) {
+	count := tx.Registrations().CountPlayers
+	promote := tx.Registrations().PromotePlayers
+	if tournament.IsTeamTournament() {
+		count = tx.Registrations().CountTeams
+		promote = tx.Registrations().PromoteTeams
+	}
+
+	checkedIn, err := count(tournament.ID, []models.RegistrationStatus{models.RegistrationCheckedIn})
+	if err != nil {
+		return 0, err
+	}
+	freePlaces := tournament.GetMaxParticipants() - int(checkedIn)
+	if freePlaces <= 0 {
+		return 0, nil
+	}
+	return promote(tournament.ID, freePlaces)
+}
+
+// checkInOpen locks the tournament and checks entrants can still check in
+func checkInOpen(tx repositories.Store, tournamentID uint) error {
+	tournament, err := tx.Tournaments().Lock(tournamentID)
+	if err != nil {
+		return orNotFound(err, notFound("not_found", "Tournament not found"))
+	}
+	if !tournament.IsCheckInAllowed() {
+		return checkInClosed()
+	}
+	return nil
+}
+
+// arrivedStatus is the status of an entrant once they have checked in
+func arrivedStatus(status models.RegistrationStatus) models.RegistrationStatus {
+	if status == models.RegistrationWaitlisted {
+		return status
+	}
+	return models.RegistrationCheckedIn
+}
+
+func isCheckInEligible(status models.RegistrationStatus) bool {
+	for _, eligible := range checkInEligibleStatuses {
+		if status == eligible {
+			return true
+		}
+	}
+	return false
+}
+
+func checkInClosed() *Error {
+	return invalid("check_in_closed", "Check-in is closed for this tournament")
+}
+
+func alreadyCheckedIn() *Error {
+	return conflict("already_checked_in", "Already checked in")
+}
+
+func registrationNotFound() *Error {
+	return notFound("registration_not_found", "No active registration found for this tournament")
+}
+
+// registrationChanged is the conflict for a registration another request
+// changed between loading and checking it in
+func registrationChanged() *Error {
+	return conflict("registration_changed", "The registration changed, reload and try again")
+}
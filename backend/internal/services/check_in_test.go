@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// registerForCheckIn registers the players and closes registration so
+// check-in can be closed
+func registerForCheckIn(t *testing.T, store repositories.Store, tournament *models.Tournament, players []*models.User) []*models.TournamentPlayer {
+	t.Helper()
+
+	registrations := make([]*models.TournamentPlayer, len(players))
+	for i, player := range players {
+		registration, err := NewRegistrationService(store).RegisterPlayer(tournament.ID, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registrations[i] = registration
+	}
+	if err := store.Tournaments().UpdateStatus(tournament, models.TournamentRegistrationClosed); err != nil {
+		t.Fatal(err)
+	}
+	return registrations
+}
+
+// Checking in from a copy loaded before the entrant was marked a no-show
+// must fail instead of overwriting the no-show
+func TestCheckInRejectsStaleRegistration(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 1)
+	tournament := createOpenTournament(t, store, players[0].ID, 8)
+	service := NewCheckInService(store)
+
+	stale := registerForCheckIn(t, store, tournament, players)[0]
+	if err := db.Model(&models.TournamentPlayer{}).Where("id = ?", stale.ID).Update("status", models.RegistrationNoShow).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var serviceErr *Error
+	err := service.CheckInPlayer(stale)
+	if !errors.As(err, &serviceErr) || serviceErr.Code != "registration_changed" {
+		t.Fatalf("checking in from a stale copy returned %v, want registration_changed", err)
+	}
+
+	reloaded, err := store.Registrations().FindPlayer(tournament.ID, players[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Status != models.RegistrationNoShow || reloaded.CheckedInAt != nil {
+		t.Errorf("registration is %s checked in at %v, want an unchanged no-show", reloaded.Status, reloaded.CheckedInAt)
+	}
+}
+
+// Check-ins racing the close either land before the no-shows are marked or
+// are rejected, so no entrant ends up both a no-show and checked in
+func TestCheckInConcurrentClose(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 10)
+	tournament := createOpenTournament(t, store, players[0].ID, 16)
+	service := NewCheckInService(store)
+	registrations := registerForCheckIn(t, store, tournament, players)
+
+	var wg sync.WaitGroup
+	for _, registration := range registrations {
+		wg.Add(1)
+		go func(registration *models.TournamentPlayer) {
+			defer wg.Done()
+			var serviceErr *Error
+			if err := service.CheckInPlayer(registration); err != nil && !errors.As(err, &serviceErr) {
+				t.Errorf("check-in failed: %v", err)
+			}
+		}(registration)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := service.Close(tournament, false); err != nil {
+			t.Errorf("closing check-in failed: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	for _, player := range players {
+		registration, err := store.Registrations().FindPlayer(tournament.ID, player.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case registration.Status == models.RegistrationCheckedIn && registration.CheckedInAt != nil:
+		case registration.Status == models.RegistrationNoShow && registration.CheckedInAt == nil:
+		default:
+			t.Errorf("player %d is %s checked in at %v", player.ID, registration.Status, registration.CheckedInAt)
+		}
+	}
+}
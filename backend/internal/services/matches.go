@@ -0,0 +1,136 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// MatchListing is what the match list accepts. A player filter matches
+// singles the player played and doubles their teams played.
+var MatchListing = listing.Spec{
+	Filters: map[string]listing.Filter{
+		"tournament_id": listing.ID("tournament_id"),
+		"status": listing.OneOf("status",
+			string(models.MatchPending), string(models.MatchOngoing), string(models.MatchCompleted), string(models.MatchCancelled)),
+		"type":  listing.OneOf("type", string(models.MatchSingles), string(models.MatchDoubles)),
+		"round": listing.Equals("round"),
+		"player_id": listing.IDFunc(func(id uint) listing.Scope {
+			return func(db *gorm.DB) *gorm.DB {
+				teams := db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamPlayer{}).Select("team_id").Where("player_id = ?", id)
+				return db.Where("player1_id = ? OR player2_id = ? OR team1_id IN (?) OR team2_id IN (?)", id, id, teams, teams)
+			}
+		}),
+		"from": listing.Since("match_date"),
+		"to":   listing.Until("match_date"),
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"match_date": "match_date",
+		"status":     "status",
+		"created_at": "created_at",
+	},
+	DefaultSort: "-match_date",
+}
+
+// MatchService manages matches. Who may change what is decided by the
+// caller's policy checks before these are called.
+type MatchService interface {
+	// List returns a page of matches with their players, teams and tournament
+	List(q *listing.Query) ([]models.Match, listing.Page, error)
+	// Get returns the match without its relations
+	Get(id uint) (*models.Match, error)
+	// GetWithRelations returns the match with its players, teams and tournament
+	GetWithRelations(id uint) (*models.Match, error)
+	// Create saves a new match, played now unless a date is set, and loads
+	// its relations
+	Create(match *models.Match) error
+	// Update saves the match and reloads its relations
+	Update(match *models.Match) error
+	Delete(match *models.Match) error
+}
+
+type matchService struct {
+	store repositories.Store
+}
+
+func NewMatchService(store repositories.Store) MatchService {
+	return &matchService{store: store}
+}
+
+func (s *matchService) List(q *listing.Query) ([]models.Match, listing.Page, error) {
+	return s.store.Matches().List(q)
+}
+
+func (s *matchService) Get(id uint) (*models.Match, error) {
+	match, err := s.store.Matches().Get(id)
+	if err != nil {
+		return nil, orNotFound(err, notFound("not_found", "Match not found"))
+	}
+	return match, nil
+}
+
+func (s *matchService) GetWithRelations(id uint) (*models.Match, error) {
+	match := &models.Match{}
+	match.ID = id
+	if err := s.store.Matches().LoadRelations(match); err != nil {
+		return nil, orNotFound(err, notFound("not_found", "Match not found"))
+	}
+	return match, nil
+}
+
+func (s *matchService) Create(match *models.Match) error {
+	match.ID = 0
+	if match.MatchDate.IsZero() {
+		match.MatchDate = time.Now()
+	}
+	if err := checkWinner(match); err != nil {
+		return err
+	}
+
+	if err := s.store.Matches().Create(match); err != nil {
+		return err
+	}
+	s.loadRelations(match)
+	return nil
+}
+
+func (s *matchService) Update(match *models.Match) error {
+	if err := checkWinner(match); err != nil {
+		return err
+	}
+	if err := s.store.Matches().Save(match); err != nil {
+		return err
+	}
+	s.loadRelations(match)
+	return nil
+}
+
+// checkWinner rejects a winner who is not playing in the match
+func checkWinner(match *models.Match) error {
+	if match.WinnerPlayerID != nil && !sameID(match.WinnerPlayerID, match.Player1ID) && !sameID(match.WinnerPlayerID, match.Player2ID) {
+		return invalid("invalid_winner", "The winner must be one of the match's players")
+	}
+	if match.WinnerTeamID != nil && !sameID(match.WinnerTeamID, match.Team1ID) && !sameID(match.WinnerTeamID, match.Team2ID) {
+		return invalid("invalid_winner", "The winning team must be one of the match's teams")
+	}
+	return nil
+}
+
+func sameID(a, b *uint) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// loadRelations fills in what the caller shows of a saved match. The match
+// is stored either way, so a failure only leaves relations empty.
+func (s *matchService) loadRelations(match *models.Match) {
+	s.store.Matches().LoadRelations(match)
+}
+
+func (s *matchService) Delete(match *models.Match) error {
+	return s.store.Matches().Delete(match)
+}
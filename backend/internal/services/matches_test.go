@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"badminton-backend/internal/models"
+)
+
+func TestCheckWinner(t *testing.T) {
+	one, two, other := uint(1), uint(2), uint(3)
+
+	tests := []struct {
+		name  string
+		match models.Match
+		valid bool
+	}{
+		{"no winner", models.Match{Player1ID: &one, Player2ID: &two}, true},
+		{"first player", models.Match{Player1ID: &one, Player2ID: &two, WinnerPlayerID: &one}, true},
+		{"second player", models.Match{Player1ID: &one, Player2ID: &two, WinnerPlayerID: &two}, true},
+		{"player not in the match", models.Match{Player1ID: &one, Player2ID: &two, WinnerPlayerID: &other}, false},
+		{"player without an opponent set", models.Match{Player1ID: &one, WinnerPlayerID: &two}, false},
+		{"first team", models.Match{Team1ID: &one, Team2ID: &two, WinnerTeamID: &one}, true},
+		{"team not in the match", models.Match{Team1ID: &one, Team2ID: &two, WinnerTeamID: &other}, false},
+		{"team winner of a singles match", models.Match{Player1ID: &one, Player2ID: &two, WinnerTeamID: &one}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWinner(&tt.match)
+			if tt.valid && err != nil {
+				t.Errorf("rejected a valid winner: %v", err)
+			}
+			if !tt.valid {
+				if serviceErr, ok := err.(*Error); !ok || serviceErr.Code != "invalid_winner" {
+					t.Errorf("got %v, want invalid_winner", err)
+				}
+			}
+		})
+	}
+}
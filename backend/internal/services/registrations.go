@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// RegistrationService enters players and teams into tournaments
+type RegistrationService interface {
+	// RegisterPlayer enters a verified player into a tournament open for
+	// registration, on the waitlist once it is full
+	RegisterPlayer(tournamentID uint, player *models.User) (*models.TournamentPlayer, error)
+	// RegisterTeam enters a team into a doubles tournament open for
+	// registration on behalf of one of its members, on the waitlist once
+	// it is full. Every member must be verified.
+	RegisterTeam(tournamentID, teamID uint, member *models.User) (*models.TournamentTeam, error)
+	// FindPlayerRegistration returns the player's registration for a tournament
+	FindPlayerRegistration(tournamentID, playerID uint) (*models.TournamentPlayer, error)
+	// Withdraw marks the registration withdrawn, keeping it for history
+	Withdraw(registration *models.TournamentPlayer) error
+	// ListForPlayer returns the player's registrations that have not been
+	// withdrawn, with their tournaments
+	ListForPlayer(playerID uint) ([]models.TournamentPlayer, error)
+}
+
+type registrationService struct {
+	store repositories.Store
+}
+
+func NewRegistrationService(store repositories.Store) RegistrationService {
+	return &registrationService{store: store}
+}
+
+// RegisterPlayer runs in a transaction holding the tournament row, so
+// concurrent registrations are counted one after another and cannot
+// overfill the tournament
+func (s *registrationService) RegisterPlayer(tournamentID uint, player *models.User) (*models.TournamentPlayer, error) {
+	if !player.IsPlayer() {
+		return nil, forbidden("player_required", "Only players can register for tournaments")
+	}
+	if !player.IsEmailVerified() {
+		return nil, forbidden("email_not_verified", "Please verify your email address before registering for tournaments")
+	}
+
+	alreadyRegistered := conflict("already_registered", "You are already registered for this tournament")
+
+	var registration models.TournamentPlayer
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		tournament, err := openTournament(tx, tournamentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Registrations().FindPlayer(tournamentID, player.ID)
+		if err == nil {
+			return alreadyRegistered
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+
+		// Players beyond capacity go on the waitlist
+		count, err := tx.Registrations().CountPlayers(tournamentID, models.ActiveRegistrationStatuses)
+		if err != nil {
+			return err
+		}
+
+		registration = models.TournamentPlayer{
+			TournamentID: tournamentID,
+			PlayerID:     player.ID,
+			Status:       capacityStatus(tournament, count),
+		}
+		return tx.Registrations().CreatePlayer(&registration)
+	})
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return nil, alreadyRegistered
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// RegisterTeam runs in a transaction holding the tournament row, like
+// RegisterPlayer
+func (s *registrationService) RegisterTeam(tournamentID, teamID uint, member *models.User) (*models.TournamentTeam, error) {
+	alreadyRegistered := conflict("team_already_registered", "Team is already registered for this tournament")
+
+	var registration models.TournamentTeam
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		tournament, err := tx.Tournaments().Lock(tournamentID)
+		if err != nil {
+			return orNotFound(err, notFound("tournament_not_found", "Tournament not found"))
+		}
+
+		if !tournament.IsTeamTournament() {
+			return invalid("singles_tournament", "This is a singles tournament, not doubles")
+		}
+		if tournament.Status != models.TournamentRegistrationOpen {
+			return invalid("registration_closed", "Registration is closed for this tournament")
+		}
+
+		isMember, err := tx.Registrations().IsTeamMember(teamID, member.ID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return forbidden("not_team_member", "You are not a member of this team")
+		}
+
+		unverified, err := tx.Registrations().CountUnverifiedMembers(teamID)
+		if err != nil {
+			return err
+		}
+		if unverified > 0 {
+			return forbidden("email_not_verified", "All team members must verify their email address before registering for tournaments")
+		}
+
+		_, err = tx.Registrations().FindTeam(tournamentID, teamID)
+		if err == nil {
+			return alreadyRegistered
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+
+		// Teams beyond capacity go on the waitlist
+		count, err := tx.Registrations().CountTeams(tournamentID, models.ActiveRegistrationStatuses)
+		if err != nil {
+			return err
+		}
+
+		registration = models.TournamentTeam{
+			TournamentID: tournamentID,
+			TeamID:       teamID,
+			Status:       capacityStatus(tournament, count),
+		}
+		return tx.Registrations().CreateTeam(&registration)
+	})
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return nil, alreadyRegistered
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+func (s *registrationService) FindPlayerRegistration(tournamentID, playerID uint) (*models.TournamentPlayer, error) {
+	registration, err := s.store.Registrations().FindPlayer(tournamentID, playerID)
+	if err != nil {
+		return nil, orNotFound(err, notFound("registration_not_found", "You are not registered for this tournament"))
+	}
+	return registration, nil
+}
+
+func (s *registrationService) Withdraw(registration *models.TournamentPlayer) error {
+	registration.Status = models.RegistrationWithdrawn
+	return s.store.Registrations().SavePlayer(registration)
+}
+
+func (s *registrationService) ListForPlayer(playerID uint) ([]models.TournamentPlayer, error) {
+	return s.store.Registrations().ListByPlayer(playerID)
+}
+
+// openTournament locks the tournament and returns it if it is accepting
+// registrations
+func openTournament(tx repositories.Store, tournamentID uint) (*models.Tournament, error) {
+	tournament, err := tx.Tournaments().Lock(tournamentID)
+	if err != nil {
+		return nil, orNotFound(err, notFound("tournament_not_found", "Tournament not found"))
+	}
+	if tournament.Status != models.TournamentRegistrationOpen {
+		return nil, invalid("registration_closed", "Registration is closed for this tournament")
+	}
+	return tournament, nil
+}
+
+// capacityStatus is the status of a new entry given how many active
+// entries the tournament already has
+func capacityStatus(tournament *models.Tournament, activeCount int64) models.RegistrationStatus {
+	if int(activeCount) >= tournament.GetMaxParticipants() {
+		return models.RegistrationWaitlisted
+	}
+	return models.RegistrationRegistered
+}
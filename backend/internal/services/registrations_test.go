@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"badminton-backend/internal/config"
+	"badminton-backend/internal/database"
+	"badminton-backend/internal/migrations"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// openTestStore opens a migrated SQLite database in a temporary directory
+// with the same connection settings as the server
+func openTestStore(t *testing.T) (*gorm.DB, repositories.Store) {
+	t.Helper()
+
+	cfg := database.ConfigFrom(config.Database{
+		Driver: database.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	db, err := database.Open(cfg, &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := migrations.New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+	return db, repositories.NewStore(db)
+}
+
+func createVerifiedPlayers(t *testing.T, db *gorm.DB, n int) []*models.User {
+	t.Helper()
+
+	verifiedAt := time.Now()
+	players := make([]*models.User, n)
+	for i := range players {
+		players[i] = &models.User{
+			Username:        fmt.Sprintf("player%d", i),
+			Email:           fmt.Sprintf("player%d@example.com", i),
+			Password:        "unused",
+			FullName:        fmt.Sprintf("Player %d", i),
+			Role:            models.RolePlayer,
+			IsActive:        true,
+			EmailVerifiedAt: &verifiedAt,
+		}
+		if err := db.Create(players[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return players
+}
+
+func createOpenTournament(t *testing.T, store repositories.Store, adminID uint, maxPlayers int) *models.Tournament {
+	t.Helper()
+
+	tournament := &models.Tournament{
+		Name:       "Concurrency Open",
+		Type:       models.TournamentSingles,
+		Status:     models.TournamentRegistrationOpen,
+		MaxPlayers: maxPlayers,
+		AdminID:    adminID,
+	}
+	if err := store.Tournaments().Create(tournament); err != nil {
+		t.Fatal(err)
+	}
+	return tournament
+}
+
+// Registrations arriving together must not overfill the tournament: the
+// first maxPlayers get places and everyone else is waitlisted
+func TestRegisterPlayerConcurrentCapacity(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 20)
+	tournament := createOpenTournament(t, store, players[0].ID, 5)
+	service := NewRegistrationService(store)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(players))
+	for _, player := range players {
+		wg.Add(1)
+		go func(player *models.User) {
+			defer wg.Done()
+			if _, err := service.RegisterPlayer(tournament.ID, player); err != nil {
+				errs <- err
+			}
+		}(player)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("registration failed: %v", err)
+	}
+
+	registered, err := store.Registrations().CountPlayers(tournament.ID, []models.RegistrationStatus{models.RegistrationRegistered})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitlisted, err := store.Registrations().CountPlayers(tournament.ID, []models.RegistrationStatus{models.RegistrationWaitlisted})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if registered != 5 || waitlisted != 15 {
+		t.Errorf("got %d registered and %d waitlisted, want 5 and 15", registered, waitlisted)
+	}
+}
+
+// The same player registering several times at once gets exactly one
+// registration; the other attempts are told they are already registered
+func TestRegisterPlayerConcurrentDuplicate(t *testing.T) {
+	db, store := openTestStore(t)
+	player := createVerifiedPlayers(t, db, 1)[0]
+	tournament := createOpenTournament(t, store, player.ID, 16)
+	service := NewRegistrationService(store)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.RegisterPlayer(tournament.ID, player)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		var ruleErr *Error
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &ruleErr) && ruleErr.Code == "already_registered":
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d registrations succeeded, want 1", succeeded)
+	}
+
+	var rows int64
+	if err := db.Model(&models.TournamentPlayer{}).Where("tournament_id = ?", tournament.ID).Count(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 {
+		t.Errorf("got %d registration rows, want 1", rows)
+	}
+}
+
+// Both members of a team registering it at once get exactly one
+// registration; the other attempts are told it is already registered
+func TestRegisterTeamConcurrentDuplicate(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 2)
+	tournament := createOpenTournament(t, store, players[0].ID, 16)
+	tournament.Type = models.TournamentDoubles
+	if err := store.Tournaments().Save(tournament); err != nil {
+		t.Fatal(err)
+	}
+	team := models.Team{Name: "Concurrency Pair"}
+	if err := db.Create(&team).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, player := range players {
+		if err := db.Create(&models.TeamPlayer{TeamID: team.ID, PlayerID: player.ID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewRegistrationService(store)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(member *models.User) {
+			defer wg.Done()
+			_, err := service.RegisterTeam(tournament.ID, team.ID, member)
+			results <- err
+		}(players[i%len(players)])
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		var ruleErr *Error
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &ruleErr) && ruleErr.Code == "team_already_registered":
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d registrations succeeded, want 1", succeeded)
+	}
+
+	var rows int64
+	if err := db.Model(&models.TournamentTeam{}).Where("tournament_id = ?", tournament.ID).Count(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 {
+		t.Errorf("got %d registration rows, want 1", rows)
+	}
+}
+
+// The unique index backs up the service's own check
+func TestCreatePlayerRegistrationDuplicate(t *testing.T) {
+	db, store := openTestStore(t)
+	player := createVerifiedPlayers(t, db, 1)[0]
+	tournament := createOpenTournament(t, store, player.ID, 16)
+
+	first := models.TournamentPlayer{TournamentID: tournament.ID, PlayerID: player.ID, Status: models.RegistrationRegistered}
+	if err := store.Registrations().CreatePlayer(&first); err != nil {
+		t.Fatal(err)
+	}
+
+	second := models.TournamentPlayer{TournamentID: tournament.ID, PlayerID: player.ID, Status: models.RegistrationRegistered}
+	if err := store.Registrations().CreatePlayer(&second); !errors.Is(err, repositories.ErrDuplicate) {
+		t.Errorf("got %v, want ErrDuplicate", err)
+	}
+}
@@ -0,0 +1,70 @@
+// Package services holds the business rules for tournaments, matches and
+// registrations, independent of HTTP so the API, command line tools and
+// background jobs share them.
+package services
+
+import (
+	"errors"
+
+	"badminton-backend/internal/repositories"
+)
+
+// Kind classifies a rule violation so callers can map it to a response
+type Kind int
+
+const (
+	KindInvalid   Kind = iota + 1 // the request itself is wrong
+	KindForbidden                 // the user may not do this
+	KindNotFound                  // something the request names does not exist
+	KindConflict                  // the current state does not allow it
+)
+
+// Error is a rule violation with a stable code for API clients. Any other
+// error returned by a service is an internal failure.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func invalid(code, message string) *Error {
+	return &Error{Kind: KindInvalid, Code: code, Message: message}
+}
+
+func forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func notFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// orNotFound replaces a missing record error with the given rule violation
+func orNotFound(err error, missing *Error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return missing
+	}
+	return err
+}
+
+// orStale replaces a stale record error with the given rule violation
+func orStale(err error, changed *Error) error {
+	if errors.Is(err, repositories.ErrStale) {
+		return changed
+	}
+	return err
+}
+
+// statusChanged is the conflict for a tournament another request moved on
+// between loading and updating it
+func statusChanged() *Error {
+	return conflict("status_changed", "The tournament status changed, reload and try again")
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// cancellableRegistrationStatuses are the registrations closed off, and
+// told, when a tournament is cancelled. Withdrawn entrants have already left.
+var cancellableRegistrationStatuses = []models.RegistrationStatus{
+	models.RegistrationRegistered,
+	models.RegistrationConfirmed,
+	models.RegistrationWaitlisted,
+	models.RegistrationCheckedIn,
+	models.RegistrationNoShow,
+}
+
+// refundableRegistrationStatuses are the registrations whose entry fee is
+// refunded on cancellation: those holding a place. The waitlist never got
+// one and no-shows forfeited theirs.
+var refundableRegistrationStatuses = []models.RegistrationStatus{
+	models.RegistrationRegistered,
+	models.RegistrationConfirmed,
+	models.RegistrationCheckedIn,
+}
+
+// Cancel cancels a tournament that has not finished while keeping its
+// history: unfinished matches and open registrations are marked cancelled,
+// entry fees are queued for refund and entrants are notified
+func (s *tournamentService) Cancel(tournament *models.Tournament, reason string) (*Cancellation, error) {
+	if !tournament.CanTransitionTo(models.TournamentCancelled) {
+		return nil, conflict("invalid_transition", "Cannot cancel a tournament in status "+string(tournament.Status))
+	}
+
+	var cancellation Cancellation
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		cancellation.CancelledMatches, err = tx.Matches().CancelUnfinished(tournament.ID)
+		if err != nil {
+			return err
+		}
+
+		cancellation.Refunds, err = entryFeeRefunds(tx, tournament, reason)
+		if err != nil {
+			return err
+		}
+		if err := tx.Refunds().CreateAll(cancellation.Refunds); err != nil {
+			return err
+		}
+
+		cancellation.CancelledRegistrations, err = tx.Registrations().CancelAll(tournament.ID, cancellableRegistrationStatuses)
+		if err != nil {
+			return err
+		}
+
+		return tx.Tournaments().UpdateStatus(tournament, models.TournamentCancelled)
+	})
+	if err != nil {
+		return nil, orStale(err, statusChanged())
+	}
+
+	message := "The organisers have cancelled this tournament."
+	if reason != "" {
+		message = "The organisers have cancelled this tournament: " + reason
+	}
+	if tournament.EntryFee > 0 {
+		message += fmt.Sprintf(" Entry fees of %.2f will be refunded to entrants who held a place.", tournament.EntryFee)
+	}
+
+	s.notifyEntrants(tournament, models.Notification{
+		Type:    "tournament_cancelled",
+		Title:   tournament.Name + " has been cancelled",
+		Message: message,
+	})
+
+	return &cancellation, nil
+}
+
+// entryFeeRefunds builds a refund for every registration of a tournament
+// with an entry fee that held a place. Team fees go to the team captain.
+func entryFeeRefunds(tx repositories.Store, tournament *models.Tournament, reason string) ([]models.Refund, error) {
+	if tournament.EntryFee <= 0 {
+		return nil, nil
+	}
+
+	if reason == "" {
+		reason = "Tournament cancelled"
+	}
+
+	var refunds []models.Refund
+	if tournament.IsTeamTournament() {
+		registrations, err := tx.Registrations().TeamsWithStatus(tournament.ID, refundableRegistrationStatuses)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range registrations {
+			captainID := r.Team.CaptainID()
+			if captainID == 0 {
+				continue
+			}
+			teamID := r.TeamID
+			refunds = append(refunds, models.Refund{
+				TournamentID: tournament.ID,
+				UserID:       captainID,
+				TeamID:       &teamID,
+				Amount:       tournament.EntryFee,
+				Reason:       reason,
+				Status:       models.RefundPending,
+			})
+		}
+		return refunds, nil
+	}
+
+	registrations, err := tx.Registrations().PlayersWithStatus(tournament.ID, refundableRegistrationStatuses)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range registrations {
+		refunds = append(refunds, models.Refund{
+			TournamentID: tournament.ID,
+			UserID:       r.PlayerID,
+			Amount:       tournament.EntryFee,
+			Reason:       reason,
+			Status:       models.RefundPending,
+		})
+	}
+	return refunds, nil
+}
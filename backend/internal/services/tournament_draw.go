@@ -0,0 +1,181 @@
+package services
+
+import (
+	"sort"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/repositories"
+)
+
+// Draw is a generated first round
+type Draw struct {
+	Matches []models.Match
+	Byes    []DrawEntrant // entrants going straight to the second round
+}
+
+// Walkovers records the byes as first-round matches won by the entrant,
+// so the draw can be rebuilt from the stored matches
+func (d *Draw) Walkovers(tournament *models.Tournament) []models.Match {
+	walkovers := make([]models.Match, 0, len(d.Byes))
+	for _, entrant := range d.Byes {
+		match := models.Match{
+			TournamentID: &tournament.ID,
+			Status:       models.MatchCompleted,
+			MatchDate:    tournament.StartDate,
+			Round:        "round1",
+		}
+		if tournament.IsTeamTournament() {
+			match.Type = models.MatchDoubles
+			match.Team1ID, match.WinnerTeamID = entrant.TeamID, entrant.TeamID
+		} else {
+			match.Type = models.MatchSingles
+			match.Player1ID, match.WinnerPlayerID = entrant.PlayerID, entrant.PlayerID
+		}
+		walkovers = append(walkovers, match.Clone())
+	}
+	return walkovers
+}
+
+// DrawEntrant is a checked-in player or team taking a place in the draw
+type DrawEntrant struct {
+	PlayerID *uint
+	TeamID   *uint
+	Ranking  int
+}
+
+// GenerateDraw creates the first-round matches of a single-elimination
+// draw from the checked-in entrants and moves the tournament to drawn.
+// Registration and check-in must both be closed.
+func (s *tournamentService) GenerateDraw(tournament *models.Tournament) (*Draw, error) {
+	if !tournament.CanTransitionTo(models.TournamentDrawn) {
+		return nil, conflict("invalid_transition", "Cannot generate a draw for a tournament in status "+string(tournament.Status))
+	}
+
+	if !tournament.IsCheckInClosed() {
+		return nil, invalid("check_in_open", "Check-in must be closed before the draw is generated")
+	}
+
+	matchCount, err := s.store.Matches().CountByTournament(tournament.ID)
+	if err != nil {
+		return nil, err
+	}
+	if matchCount > 0 {
+		return nil, conflict("draw_exists", "The draw has already been generated")
+	}
+
+	entrants, err := s.checkedInEntrants(tournament)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entrants) < 2 {
+		return nil, invalid("not_enough_entrants", "At least two checked-in entrants are required for a draw")
+	}
+
+	byes, pairs := seedFirstRound(len(entrants))
+
+	draw := Draw{Matches: make([]models.Match, 0, len(pairs))}
+	for _, pair := range pairs {
+		match := models.Match{
+			TournamentID: &tournament.ID,
+			Status:       models.MatchPending,
+			MatchDate:    tournament.StartDate,
+			Round:        "round1",
+		}
+		first, second := entrants[pair[0]], entrants[pair[1]]
+		if tournament.IsTeamTournament() {
+			match.Type = models.MatchDoubles
+			match.Team1ID, match.Team2ID = first.TeamID, second.TeamID
+		} else {
+			match.Type = models.MatchSingles
+			match.Player1ID, match.Player2ID = first.PlayerID, second.PlayerID
+		}
+		draw.Matches = append(draw.Matches, match)
+	}
+	for _, seed := range byes {
+		draw.Byes = append(draw.Byes, entrants[seed])
+	}
+
+	err = s.store.Transaction(func(tx repositories.Store) error {
+		if err := tx.Matches().CreateAll(append(draw.Walkovers(tournament), draw.Matches...)); err != nil {
+			return err
+		}
+		return tx.Tournaments().UpdateStatus(tournament, models.TournamentDrawn)
+	})
+	if err != nil {
+		return nil, orStale(err, statusChanged())
+	}
+
+	s.notifyEntrants(tournament, models.Notification{
+		Type:    "draw_published",
+		Title:   "The draw for " + tournament.Name + " is out",
+		Message: "Check the draw to see your first-round opponent.",
+	})
+
+	return &draw, nil
+}
+
+// checkedInEntrants returns the tournament's checked-in entrants in seed order.
+// Players are seeded by ranking (1 is best, 0 is unranked); teams by the best
+// ranking among their members. Ties keep registration order.
+func (s *tournamentService) checkedInEntrants(tournament *models.Tournament) ([]DrawEntrant, error) {
+	var entrants []DrawEntrant
+	checkedIn := []models.RegistrationStatus{models.RegistrationCheckedIn}
+
+	if tournament.IsTeamTournament() {
+		registrations, err := s.store.Registrations().TeamsWithStatus(tournament.ID, checkedIn)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range registrations {
+			teamID := r.TeamID
+			best := 0
+			for _, tp := range r.Team.Players {
+				if tp.Player.Ranking > 0 && (best == 0 || tp.Player.Ranking < best) {
+					best = tp.Player.Ranking
+				}
+			}
+			entrants = append(entrants, DrawEntrant{TeamID: &teamID, Ranking: best})
+		}
+	} else {
+		registrations, err := s.store.Registrations().PlayersWithStatus(tournament.ID, checkedIn)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range registrations {
+			playerID := r.PlayerID
+			entrants = append(entrants, DrawEntrant{PlayerID: &playerID, Ranking: r.Player.Ranking})
+		}
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		a, b := entrants[i].Ranking, entrants[j].Ranking
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+
+	return entrants, nil
+}
+
+// seedFirstRound splits n seeded entrants into first-round byes and pairs.
+// The draw is padded to the next power of two; the top seeds receive the
+// byes and the remaining entrants are paired highest against lowest.
+func seedFirstRound(n int) (byes []int, pairs [][2]int) {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+
+	byeCount := size - n
+	for seed := 0; seed < byeCount; seed++ {
+		byes = append(byes, seed)
+	}
+
+	for lo, hi := byeCount, n-1; lo < hi; lo, hi = lo+1, hi-1 {
+		pairs = append(pairs, [2]int{lo, hi})
+	}
+
+	return byes, pairs
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/notify"
+	"badminton-backend/internal/repositories"
+)
+
+// TournamentListing is what the tournament list accepts
+var TournamentListing = listing.Spec{
+	Filters: map[string]listing.Filter{
+		"status": listing.OneOf("status",
+			string(models.TournamentDraft), string(models.TournamentRegistrationOpen), string(models.TournamentRegistrationClosed),
+			string(models.TournamentDrawn), string(models.TournamentOngoing), string(models.TournamentCompleted), string(models.TournamentCancelled)),
+		"type":     listing.OneOf("type", string(models.TournamentSingles), string(models.TournamentDoubles)),
+		"admin_id": listing.ID("admin_id"),
+		"search":   listing.Search("name", "description"),
+		"from":     listing.Since("start_date"),
+		"to":       listing.Until("start_date"),
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"start_date": "start_date",
+		"created_at": "created_at",
+	},
+	DefaultSort: "-start_date",
+}
+
+// TournamentService manages tournaments through their lifecycle
+type TournamentService interface {
+	// List returns a page of tournaments with their matches
+	List(q *listing.Query) ([]models.Tournament, listing.Page, error)
+	// Get returns the tournament with its matches
+	Get(id uint) (*models.Tournament, error)
+	// Create saves a new draft tournament owned by the admin
+	Create(adminID uint, tournament *models.Tournament) error
+	// Update applies the changes to a tournament that has not finished
+	Update(tournament *models.Tournament, changes TournamentChanges) error
+	// Delete removes a draft tournament; later ones must be cancelled
+	Delete(tournament *models.Tournament) error
+
+	OpenRegistration(tournament *models.Tournament) error
+	CloseRegistration(tournament *models.Tournament) error
+	Start(tournament *models.Tournament) error
+	Complete(tournament *models.Tournament) error
+	Cancel(tournament *models.Tournament, reason string) (*Cancellation, error)
+	GenerateDraw(tournament *models.Tournament) (*Draw, error)
+}
+
+// TournamentChanges are the editable tournament details; nil fields are
+// left unchanged
+type TournamentChanges struct {
+	Name                 *string                `json:"name"`
+	Description          *string                `json:"description"`
+	Type                 *models.TournamentType `json:"type"`
+	StartDate            *time.Time             `json:"start_date"`
+	EndDate              *time.Time             `json:"end_date"`
+	MaxPlayers           *int                   `json:"max_players"`
+	MaxTeams             *int                   `json:"max_teams"`
+	EntryFee             *float64               `json:"entry_fee"`
+	PrizePool            *float64               `json:"prize_pool"`
+	CheckInWindowMinutes *int                   `json:"check_in_window_minutes"`
+}
+
+// Cancellation is what cancelling a tournament closed off
+type Cancellation struct {
+	CancelledMatches       int64
+	CancelledRegistrations int64
+	Refunds                []models.Refund
+}
+
+type tournamentService struct {
+	store    repositories.Store
+	notifier *notify.Notifier
+}
+
+func NewTournamentService(store repositories.Store, notifier *notify.Notifier) TournamentService {
+	return &tournamentService{store: store, notifier: notifier}
+}
+
+func (s *tournamentService) List(q *listing.Query) ([]models.Tournament, listing.Page, error) {
+	return s.store.Tournaments().List(q)
+}
+
+func (s *tournamentService) Get(id uint) (*models.Tournament, error) {
+	tournament, err := s.store.Tournaments().Get(id)
+	if err != nil {
+		return nil, orNotFound(err, notFound("not_found", "Tournament not found"))
+	}
+	return tournament, nil
+}
+
+func (s *tournamentService) Create(adminID uint, tournament *models.Tournament) error {
+	// New tournaments always start as drafts owned by their creator
+	tournament.ID = 0
+	tournament.Status = models.TournamentDraft
+	tournament.AdminID = adminID
+	tournament.CheckInClosedAt = nil
+
+	return s.store.Tournaments().Create(tournament)
+}
+
+func (s *tournamentService) Update(tournament *models.Tournament, changes TournamentChanges) error {
+	if tournament.IsFinished() {
+		return conflict("tournament_finished", "Completed or cancelled tournaments cannot be edited")
+	}
+
+	if changes.Type != nil && *changes.Type != tournament.Type {
+		if tournament.Status != models.TournamentDraft {
+			return conflict("type_locked", "Tournament type can only be changed while in draft")
+		}
+		tournament.Type = *changes.Type
+	}
+
+	if changes.Name != nil {
+		tournament.Name = *changes.Name
+	}
+	if changes.Description != nil {
+		tournament.Description = *changes.Description
+	}
+	if changes.StartDate != nil {
+		tournament.StartDate = *changes.StartDate
+	}
+	if changes.EndDate != nil {
+		tournament.EndDate = *changes.EndDate
+	}
+	if changes.MaxPlayers != nil {
+		tournament.MaxPlayers = *changes.MaxPlayers
+	}
+	if changes.MaxTeams != nil {
+		tournament.MaxTeams = *changes.MaxTeams
+	}
+	if changes.EntryFee != nil {
+		tournament.EntryFee = *changes.EntryFee
+	}
+	if changes.PrizePool != nil {
+		tournament.PrizePool = *changes.PrizePool
+	}
+	if changes.CheckInWindowMinutes != nil {
+		tournament.CheckInWindowMinutes = *changes.CheckInWindowMinutes
+	}
+
+	return s.store.Tournaments().Save(tournament)
+}
+
+func (s *tournamentService) Delete(tournament *models.Tournament) error {
+	if tournament.Status != models.TournamentDraft {
+		return conflict("tournament_not_draft", "Only draft tournaments can be deleted, cancel the tournament instead")
+	}
+	return s.store.Tournaments().Delete(tournament)
+}
+
+// OpenRegistration opens (or reopens) registration
+func (s *tournamentService) OpenRegistration(tournament *models.Tournament) error {
+	if tournament.CanTransitionTo(models.TournamentRegistrationOpen) && tournament.IsCheckInClosed() {
+		return conflict("check_in_closed", "Registration cannot reopen after check-in has closed")
+	}
+	return s.transition(tournament, models.TournamentRegistrationOpen, nil)
+}
+
+// CloseRegistration stops new registrations and tells entrants when
+// check-in opens
+func (s *tournamentService) CloseRegistration(tournament *models.Tournament) error {
+	return s.transition(tournament, models.TournamentRegistrationClosed, &models.Notification{
+		Type:    "registration_closed",
+		Title:   "Registration closed for " + tournament.Name,
+		Message: "Check-in opens at " + tournament.CheckInOpensAt().Format("2006-01-02 15:04") + ". Please check in before the tournament starts.",
+	})
+}
+
+// Start moves a drawn tournament into play
+func (s *tournamentService) Start(tournament *models.Tournament) error {
+	matchCount, err := s.store.Matches().CountByTournament(tournament.ID)
+	if err != nil {
+		return err
+	}
+	if matchCount == 0 {
+		return conflict("draw_required", "The draw must be generated before the tournament starts")
+	}
+
+	return s.transition(tournament, models.TournamentOngoing, &models.Notification{
+		Type:    "tournament_started",
+		Title:   tournament.Name + " has started",
+		Message: "Check the draw for your first match.",
+	})
+}
+
+// Complete finishes an ongoing tournament once every match is decided
+func (s *tournamentService) Complete(tournament *models.Tournament) error {
+	openMatches, err := s.store.Matches().CountByTournament(tournament.ID, models.MatchPending, models.MatchOngoing)
+	if err != nil {
+		return err
+	}
+	if openMatches > 0 {
+		return conflict("matches_unfinished", strconv.FormatInt(openMatches, 10)+" matches are still pending or in play")
+	}
+
+	return s.transition(tournament, models.TournamentCompleted, &models.Notification{
+		Type:    "tournament_completed",
+		Title:   tournament.Name + " has finished",
+		Message: "Thanks for playing! Final results are now available.",
+	})
+}
+
+// transition moves the tournament to the next status if the state machine
+// allows it, notifies entrants and reloads it
+func (s *tournamentService) transition(tournament *models.Tournament, next models.TournamentStatus, notification *models.Notification) error {
+	if !tournament.CanTransitionTo(next) {
+		return conflict("invalid_transition", "Cannot move tournament from "+string(tournament.Status)+" to "+string(next))
+	}
+
+	if err := s.store.Tournaments().UpdateStatus(tournament, next); err != nil {
+		return orStale(err, statusChanged())
+	}
+
+	if notification != nil {
+		s.notifyEntrants(tournament, *notification)
+	}
+
+	if reloaded, err := s.store.Tournaments().Get(tournament.ID); err == nil {
+		*tournament = *reloaded
+	}
+	return nil
+}
+
+// notifyEntrants notifies the tournament's entrants. Delivery failures are
+// logged rather than failing a change that has already happened.
+func (s *tournamentService) notifyEntrants(tournament *models.Tournament, notification models.Notification) {
+	if err := s.notifier.NotifyEntrants(tournament, notification); err != nil {
+		log.Printf("Failed to notify entrants of tournament %d: %v", tournament.ID, err)
+	}
+}
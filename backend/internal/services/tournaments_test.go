@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/notify"
+)
+
+// A status change made from a copy loaded before another request moved the
+// tournament on must fail instead of overwriting the newer status
+func TestTransitionRejectsStaleStatus(t *testing.T) {
+	db, store := openTestStore(t)
+	admin := createVerifiedPlayers(t, db, 1)[0]
+	tournament := createOpenTournament(t, store, admin.ID, 8)
+	service := NewTournamentService(store, notify.New(db))
+
+	stale := *tournament
+	if err := service.CloseRegistration(tournament); err != nil {
+		t.Fatal(err)
+	}
+
+	var serviceErr *Error
+	err := service.CloseRegistration(&stale)
+	if !errors.As(err, &serviceErr) || serviceErr.Code != "status_changed" {
+		t.Fatalf("closing registration from a stale copy returned %v, want status_changed", err)
+	}
+
+	reloaded, err := store.Tournaments().Get(tournament.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Status != models.TournamentRegistrationClosed {
+		t.Errorf("status is %s, want %s", reloaded.Status, models.TournamentRegistrationClosed)
+	}
+}
+
+// Cancelling closes every open registration but only refunds entrants who
+// held a place
+func TestCancelRefundsOnlyEntrantsWithAPlace(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 6)
+	tournament := createOpenTournament(t, store, players[0].ID, 8)
+	tournament.EntryFee = 15
+	if err := store.Tournaments().Save(tournament); err != nil {
+		t.Fatal(err)
+	}
+	service := NewTournamentService(store, notify.New(db))
+
+	statuses := []models.RegistrationStatus{
+		models.RegistrationRegistered,
+		models.RegistrationConfirmed,
+		models.RegistrationCheckedIn,
+		models.RegistrationWaitlisted,
+		models.RegistrationNoShow,
+		models.RegistrationWithdrawn,
+	}
+	for i, status := range statuses {
+		registration := models.TournamentPlayer{TournamentID: tournament.ID, PlayerID: players[i].ID, Status: status}
+		if err := store.Registrations().CreatePlayer(&registration); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancellation, err := service.Cancel(tournament, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refunded := make(map[uint]bool)
+	for _, refund := range cancellation.Refunds {
+		refunded[refund.UserID] = true
+	}
+	if len(cancellation.Refunds) != 3 || !refunded[players[0].ID] || !refunded[players[1].ID] || !refunded[players[2].ID] {
+		t.Errorf("refunded %v, want the registered, confirmed and checked-in players", refunded)
+	}
+	if cancellation.CancelledRegistrations != 5 {
+		t.Errorf("cancelled %d registrations, want 5", cancellation.CancelledRegistrations)
+	}
+}
+
+// Byes are stored as completed first-round matches won by the top seeds,
+// next to the matches still to be played
+func TestGenerateDrawRecordsByes(t *testing.T) {
+	db, store := openTestStore(t)
+	players := createVerifiedPlayers(t, db, 3)
+	tournament := createOpenTournament(t, store, players[0].ID, 8)
+	service := NewTournamentService(store, notify.New(db))
+
+	for i, player := range players {
+		// The last player is the top seed
+		if err := db.Model(player).Update("ranking", len(players)-i).Error; err != nil {
+			t.Fatal(err)
+		}
+		registration := models.TournamentPlayer{TournamentID: tournament.ID, PlayerID: player.ID, Status: models.RegistrationCheckedIn}
+		if err := store.Registrations().CreatePlayer(&registration); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.CloseRegistration(tournament); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	tournament.CheckInClosedAt = &now
+
+	draw, err := service.GenerateDraw(tournament)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(draw.Matches) != 1 || len(draw.Byes) != 1 || *draw.Byes[0].PlayerID != players[2].ID {
+		t.Fatalf("drew %d matches and byes %+v, want one match and a bye for the top seed", len(draw.Matches), draw.Byes)
+	}
+
+	var walkovers []models.Match
+	if err := db.Where("tournament_id = ? AND status = ?", tournament.ID, models.MatchCompleted).Find(&walkovers).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(walkovers) != 1 || !walkovers[0].IsWalkover() || walkovers[0].WinnerSide() != 1 || *walkovers[0].Player1ID != players[2].ID {
+		t.Errorf("stored walkovers %+v, want one won by the top seed", walkovers)
+	}
+	if count, err := store.Matches().CountByTournament(tournament.ID); err != nil || count != 2 {
+		t.Errorf("stored %d matches (%v), want the match and the walkover", count, err)
+	}
+}
@@ -0,0 +1,161 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/middleware"
+	"badminton-backend/internal/models"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again; the session is revoked as it has likely been stolen
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// Tokens is an access token with the refresh token that renews it
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
+
+// Manager issues, rotates and revokes login sessions
+type Manager struct {
+	db *gorm.DB
+}
+
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// Issue starts a new session for the user and returns its first tokens.
+// mfa records whether the login passed a second factor.
+func (m *Manager) Issue(user *models.User, mfa bool, userAgent, ipAddress string) (Tokens, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(middleware.RefreshTokenTTL()),
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
+		MFA:              mfa,
+	}
+	if err := m.db.Create(&session).Error; err != nil {
+		return Tokens{}, err
+	}
+
+	return m.tokens(user, &session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for new tokens, rotating the refresh
+// token so each one can only be used once
+func (m *Manager) Refresh(refreshToken, userAgent, ipAddress string) (Tokens, *models.User, error) {
+	hash := hashToken(refreshToken)
+	now := time.Now()
+
+	var session models.Session
+	err := m.db.Preload("User").Where("refresh_token_hash = ?", hash).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		// A rotated token being replayed means the chain has leaked
+		var reused models.Session
+		if m.db.Where("previous_token_hash = ?", hash).First(&reused).Error == nil {
+			m.Revoke(reused.ID)
+			return Tokens{}, nil, ErrRefreshTokenReused
+		}
+		return Tokens{}, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Tokens{}, nil, err
+	}
+
+	if !session.IsActive(now) || !session.User.IsActive {
+		return Tokens{}, nil, ErrInvalidRefreshToken
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return Tokens{}, nil, err
+	}
+
+	// Only rotate if nobody else rotated this token in the meantime
+	result := m.db.Model(&session).
+		Where("refresh_token_hash = ?", hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  hashToken(newToken),
+			"previous_token_hash": hash,
+			"last_refreshed_at":   now,
+			"user_agent":          userAgent,
+			"ip_address":          ipAddress,
+		})
+	if result.Error != nil {
+		return Tokens{}, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return Tokens{}, nil, ErrInvalidRefreshToken
+	}
+
+	tokens, err := m.tokens(&session.User, &session, newToken)
+	return tokens, &session.User, err
+}
+
+// MarkMFA records that the session's user has just passed a second factor
+func (m *Manager) MarkMFA(sessionID uint) error {
+	return m.db.Model(&models.Session{}).Where("id = ?", sessionID).Update("mfa", true).Error
+}
+
+// Revoke ends a single session
+func (m *Manager) Revoke(sessionID uint) error {
+	return m.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAll ends every session of a user, logging them out on all devices
+func (m *Manager) RevokeAll(userID uint) error {
+	return m.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// tokens signs an access token for the session
+func (m *Manager) tokens(user *models.User, session *models.Session, refreshToken string) (Tokens, error) {
+	accessToken, err := middleware.GenerateToken(user, session.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(middleware.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// newRefreshToken returns a random opaque refresh token
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash under which a token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package throttle
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// Policy controls how failures on a key are throttled
+type Policy struct {
+	FreeAttempts     int           // failures allowed before any delay
+	BaseDelay        time.Duration // delay after the first throttled failure, doubled on each one after
+	MaxDelay         time.Duration
+	LockoutThreshold int // failures that lock the key out
+	LockoutDuration  time.Duration
+	Window           time.Duration // failures are forgotten after this long without another
+}
+
+var (
+	// AccountPolicy throttles guesses against a single account
+	AccountPolicy = Policy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  30 * time.Minute,
+		Window:           time.Hour,
+	}
+
+	// IPPolicy throttles a single client guessing across many accounts
+	IPPolicy = Policy{
+		FreeAttempts:     10,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 50,
+		LockoutDuration:  time.Hour,
+		Window:           time.Hour,
+	}
+)
+
+// AccountKey is the throttle key of a user's account, shared by password
+// guesses made under any of its names and by its second factor
+func AccountKey(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// IdentifierKey is the throttle key of a login name that matches no user.
+// Unknown names are throttled like accounts, so lockouts behave the same
+// for real and unknown accounts and do not reveal which exist.
+func IdentifierKey(identifier string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// IPKey is the throttle key of a client address
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Guard tracks failed login attempts
+type Guard struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Guard {
+	return &Guard{db: db}
+}
+
+// RetryAfter returns how long the longest-blocked of the keys must wait
+// before trying again, or zero if none are blocked
+func (g *Guard) RetryAfter(keys ...string) (time.Duration, error) {
+	var throttles []models.LoginThrottle
+	if err := g.db.Where("key IN ?", keys).Find(&throttles).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, t := range throttles {
+		if d := t.RetryAfter(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt on the key and blocks it according to the
+// policy. It reports whether this failure locked the key out.
+func (g *Guard) Fail(key string, policy Policy) (bool, error) {
+	now := time.Now()
+	lockedOut := false
+
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		var t models.LoginThrottle
+		err := tx.Where("key = ?", key).First(&t).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		switch {
+		case t.RetryAfter(now) > 0:
+			// Still blocked; only reachable by requests racing the block
+		case now.Sub(t.LastFailureAt) > policy.Window:
+			// Start over once the previous failures are old
+			t.Failures, t.LockedOut, t.BlockedUntil = 0, false, nil
+		case t.LockedOut:
+			// After a lockout expires, further failures back off straight away
+			t.Failures, t.LockedOut = policy.FreeAttempts, false
+		}
+
+		t.Key = key
+		t.Failures++
+		t.LastFailureAt = now
+
+		switch {
+		case t.LockedOut:
+			// Still locked out; a racing attempt must not extend or re-report it
+		case t.Failures >= policy.LockoutThreshold:
+			until := now.Add(policy.LockoutDuration)
+			t.BlockedUntil = &until
+			t.LockedOut = true
+			lockedOut = true
+		case t.Failures > policy.FreeAttempts:
+			until := now.Add(policy.delay(t.Failures))
+			t.BlockedUntil = &until
+		}
+
+		return tx.Save(&t).Error
+	})
+
+	return lockedOut, err
+}
+
+// Reset forgets all failures on the keys
+func (g *Guard) Reset(keys ...string) error {
+	return g.db.Unscoped().Where("key IN ?", keys).Delete(&models.LoginThrottle{}).Error
+}
+
+// delay is the exponential backoff after the given number of failures
+func (p Policy) delay(failures int) time.Duration {
+	d := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return d
+}
@@ -0,0 +1,126 @@
+package usertokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"badminton-backend/internal/models"
+)
+
+// ErrInvalidToken is returned for unknown, expired or already used tokens
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Lifetimes of the emailed tokens
+const (
+	EmailVerificationTTL = 48 * time.Hour
+	PasswordResetTTL     = time.Hour
+	MFAChallengeTTL      = 5 * time.Minute
+	OIDCLoginTTL         = time.Minute
+	AccountClaimTTL      = 30 * 24 * time.Hour
+)
+
+// Manager issues and redeems single-use user tokens
+type Manager struct {
+	db *gorm.DB
+}
+
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// Issue creates a token for the user, invalidating any earlier unused
+// token with the same purpose, and returns the plain token to email
+func (m *Manager) Issue(user *models.User, purpose models.UserTokenPurpose, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	now := time.Now()
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: hashToken(token),
+			Email:     user.Email,
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Lookup returns the user token if it is still usable, without using it up
+func (m *Manager) Lookup(token string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	var userToken models.UserToken
+	err := m.db.Preload("User").
+		Where("token_hash = ? AND purpose = ?", hashToken(token), purpose).
+		First(&userToken).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !userToken.IsUsable(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	return &userToken, nil
+}
+
+// Redeem uses up a token for the given purpose and runs apply in the same
+// transaction, so the token is only spent if apply succeeds
+func (m *Manager) Redeem(token string, purpose models.UserTokenPurpose, apply func(tx *gorm.DB, userToken *models.UserToken) error) error {
+	now := time.Now()
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		var userToken models.UserToken
+		err := tx.Preload("User").
+			Where("token_hash = ? AND purpose = ?", hashToken(token), purpose).
+			First(&userToken).Error
+		if err == gorm.ErrRecordNotFound {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if !userToken.IsUsable(now) {
+			return ErrInvalidToken
+		}
+
+		// Only the first of two concurrent redemptions wins
+		result := tx.Model(&userToken).Where("used_at IS NULL").Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidToken
+		}
+
+		return apply(tx, &userToken)
+	})
+}
+
+// hashToken returns the hash under which a token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,193 @@
+package views
+
+import (
+	"time"
+
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/services"
+)
+
+// Request bodies bound by the handlers. Fields without binding:"required"
+// may be left out.
+
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	FullName string `json:"full_name" binding:"required"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // authenticator or recovery code
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type ClaimAccountRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username"` // keeps the generated username when empty
+}
+
+type OIDCExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Ranking  int    `json:"ranking"` // Only for players
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string             `json:"name" binding:"required,max=100"`
+	Scope         models.APIKeyScope `json:"scope" binding:"required"`
+	ExpiresInDays int                `json:"expires_in_days" binding:"min=0,max=3650"` // 0 means the key does not expire, admin keys default to 30 days
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+type UnlockUserRequest struct {
+	IPAddress string `json:"ip_address"` // also clears this address's lockout
+}
+
+type CreatePlayerRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Ranking  int    `json:"ranking"`
+}
+
+type UpdatePlayerRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Ranking *int   `json:"ranking"`
+}
+
+// MatchRequest creates a match, or edits one when sent with the fields to
+// change
+type MatchRequest struct {
+	TournamentID   *uint              `json:"tournament_id"`
+	Type           models.MatchType   `json:"type"`
+	Status         models.MatchStatus `json:"status"`
+	MatchDate      time.Time          `json:"match_date"`
+	Round          string             `json:"round"`
+	Player1ID      *uint              `json:"player1_id"`
+	Player2ID      *uint              `json:"player2_id"`
+	Player1Score   int                `json:"player1_score"`
+	Player2Score   int                `json:"player2_score"`
+	Team1ID        *uint              `json:"team1_id"`
+	Team2ID        *uint              `json:"team2_id"`
+	Team1Score     int                `json:"team1_score"`
+	Team2Score     int                `json:"team2_score"`
+	WinnerPlayerID *uint              `json:"winner_player_id"`
+	WinnerTeamID   *uint              `json:"winner_team_id"`
+}
+
+type ReportResultRequest struct {
+	Side1Score *int `json:"side1_score" binding:"required"`
+	Side2Score *int `json:"side2_score" binding:"required"`
+}
+
+type DisputeResultRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ResolveResultRequest struct {
+	Accept     *bool  `json:"accept" binding:"required"`
+	Side1Score *int   `json:"side1_score"` // corrected scores when not accepted
+	Side2Score *int   `json:"side2_score"`
+	Note       string `json:"note"`
+}
+
+type CreateTournamentRequest struct {
+	Name                 string                `json:"name"`
+	Description          string                `json:"description"`
+	Type                 models.TournamentType `json:"type"`
+	StartDate            time.Time             `json:"start_date"`
+	EndDate              time.Time             `json:"end_date"`
+	MaxPlayers           int                   `json:"max_players"`
+	MaxTeams             int                   `json:"max_teams"`
+	EntryFee             float64               `json:"entry_fee"`
+	PrizePool            float64               `json:"prize_pool"`
+	CheckInWindowMinutes int                   `json:"check_in_window_minutes"`
+}
+
+type UpdateTournamentRequest struct {
+	services.TournamentChanges
+	Status *string `json:"status"` // rejected, status changes through the transition endpoints
+}
+
+type CancelTournamentRequest struct {
+	Reason string `json:"reason"`
+}
+
+type CloseCheckInRequest struct {
+	PromoteWaitlist bool `json:"promote_waitlist"`
+}
+
+type AddStaffRequest struct {
+	UserID uint             `json:"user_id" binding:"required"`
+	Role   models.StaffRole `json:"role" binding:"required"`
+}
+
+type RegisterTeamRequest struct {
+	TeamID uint `json:"team_id" binding:"required"`
+}
+
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	PartnerID   uint   `json:"partner_id" binding:"required"`
+}
+
+type UpdateTeamRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type TeamPlayerRequest struct {
+	PlayerID uint `json:"player_id" binding:"required"`
+}
@@ -1,6 +1,13 @@
 package views
 
-import "badminton-backend/internal/models"
+import (
+	"encoding/json"
+	"time"
+
+	"badminton-backend/internal/listing"
+	"badminton-backend/internal/models"
+	"badminton-backend/internal/search"
+)
 
 type PlayerResponse struct {
 	ID      uint   `json:"id"`
@@ -9,15 +16,29 @@ type PlayerResponse struct {
 	Ranking int    `json:"ranking"`
 }
 
+// MatchResponse shows singles and doubles matches alike, as two sides each
+// fielding a team. In singles the team is the one player.
 type MatchResponse struct {
-	ID           uint            `json:"id"`
-	Player1      PlayerResponse  `json:"player1"`
-	Player2      PlayerResponse  `json:"player2"`
-	Player1Score int             `json:"player1_score"`
-	Player2Score int             `json:"player2_score"`
-	Status       string          `json:"status"`
-	MatchDate    string          `json:"match_date"`
-	Tournament   *TournamentInfo `json:"tournament,omitempty"`
+	ID         uint              `json:"id"`
+	Type       string            `json:"type"`
+	Status     string            `json:"status"`
+	Round      string            `json:"round"`
+	MatchDate  string            `json:"match_date"`
+	Side1      MatchSideResponse `json:"side1"`
+	Side2      MatchSideResponse `json:"side2"`
+	WinnerSide *int              `json:"winner_side"` // 1 or 2, null until decided
+	Tournament *TournamentInfo   `json:"tournament,omitempty"`
+}
+
+type MatchSideResponse struct {
+	Team  MatchTeamResponse `json:"team"`
+	Score int               `json:"score"`
+}
+
+type MatchTeamResponse struct {
+	ID      *uint            `json:"id"`   // null in singles
+	Name    string           `json:"name"` // the player's name in singles
+	Members []PlayerResponse `json:"members"`
 }
 
 type TournamentResponse struct {
@@ -36,14 +57,259 @@ type TournamentInfo struct {
 	Name string `json:"name"`
 }
 
+type TeamMemberResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+	Ranking  int    `json:"ranking"`
+}
+
+type TeamResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	CaptainID   uint                 `json:"captain_id"`
+	Members     []TeamMemberResponse `json:"members"`
+}
+
+type EntrantResponse struct {
+	RegistrationID uint       `json:"registration_id"`
+	PlayerID       *uint      `json:"player_id,omitempty"`
+	TeamID         *uint      `json:"team_id,omitempty"`
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	CheckedInAt    *time.Time `json:"checked_in_at"`
+}
+
+type RefundResponse struct {
+	ID          uint            `json:"id"`
+	Tournament  *TournamentInfo `json:"tournament,omitempty"`
+	User        UserResponse    `json:"user"`
+	TeamID      *uint           `json:"team_id,omitempty"`
+	Amount      float64         `json:"amount"`
+	Reason      string          `json:"reason"`
+	Status      string          `json:"status"`
+	ProcessedAt *time.Time      `json:"processed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type TournamentStaffResponse struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	Username   string    `json:"username"`
+	FullName   string    `json:"full_name"`
+	Role       string    `json:"role"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+type MatchResultResponse struct {
+	ID             uint       `json:"id"`
+	MatchID        uint       `json:"match_id"`
+	ReportedByID   uint       `json:"reported_by_id"`
+	ReporterSide   int        `json:"reporter_side"`
+	Side1Score     int        `json:"side1_score"`
+	Side2Score     int        `json:"side2_score"`
+	Status         string     `json:"status"`
+	RespondedByID  *uint      `json:"responded_by_id,omitempty"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	DisputeReason  string     `json:"dispute_reason,omitempty"`
+	ResolvedByID   *uint      `json:"resolved_by_id,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type StandingResponse struct {
+	ID            uint   `json:"id"` // user ID for singles, team ID for doubles
+	Name          string `json:"name"`
+	Played        int    `json:"played"`
+	Won           int    `json:"won"`
+	Lost          int    `json:"lost"`
+	PointsFor     int    `json:"points_for"`
+	PointsAgainst int    `json:"points_against"`
+}
+
+type APIKeyResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scope       string     `json:"scope"`
+	CreatedByID uint       `json:"created_by_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	ID         uint            `json:"id"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID *uint           `json:"resource_id"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	Details    string          `json:"details,omitempty"`
+	IPAddress  string          `json:"ip_address"`
+	Method     string          `json:"method,omitempty"`
+	Path       string          `json:"path,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	SessionID  *uint           `json:"session_id,omitempty"`
+	APIKeyID   *uint           `json:"api_key_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type SearchResultResponse struct {
+	Type     string  `json:"type"` // player, team or tournament
+	ID       uint    `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle,omitempty"`
+	Rank     float64 `json:"rank"`
+}
+
+type AuthResponse struct {
+	User         UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"` // access token lifetime in seconds
+}
+
+// MFAChallengeResponse answers a correct password when the account has
+// two-factor authentication on; the login completes at /login/mfa
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type AuthorizationURLResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type IdentityResponse struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreatedAPIKeyResponse carries the key itself, which is only shown once
+type CreatedAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey APIKeyResponse `json:"api_key"`
+}
+
+type NotificationResponse struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	Type         string     `json:"type"`
+	Title        string     `json:"title"`
+	Message      string     `json:"message"`
+	TournamentID *uint      `json:"tournament_id"`
+	MatchID      *uint      `json:"match_id"`
+	ReadAt       *time.Time `json:"read_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type RegistrationStatusResponse struct {
+	RegistrationID uint   `json:"registration_id"`
+	Status         string `json:"status"`
+}
+
+type PlayerRegistrationResponse struct {
+	ID           uint               `json:"id"`
+	TournamentID uint               `json:"tournament_id"`
+	Status       string             `json:"status"`
+	CheckedInAt  *time.Time         `json:"checked_in_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Tournament   TournamentResponse `json:"tournament"`
+}
+
+type CheckInListResponse struct {
+	CheckInOpensAt  time.Time         `json:"check_in_opens_at"`
+	CheckInClosedAt *time.Time        `json:"check_in_closed_at"`
+	Entrants        []EntrantResponse `json:"entrants"`
+}
+
+type CheckInClosedResponse struct {
+	NoShows  int64 `json:"no_shows"` // registrations marked as no-shows
+	Promoted int64 `json:"promoted"` // waitlisted entrants moved into the field
+}
+
+type CreatedTeamResponse struct {
+	TeamID   uint   `json:"team_id"`
+	TeamName string `json:"team_name"`
+}
+
+type CancellationResponse struct {
+	Tournament             TournamentResponse `json:"tournament"`
+	CancelledMatches       int64              `json:"cancelled_matches"`
+	CancelledRegistrations int64              `json:"cancelled_registrations"`
+	RefundsCreated         int                `json:"refunds_created"`
+}
+
+type DrawResponse struct {
+	MatchCount int           `json:"match_count"`
+	Byes       []ByeResponse `json:"byes"`
+}
+
+// ByeResponse is an entrant going straight to the second round, a player
+// in singles and a team in doubles
+type ByeResponse struct {
+	PlayerID *uint `json:"player_id"`
+	TeamID   *uint `json:"team_id"`
+}
+
+type TournamentStaffListResponse struct {
+	AdminID uint                      `json:"admin_id"`
+	Staff   []TournamentStaffResponse `json:"staff"`
+}
+
+type TournamentRolesResponse struct {
+	Roles       []models.StaffRole            `json:"roles"`
+	Permissions []models.TournamentPermission `json:"permissions"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 }
 
 type SuccessResponse struct {
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Message string          `json:"message"`
+	Data    interface{}     `json:"data,omitempty"`
+	Meta    *PaginationMeta `json:"meta,omitempty"` // set by paginated list endpoints
+}
+
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
 }
 
 type UserResponse struct {
@@ -54,9 +320,22 @@ type UserResponse struct {
 	Role     string `json:"role"`
 	IsActive bool   `json:"is_active"`
 	Ranking  int    `json:"ranking,omitempty"` // Only for players
+
+	EmailVerified    bool `json:"email_verified"`
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+	ClaimPending     bool `json:"claim_pending"` // converted legacy player not yet claimed
 }
 
 // Helper functions to convert models to responses
+func ToPaginationMeta(page listing.Page) *PaginationMeta {
+	return &PaginationMeta{
+		Page:       page.Number,
+		Limit:      page.Limit,
+		Total:      page.Total,
+		TotalPages: page.Pages(),
+	}
+}
+
 func ToUserResponse(user models.User) UserResponse {
 	return UserResponse{
 		ID:       user.ID,
@@ -66,45 +345,41 @@ func ToUserResponse(user models.User) UserResponse {
 		Role:     string(user.Role),
 		IsActive: user.IsActive,
 		Ranking:  user.Ranking,
+
+		EmailVerified:    user.IsEmailVerified(),
+		TwoFactorEnabled: user.IsTOTPEnabled(),
+		ClaimPending:     user.ClaimPending,
 	}
 }
 
-func ToPlayerResponse(player models.Player) PlayerResponse {
+func ToPlayerResponse(user models.User) PlayerResponse {
 	return PlayerResponse{
-		ID:      player.ID,
-		Name:    player.Name,
-		Email:   player.Email,
-		Ranking: player.Ranking,
+		ID:      user.ID,
+		Name:    user.FullName,
+		Email:   user.Email,
+		Ranking: user.Ranking,
 	}
 }
 
 func ToMatchResponse(match models.Match) MatchResponse {
-	var player1, player2 PlayerResponse
-	if match.Player1 != nil {
-		player1 = PlayerResponse{
-			ID:      match.Player1.ID,
-			Name:    match.Player1.FullName,
-			Email:   match.Player1.Email,
-			Ranking: match.Player1.Ranking,
-		}
+	response := MatchResponse{
+		ID:        match.ID,
+		Type:      string(match.Type),
+		Status:    string(match.Status),
+		Round:     match.Round,
+		MatchDate: match.MatchDate.Format("2006-01-02 15:04:05"),
 	}
-	if match.Player2 != nil {
-		player2 = PlayerResponse{
-			ID:      match.Player2.ID,
-			Name:    match.Player2.FullName,
-			Email:   match.Player2.Email,
-			Ranking: match.Player2.Ranking,
-		}
+
+	if match.IsTeamMatch() {
+		response.Side1 = MatchSideResponse{Team: toMatchTeam(match.Team1), Score: match.Team1Score}
+		response.Side2 = MatchSideResponse{Team: toMatchTeam(match.Team2), Score: match.Team2Score}
+	} else {
+		response.Side1 = MatchSideResponse{Team: toMatchPlayer(match.Player1), Score: match.Player1Score}
+		response.Side2 = MatchSideResponse{Team: toMatchPlayer(match.Player2), Score: match.Player2Score}
 	}
 
-	response := MatchResponse{
-		ID:           match.ID,
-		Player1:      player1,
-		Player2:      player2,
-		Player1Score: match.Player1Score,
-		Player2Score: match.Player2Score,
-		Status:       string(match.Status),
-		MatchDate:    match.MatchDate.Format("2006-01-02 15:04:05"),
+	if side := match.WinnerSide(); side != 0 {
+		response.WinnerSide = &side
 	}
 
 	if match.Tournament != nil {
@@ -117,6 +392,160 @@ func ToMatchResponse(match models.Match) MatchResponse {
 	return response
 }
 
+// toMatchTeam is a doubles side; a side not yet filled has no members
+func toMatchTeam(team *models.Team) MatchTeamResponse {
+	if team == nil {
+		return MatchTeamResponse{Members: []PlayerResponse{}}
+	}
+
+	members := make([]PlayerResponse, len(team.Players))
+	for i, tp := range team.Players {
+		members[i] = ToPlayerResponse(tp.Player)
+	}
+	id := team.ID
+	return MatchTeamResponse{ID: &id, Name: team.Name, Members: members}
+}
+
+// toMatchPlayer is a singles side, a team of the one player
+func toMatchPlayer(player *models.User) MatchTeamResponse {
+	if player == nil {
+		return MatchTeamResponse{Members: []PlayerResponse{}}
+	}
+	return MatchTeamResponse{Name: player.FullName, Members: []PlayerResponse{ToPlayerResponse(*player)}}
+}
+
+func ToTeamResponse(team models.Team) TeamResponse {
+	members := make([]TeamMemberResponse, len(team.Players))
+	for i, tp := range team.Players {
+		members[i] = TeamMemberResponse{
+			ID:       tp.PlayerID,
+			Username: tp.Player.Username,
+			FullName: tp.Player.FullName,
+			Role:     tp.Role,
+			Ranking:  tp.Player.Ranking,
+		}
+	}
+
+	return TeamResponse{
+		ID:          team.ID,
+		Name:        team.Name,
+		Description: team.Description,
+		CaptainID:   team.CaptainID(),
+		Members:     members,
+	}
+}
+
+func ToPlayerEntrantResponse(registration models.TournamentPlayer) EntrantResponse {
+	playerID := registration.PlayerID
+	return EntrantResponse{
+		RegistrationID: registration.ID,
+		PlayerID:       &playerID,
+		Name:           registration.Player.FullName,
+		Status:         string(registration.Status),
+		CheckedInAt:    registration.CheckedInAt,
+	}
+}
+
+func ToTeamEntrantResponse(registration models.TournamentTeam) EntrantResponse {
+	teamID := registration.TeamID
+	return EntrantResponse{
+		RegistrationID: registration.ID,
+		TeamID:         &teamID,
+		Name:           registration.Team.Name,
+		Status:         string(registration.Status),
+		CheckedInAt:    registration.CheckedInAt,
+	}
+}
+
+func ToRefundResponse(refund models.Refund) RefundResponse {
+	response := RefundResponse{
+		ID:          refund.ID,
+		User:        ToUserResponse(refund.User),
+		TeamID:      refund.TeamID,
+		Amount:      refund.Amount,
+		Reason:      refund.Reason,
+		Status:      string(refund.Status),
+		ProcessedAt: refund.ProcessedAt,
+		CreatedAt:   refund.CreatedAt,
+	}
+
+	if refund.Tournament.ID != 0 {
+		response.Tournament = &TournamentInfo{
+			ID:   refund.Tournament.ID,
+			Name: refund.Tournament.Name,
+		}
+	}
+
+	return response
+}
+
+func ToTournamentStaffResponse(staff models.TournamentStaff) TournamentStaffResponse {
+	return TournamentStaffResponse{
+		ID:         staff.ID,
+		UserID:     staff.UserID,
+		Username:   staff.User.Username,
+		FullName:   staff.User.FullName,
+		Role:       string(staff.Role),
+		AssignedAt: staff.CreatedAt,
+	}
+}
+
+func ToMatchResultResponse(result models.MatchResult) MatchResultResponse {
+	return MatchResultResponse{
+		ID:             result.ID,
+		MatchID:        result.MatchID,
+		ReportedByID:   result.ReportedByID,
+		ReporterSide:   result.ReporterSide,
+		Side1Score:     result.Side1Score,
+		Side2Score:     result.Side2Score,
+		Status:         string(result.Status),
+		RespondedByID:  result.RespondedByID,
+		RespondedAt:    result.RespondedAt,
+		DisputeReason:  result.DisputeReason,
+		ResolvedByID:   result.ResolvedByID,
+		ResolvedAt:     result.ResolvedAt,
+		ResolutionNote: result.ResolutionNote,
+		CreatedAt:      result.CreatedAt,
+	}
+}
+
+func ToAPIKeyResponse(apiKey models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Prefix:      apiKey.Prefix,
+		Scope:       string(apiKey.Scope),
+		CreatedByID: apiKey.CreatedByID,
+		ExpiresAt:   apiKey.ExpiresAt,
+		LastUsedAt:  apiKey.LastUsedAt,
+		LastUsedIP:  apiKey.LastUsedIP,
+		RevokedAt:   apiKey.RevokedAt,
+		CreatedAt:   apiKey.CreatedAt,
+	}
+}
+
+func ToAuditLogResponse(entry models.AuditLog) AuditLogResponse {
+	response := AuditLogResponse{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		Resource:   entry.TargetType,
+		ResourceID: entry.TargetID,
+		Details:    entry.Details,
+		IPAddress:  entry.IPAddress,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		UserAgent:  entry.UserAgent,
+		SessionID:  entry.SessionID,
+		APIKeyID:   entry.APIKeyID,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.Changes != "" {
+		response.Changes = json.RawMessage(entry.Changes)
+	}
+	return response
+}
+
 func ToTournamentResponse(tournament models.Tournament) TournamentResponse {
 	return TournamentResponse{
 		ID:          tournament.ID,
@@ -129,3 +558,51 @@ func ToTournamentResponse(tournament models.Tournament) TournamentResponse {
 		MatchCount:  len(tournament.Matches),
 	}
 }
+
+func ToSearchResultResponse(result search.Result) SearchResultResponse {
+	return SearchResultResponse{
+		Type:     result.Kind,
+		ID:       result.ID,
+		Title:    result.Title,
+		Subtitle: result.Subtitle,
+		Rank:     result.Rank,
+	}
+}
+
+func ToIdentityResponse(identity models.UserIdentity) IdentityResponse {
+	return IdentityResponse{
+		ID:        identity.ID,
+		UserID:    identity.UserID,
+		Issuer:    identity.Issuer,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+		UpdatedAt: identity.UpdatedAt,
+	}
+}
+
+func ToNotificationResponse(notification models.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:           notification.ID,
+		UserID:       notification.UserID,
+		Type:         notification.Type,
+		Title:        notification.Title,
+		Message:      notification.Message,
+		TournamentID: notification.TournamentID,
+		MatchID:      notification.MatchID,
+		ReadAt:       notification.ReadAt,
+		CreatedAt:    notification.CreatedAt,
+		UpdatedAt:    notification.UpdatedAt,
+	}
+}
+
+func ToPlayerRegistrationResponse(registration models.TournamentPlayer) PlayerRegistrationResponse {
+	return PlayerRegistrationResponse{
+		ID:           registration.ID,
+		TournamentID: registration.TournamentID,
+		Status:       string(registration.Status),
+		CheckedInAt:  registration.CheckedInAt,
+		CreatedAt:    registration.CreatedAt,
+		Tournament:   ToTournamentResponse(registration.Tournament),
+	}
+}
@@ -167,6 +167,10 @@ export default function AdminPage() {
           router.push('/dashboard');
           return;
         }
+        if (!userProfile.two_factor_enabled) {
+          router.push('/security');
+          return;
+        }
 
         setCurrentUser(userProfile);
 
@@ -0,0 +1,61 @@
+'use client';
+
+import { useState, FormEvent } from 'react';
+import { useRouter } from 'next/navigation';
+import { authAPI } from '../../../utils/api';
+import { AuthForm, FormField } from '../../../components/auth/AuthForm';
+
+export default function ClaimAccountPage() {
+  const [username, setUsername] = useState('');
+  const [password, setPassword] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+  const router = useRouter();
+
+  const handleSubmit = async (e: FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      const token = new URLSearchParams(window.location.search).get('token') || '';
+      const response = await authAPI.claimAccount({ token, password, username: username || undefined });
+      localStorage.setItem('token', response.data.token);
+      localStorage.setItem('refresh_token', response.data.refresh_token);
+      localStorage.setItem('user', JSON.stringify(response.data.user));
+      router.push('/dashboard');
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Claiming the account failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <AuthForm
+      title="Claim Your Account"
+      onSubmit={handleSubmit}
+      loading={loading}
+      error={error}
+      submitText="Claim account"
+      footerText="Link expired?"
+      footerLink={{ text: 'Reset your password instead', href: '/auth/forgot-password' }}
+    >
+      <FormField
+        label="Username (leave empty to keep the one in your email)"
+        value={username}
+        onChange={(e) => setUsername(e.target.value)}
+        disabled={loading}
+      />
+      <FormField
+        label="Password"
+        type="password"
+        value={password}
+        onChange={(e) => setPassword(e.target.value)}
+        required
+        minLength={6}
+        disabled={loading}
+      />
+    </AuthForm>
+  );
+}
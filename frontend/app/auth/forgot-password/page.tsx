@@ -0,0 +1,49 @@
+'use client';
+
+import { useState, FormEvent } from 'react';
+import { authAPI } from '../../../utils/api';
+import { AuthForm, FormField } from '../../../components/auth/AuthForm';
+
+export default function ForgotPasswordPage() {
+  const [email, setEmail] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+
+  const handleSubmit = async (e: FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      const response = await authAPI.forgotPassword(email);
+      setMessage(response.message);
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Request failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <AuthForm
+      title="Forgot Password"
+      onSubmit={handleSubmit}
+      loading={loading}
+      error={error}
+      submitText="Send reset link"
+      footerText="Remembered it?"
+      footerLink={{ text: 'Back to login', href: '/auth/login' }}
+    >
+      {message && <p>{message}</p>}
+      <FormField
+        label="Email"
+        type="email"
+        value={email}
+        onChange={(e) => setEmail(e.target.value)}
+        required
+        disabled={loading}
+      />
+    </AuthForm>
+  );
+}
@@ -1,8 +1,8 @@
 'use client';
 
-import { useState, ChangeEvent, FormEvent } from 'react';
+import { useState, useEffect, ChangeEvent, FormEvent } from 'react';
 import { useRouter } from 'next/navigation';
-import { authAPI } from '../../../utils/api';
+import { authAPI, oidcLoginURL } from '../../../utils/api';
 import { AuthForm, FormField } from '../../../components/auth/AuthForm';
 import type { LoginRequest } from '../../../types';
 
@@ -13,8 +13,21 @@ export default function LoginPage() {
   });
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
+  const [mfaToken, setMfaToken] = useState('');
+  const [mfaCode, setMfaCode] = useState('');
   const router = useRouter();
 
+  // Single sign-on returns here with an error, or a challenge for 2FA users
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    if (params.get('mfa_token')) {
+      setMfaToken(params.get('mfa_token') || '');
+    }
+    if (params.get('error')) {
+      setError(`Single sign-on failed (${params.get('error')})`);
+    }
+  }, []);
+
   const handleInputChange = (field: keyof LoginRequest) => 
     (e: ChangeEvent<HTMLInputElement>) => {
       setFormData(prev => ({
@@ -29,14 +42,25 @@ export default function LoginPage() {
     setError('');
 
     try {
-      const response = await authAPI.login(formData);
+      const response = mfaToken
+        ? await authAPI.loginMFA(mfaToken, mfaCode)
+        : await authAPI.login(formData);
+
+      // Two-factor accounts need a code before they get a token
+      if (response.data.mfa_required && response.data.mfa_token) {
+        setMfaToken(response.data.mfa_token);
+        return;
+      }
       
       // Store token and user info
       localStorage.setItem('token', response.data.token);
+      localStorage.setItem('refresh_token', response.data.refresh_token);
       localStorage.setItem('user', JSON.stringify(response.data.user));
       
-      // Redirect based on role
-      if (response.data.user.role === 'admin') {
+      // Redirect based on role; admins must set up 2FA first
+      if (response.data.user.role === 'admin' && !response.data.user.two_factor_enabled) {
+        router.push('/security');
+      } else if (response.data.user.role === 'admin') {
         router.push('/admin');
       } else {
         router.push('/dashboard');
@@ -49,6 +73,28 @@ export default function LoginPage() {
     }
   };
 
+  if (mfaToken) {
+    return (
+      <AuthForm
+        title="Two-Factor Authentication"
+        onSubmit={handleSubmit}
+        loading={loading}
+        error={error}
+        submitText="Verify"
+      >
+        <FormField
+          label="Authentication or recovery code"
+          type="text"
+          value={mfaCode}
+          onChange={(e) => setMfaCode(e.target.value)}
+          placeholder="6-digit code, or a recovery code if you lost your device"
+          required
+          disabled={loading}
+        />
+      </AuthForm>
+    );
+  }
+
   return (
     <AuthForm
       title="Login"
@@ -76,6 +122,9 @@ export default function LoginPage() {
         required
         disabled={loading}
       />
+
+      <p style={{ textAlign: 'right' }}><a href="/auth/forgot-password">Forgot your password?</a></p>
+      <p style={{ textAlign: 'center' }}><a href={oidcLoginURL}>Sign in with club single sign-on</a></p>
     </AuthForm>
   );
 }
\ No newline at end of file
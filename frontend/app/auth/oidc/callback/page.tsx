@@ -0,0 +1,37 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { useRouter } from 'next/navigation';
+import { authAPI } from '../../../../utils/api';
+
+export default function OIDCCallbackPage() {
+  const [message, setMessage] = useState('Signing you in...');
+  const router = useRouter();
+
+  useEffect(() => {
+    const code = new URLSearchParams(window.location.search).get('code') || '';
+    authAPI.exchangeOIDCLogin(code)
+      .then((response) => {
+        // Two-factor accounts finish on the login page
+        if (response.data.mfa_required && response.data.mfa_token) {
+          router.push(`/auth/login?mfa_token=${encodeURIComponent(response.data.mfa_token)}`);
+          return;
+        }
+
+        localStorage.setItem('token', response.data.token);
+        localStorage.setItem('refresh_token', response.data.refresh_token);
+        localStorage.setItem('user', JSON.stringify(response.data.user));
+        router.push(response.data.user.role === 'admin' ? '/admin' : '/dashboard');
+      })
+      .catch((error: any) => setMessage(error.response?.data?.message || 'Sign-in failed'));
+  }, [router]);
+
+  return (
+    <div className="container" style={{ maxWidth: '500px', marginTop: '2rem' }}>
+      <div className="card" style={{ textAlign: 'center' }}>
+        <p>{message}</p>
+        <p><a href="/auth/login">Back to login</a></p>
+      </div>
+    </div>
+  );
+}
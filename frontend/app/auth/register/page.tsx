@@ -47,6 +47,7 @@ export default function RegisterPage() {
       
       // Store token and user info
       localStorage.setItem('token', response.data.token);
+      localStorage.setItem('refresh_token', response.data.refresh_token);
       localStorage.setItem('user', JSON.stringify(response.data.user));
       
       // Redirect to dashboard (all new users are players)
@@ -0,0 +1,51 @@
+'use client';
+
+import { useState, FormEvent } from 'react';
+import { useRouter } from 'next/navigation';
+import { authAPI } from '../../../utils/api';
+import { AuthForm, FormField } from '../../../components/auth/AuthForm';
+
+export default function ResetPasswordPage() {
+  const [password, setPassword] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+  const router = useRouter();
+
+  const handleSubmit = async (e: FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      const token = new URLSearchParams(window.location.search).get('token') || '';
+      await authAPI.resetPassword(token, password);
+      router.push('/auth/login');
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Password reset failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <AuthForm
+      title="Reset Password"
+      onSubmit={handleSubmit}
+      loading={loading}
+      error={error}
+      submitText="Set new password"
+      footerText="Link expired?"
+      footerLink={{ text: 'Request a new one', href: '/auth/forgot-password' }}
+    >
+      <FormField
+        label="New Password"
+        type="password"
+        value={password}
+        onChange={(e) => setPassword(e.target.value)}
+        required
+        minLength={6}
+        disabled={loading}
+      />
+    </AuthForm>
+  );
+}
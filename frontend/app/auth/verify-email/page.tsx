@@ -0,0 +1,31 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { authAPI } from '../../../utils/api';
+
+export default function VerifyEmailPage() {
+  const [message, setMessage] = useState('Verifying your email...');
+
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get('token') || '';
+    authAPI.verifyEmail(token)
+      .then((response) => {
+        setMessage(response.message);
+        const userData = localStorage.getItem('user');
+        if (userData) {
+          localStorage.setItem('user', JSON.stringify({ ...JSON.parse(userData), email_verified: true }));
+        }
+      })
+      .catch((error: any) => setMessage(error.response?.data?.message || 'Verification failed'));
+  }, []);
+
+  return (
+    <div className="container" style={{ maxWidth: '500px', marginTop: '2rem' }}>
+      <div className="card" style={{ textAlign: 'center' }}>
+        <h1 style={{ marginBottom: '2rem' }}>🏸 Email Verification</h1>
+        <p>{message}</p>
+        <p><a href="/dashboard">Go to dashboard</a></p>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,127 @@
+'use client';
+
+import { useEffect, useState, FormEvent } from 'react';
+import Layout from '../../components/Layout';
+import { authAPI } from '../../utils/api';
+
+interface TwoFactorStatus {
+  enabled: boolean;
+  required: boolean;
+  recovery_codes_remaining: number;
+}
+
+export default function SecurityPage() {
+  const [status, setStatus] = useState<TwoFactorStatus | null>(null);
+  const [setup, setSetup] = useState<{ secret: string; provisioning_uri: string } | null>(null);
+  const [code, setCode] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+  const [error, setError] = useState('');
+  const [identities, setIdentities] = useState<{ id: number; email: string; issuer: string }[]>([]);
+
+  useEffect(() => {
+    authAPI.getTwoFactorStatus()
+      .then((response) => setStatus(response.data))
+      .catch(() => setError('Failed to load two-factor status'));
+    authAPI.getOIDCIdentities()
+      .then((response) => setIdentities(response.data || []))
+      .catch(() => setIdentities([]));
+
+    const linkError = new URLSearchParams(window.location.search).get('error');
+    if (linkError) {
+      setError(`Linking failed (${linkError})`);
+    }
+  }, []);
+
+  const linkAccount = async () => {
+    try {
+      const response = await authAPI.linkOIDCIdentity();
+      window.location.href = response.data.authorization_url;
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Single sign-on is unavailable');
+    }
+  };
+
+  const startSetup = async () => {
+    setError('');
+    try {
+      const response = await authAPI.setupTwoFactor();
+      setSetup(response.data);
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Failed to start setup');
+    }
+  };
+
+  const enable = async (e: FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    setError('');
+    try {
+      const response = await authAPI.enableTwoFactor(code);
+      setRecoveryCodes(response.data.recovery_codes);
+      setSetup(null);
+      setStatus((prev) => prev && { ...prev, enabled: true, recovery_codes_remaining: response.data.recovery_codes.length });
+
+      const userData = localStorage.getItem('user');
+      if (userData) {
+        localStorage.setItem('user', JSON.stringify({ ...JSON.parse(userData), two_factor_enabled: true }));
+      }
+    } catch (error: any) {
+      setError(error.response?.data?.message || 'Invalid code');
+    }
+  };
+
+  return (
+    <Layout title="Security">
+      <div className="container" style={{ maxWidth: '600px' }}>
+        <div className="card">
+          <h1>Two-Factor Authentication</h1>
+
+          {error && <div style={{ color: '#c33', marginBottom: '1rem' }}>{error}</div>}
+
+          {status?.required && !status.enabled && (
+            <p>Admin accounts must turn on two-factor authentication before using the admin panel.</p>
+          )}
+
+          {status?.enabled && (
+            <p>Two-factor authentication is on. Recovery codes remaining: {status.recovery_codes_remaining}</p>
+          )}
+
+          {status && !status.enabled && !setup && (
+            <button className="button" onClick={startSetup}>Set up two-factor authentication</button>
+          )}
+
+          {setup && (
+            <form onSubmit={enable}>
+              <p>Add this account to your authenticator app by opening the link on your phone or entering the key manually:</p>
+              <p><a href={setup.provisioning_uri}>{setup.provisioning_uri}</a></p>
+              <p>Key: <code>{setup.secret}</code></p>
+              <div className="form-group">
+                <label>Code from the app</label>
+                <input value={code} onChange={(e) => setCode(e.target.value)} required />
+              </div>
+              <button type="submit" className="button">Turn on</button>
+            </form>
+          )}
+
+          <h2>Single Sign-On</h2>
+          {identities.length > 0 ? (
+            <ul>
+              {identities.map((identity) => <li key={identity.id}>{identity.email} ({identity.issuer})</li>)}
+            </ul>
+          ) : (
+            <p>No club account linked.</p>
+          )}
+          <button className="button" onClick={linkAccount}>Link club account</button>
+
+          {recoveryCodes.length > 0 && (
+            <div>
+              <p>Save these recovery codes somewhere safe. Each can be used once if you lose your device; they will not be shown again.</p>
+              <ul>
+                {recoveryCodes.map((recoveryCode) => <li key={recoveryCode}><code>{recoveryCode}</code></li>)}
+              </ul>
+            </div>
+          )}
+        </div>
+      </div>
+    </Layout>
+  );
+}
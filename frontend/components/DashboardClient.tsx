@@ -19,7 +19,10 @@ function TournamentCard({ tournament, onRegister }: TournamentCardProps) {
 
   const getStatusColor = (status: Tournament['status']) => {
     const colors = {
-      upcoming: '#28a745',
+      draft: '#adb5bd',
+      registration_open: '#28a745',
+      registration_closed: '#ffc107',
+      drawn: '#17a2b8',
       ongoing: '#007bff', 
       completed: '#6c757d',
       cancelled: '#dc3545'
@@ -35,7 +38,7 @@ function TournamentCard({ tournament, onRegister }: TournamentCardProps) {
           className="tournament-status"
           style={{ backgroundColor: getStatusColor(tournament.status) }}
         >
-          {tournament.status.charAt(0).toUpperCase() + tournament.status.slice(1)}
+          {(tournament.status.charAt(0).toUpperCase() + tournament.status.slice(1)).replace('_', ' ')}
         </span>
       </div>
       
@@ -54,7 +57,7 @@ function TournamentCard({ tournament, onRegister }: TournamentCardProps) {
         </div>
       )}
       
-      {tournament.status === 'upcoming' && (
+      {tournament.status === 'registration_open' && (
         <button 
           className="button button-primary"
           onClick={() => onRegister(tournament.id)}
@@ -3,6 +3,7 @@
 import { ReactNode, useEffect, useState } from 'react';
 import { useRouter } from 'next/navigation';
 import type { User } from '../types';
+import { authAPI } from '../utils/api';
 
 interface LayoutProps {
   children: ReactNode;
@@ -28,8 +29,14 @@ export default function Layout({ children, title = 'Badminton Tournament' }: Lay
     setIsLoading(false);
   }, []);
 
-  const handleLogout = () => {
+  const handleLogout = async () => {
+    try {
+      await authAPI.logout();
+    } catch (error) {
+      // The session may already be gone; clear local state regardless
+    }
     localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
     localStorage.removeItem('user');
     router.push('/auth/login');
   };
@@ -57,6 +64,7 @@ export default function Layout({ children, title = 'Badminton Tournament' }: Lay
                   <div className="user-menu">
                     <span className="user-greeting">Hi, {user.full_name}</span>
                     <a href="/profile">Profile</a>
+                    <a href="/security">Security</a>
                     <button onClick={handleLogout} className="logout-btn">
                       Logout
                     </button>
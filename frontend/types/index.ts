@@ -8,6 +8,9 @@ export interface User {
   role: 'player' | 'admin';
   ranking?: number;
   is_active: boolean;
+  email_verified: boolean;
+  two_factor_enabled: boolean;
+  claim_pending: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -23,11 +26,22 @@ export interface Tournament {
   registration_deadline: string;
   entry_fee: number;
   prize_pool: number;
-  status: 'upcoming' | 'ongoing' | 'completed' | 'cancelled';
+  status: 'draft' | 'registration_open' | 'registration_closed' | 'drawn' | 'ongoing' | 'completed' | 'cancelled';
   created_at: string;
   updated_at: string;
 }
 
+export type StaffRole = 'organizer' | 'referee' | 'scorer' | 'desk';
+
+export interface TournamentStaff {
+  id: number;
+  user_id: number;
+  username: string;
+  full_name: string;
+  role: StaffRole;
+  assigned_at: string;
+}
+
 export interface Team {
   id: number;
   name: string;
@@ -37,22 +51,92 @@ export interface Team {
   updated_at: string;
 }
 
+export interface MatchPlayer {
+  id: number;
+  name: string;
+  email: string;
+  ranking: number;
+}
+
+// Singles sides are a team of one player, with a null id
+export interface MatchSide {
+  team: {
+    id: number | null;
+    name: string;
+    members: MatchPlayer[];
+  };
+  score: number;
+}
+
 export interface Match {
   id: number;
-  tournament_id: number;
-  team1_id?: number;
-  team2_id?: number;
-  player1_id?: number;
-  player2_id?: number;
-  player3_id?: number;
-  player4_id?: number;
-  score_team1: number;
-  score_team2: number;
-  status: 'scheduled' | 'in_progress' | 'completed' | 'cancelled';
-  scheduled_at?: string;
-  completed_at?: string;
+  type: 'singles' | 'doubles';
+  status: 'pending' | 'ongoing' | 'completed' | 'cancelled';
+  round: string;
+  match_date: string;
+  side1: MatchSide;
+  side2: MatchSide;
+  winner_side: 1 | 2 | null;
+  tournament?: { id: number; name: string };
+}
+
+export interface MatchResult {
+  id: number;
+  match_id: number;
+  reported_by_id: number;
+  reporter_side: 1 | 2;
+  side1_score: number;
+  side2_score: number;
+  status: 'pending' | 'confirmed' | 'disputed' | 'rejected';
+  responded_by_id?: number;
+  responded_at?: string;
+  dispute_reason?: string;
+  resolved_by_id?: number;
+  resolved_at?: string;
+  resolution_note?: string;
+  created_at: string;
+}
+
+export interface Standing {
+  id: number;
+  name: string;
+  played: number;
+  won: number;
+  lost: number;
+  points_for: number;
+  points_against: number;
+}
+
+export type APIKeyScope = 'read' | 'score' | 'admin';
+
+export interface APIKey {
+  id: number;
+  name: string;
+  prefix: string;
+  scope: APIKeyScope;
+  created_by_id: number;
+  expires_at: string | null;
+  last_used_at: string | null;
+  last_used_ip?: string;
+  revoked_at: string | null;
+  created_at: string;
+}
+
+export interface AuditLogEntry {
+  id: number;
+  actor_id: number | null;
+  action: string;
+  resource: string;
+  resource_id: number | null;
+  changes?: Record<string, { before: unknown; after: unknown }>;
+  details?: string;
+  ip_address: string;
+  method?: string;
+  path?: string;
+  user_agent?: string;
+  session_id?: number;
+  api_key_id?: number;
   created_at: string;
-  updated_at: string;
 }
 
 export interface LoginRequest {
@@ -72,6 +156,11 @@ export interface AuthResponse {
   data: {
     user: User;
     token: string;
+    refresh_token: string;
+    expires_in: number;
+    // Set instead of the fields above when the second login step is needed
+    mfa_required?: boolean;
+    mfa_token?: string;
   };
 }
 
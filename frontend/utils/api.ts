@@ -1,5 +1,5 @@
 import axios, { InternalAxiosRequestConfig } from 'axios';
-import type { LoginRequest, RegisterRequest, AuthResponse, User } from '../types';
+import type { LoginRequest, RegisterRequest, AuthResponse, APIKeyScope, StaffRole, User } from '../types';
 
 const API_BASE = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080';
 
@@ -21,6 +21,30 @@ api.interceptors.request.use((config: InternalAxiosRequestConfig) => {
   return config;
 });
 
+// Refresh the access token once when a request is rejected as unauthorized
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config;
+    const refreshToken = typeof window !== 'undefined' ? localStorage.getItem('refresh_token') : null;
+    if (error.response?.status !== 401 || !refreshToken || original._retry || original.url === '/token/refresh') {
+      return Promise.reject(error);
+    }
+
+    original._retry = true;
+    try {
+      const response = await api.post<AuthResponse>('/token/refresh', { refresh_token: refreshToken });
+      localStorage.setItem('token', response.data.data.token);
+      localStorage.setItem('refresh_token', response.data.data.refresh_token);
+      return api(original);
+    } catch (refreshError) {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+      return Promise.reject(error);
+    }
+  }
+);
+
 export const authAPI = {
   login: async (data: LoginRequest): Promise<AuthResponse> => {
     const response = await api.post<AuthResponse>('/login', data);
@@ -42,16 +66,114 @@ export const authAPI = {
     return response.data;
   },
 
-  changePassword: async (data: { current_password: string; new_password: string }) => {
-    const response = await api.post('/change-password', data);
+  changePassword: async (data: { current_password: string; new_password: string }): Promise<AuthResponse> => {
+    const response = await api.post<AuthResponse>('/change-password', data);
+    return response.data;
+  },
+
+  loginMFA: async (mfaToken: string, code: string): Promise<AuthResponse> => {
+    const response = await api.post<AuthResponse>('/login/mfa', { mfa_token: mfaToken, code });
+    return response.data;
+  },
+
+  exchangeOIDCLogin: async (code: string): Promise<AuthResponse> => {
+    const response = await api.post<AuthResponse>('/auth/oidc/exchange', { code });
+    return response.data;
+  },
+
+  getOIDCIdentities: async () => {
+    const response = await api.get('/auth/oidc/identities');
+    return response.data;
+  },
+
+  linkOIDCIdentity: async () => {
+    // The response sets the cookie the provider's callback is checked against
+    const response = await api.post('/auth/oidc/link', null, { withCredentials: true });
+    return response.data;
+  },
+
+  getTwoFactorStatus: async () => {
+    const response = await api.get('/2fa');
+    return response.data;
+  },
+
+  setupTwoFactor: async () => {
+    const response = await api.post('/2fa/setup');
+    return response.data;
+  },
+
+  enableTwoFactor: async (code: string) => {
+    const response = await api.post('/2fa/enable', { code });
+    return response.data;
+  },
+
+  verifyEmail: async (token: string) => {
+    const response = await api.post('/verify-email', { token });
+    return response.data;
+  },
+
+  resendVerification: async () => {
+    const response = await api.post('/resend-verification');
+    return response.data;
+  },
+
+  forgotPassword: async (email: string) => {
+    const response = await api.post('/forgot-password', { email });
+    return response.data;
+  },
+
+  resetPassword: async (token: string, newPassword: string) => {
+    const response = await api.post('/reset-password', { token, new_password: newPassword });
+    return response.data;
+  },
+
+  claimAccount: async (data: { token: string; password: string; username?: string }): Promise<AuthResponse> => {
+    const response = await api.post<AuthResponse>('/claim-account', data);
+    return response.data;
+  },
+
+  logout: async () => {
+    const response = await api.post('/logout');
+    return response.data;
+  },
+
+  logoutAll: async () => {
+    const response = await api.post('/logout-all');
+    return response.data;
+  },
+
+  getAPIKeys: async () => {
+    const response = await api.get('/api-keys');
+    return response.data;
+  },
+
+  createAPIKey: async (name: string, scope: APIKeyScope, expiresInDays = 0) => {
+    const response = await api.post('/api-keys', { name, scope, expires_in_days: expiresInDays });
+    return response.data;
+  },
+
+  revokeAPIKey: async (id: number) => {
+    const response = await api.delete(`/api-keys/${id}`);
+    return response.data;
+  },
+
+  getAuditLog: async (params: { resource?: string; id?: number; actor_id?: number; action?: string; limit?: number } = {}) => {
+    const response = await api.get('/audit', { params });
     return response.data;
   },
 };
 
 export const tournamentAPI = {
+  // Fetches every page, latest start date first
   getTournaments: async () => {
-    const response = await api.get('/tournaments');
-    return response.data;
+    const params = { sort: '-start_date', limit: 100 };
+    const first = (await api.get('/tournaments', { params: { ...params, page: 1 } })).data;
+    const tournaments = [...(first.data || [])];
+    for (let page = 2; page <= (first.meta?.total_pages || 1); page++) {
+      const response = await api.get('/tournaments', { params: { ...params, page } });
+      tournaments.push(...(response.data.data || []));
+    }
+    return { ...first, data: tournaments };
   },
 
   getTournament: async (id: number) => {
@@ -73,6 +195,56 @@ export const tournamentAPI = {
     const response = await api.get('/my-registrations');
     return response.data;
   },
+
+  getStaff: async (tournamentId: number) => {
+    const response = await api.get(`/tournaments/${tournamentId}/staff`);
+    return response.data;
+  },
+
+  addStaff: async (tournamentId: number, userId: number, role: StaffRole) => {
+    const response = await api.post(`/tournaments/${tournamentId}/staff`, { user_id: userId, role });
+    return response.data;
+  },
+
+  removeStaff: async (tournamentId: number, staffId: number) => {
+    const response = await api.delete(`/tournaments/${tournamentId}/staff/${staffId}`);
+    return response.data;
+  },
+
+  getMyRoles: async (tournamentId: number) => {
+    const response = await api.get(`/tournaments/${tournamentId}/my-roles`);
+    return response.data;
+  },
+};
+
+export const matchAPI = {
+  reportResult: async (matchId: number, side1Score: number, side2Score: number) => {
+    const response = await api.post(`/matches/${matchId}/result`, { side1_score: side1Score, side2_score: side2Score });
+    return response.data;
+  },
+
+  getPendingResults: async () => {
+    const response = await api.get('/match-results/pending');
+    return response.data;
+  },
+
+  confirmResult: async (resultId: number) => {
+    const response = await api.post(`/match-results/${resultId}/confirm`);
+    return response.data;
+  },
+
+  disputeResult: async (resultId: number, reason: string) => {
+    const response = await api.post(`/match-results/${resultId}/dispute`, { reason });
+    return response.data;
+  },
+
+  getStandings: async (type: 'singles' | 'doubles' = 'singles') => {
+    const response = await api.get('/standings', { params: { type } });
+    return response.data;
+  },
 };
 
+// Browser entry point for single sign-on
+export const oidcLoginURL = `${API_BASE}/api/v1/auth/oidc/login`;
+
 export default api;
\ No newline at end of file